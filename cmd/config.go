@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// configKey describes a settable key in config.json
+type configKey struct {
+	Name        string
+	Kind        string // "bool", "int", or "string"
+	Description string
+	Get         func(cfg *types.Config) string
+	Set         func(cfg *types.Config, value string) error
+	ReadOnly    string // Why Set is nil, shown when 'config set' refuses the key
+}
+
+// configKeys lists the keys managed by 'tabgen config', in display order
+var configKeys = []configKey{
+	{
+		Name:        "tabgen_dir",
+		Kind:        "string",
+		Description: "Base directory for TabGen data (informational; not used)",
+		Get:         func(cfg *types.Config) string { return cfg.TabGenDir },
+		ReadOnly:    "choose the data directory with --data-dir or $TABGEN_DATA_DIR instead",
+	},
+	{
+		Name:        "scan_on_startup",
+		Kind:        "bool",
+		Description: "Whether to scan on shell startup (informational; not used)",
+		Get:         func(cfg *types.Config) string { return strconv.FormatBool(cfg.ScanOnStartup) },
+		ReadOnly:    "'tabgen install' sets up a timer for scheduled scans instead",
+	},
+	{
+		Name:        "compress_tools",
+		Kind:        "bool",
+		Description: "Whether to gzip stored tool JSON",
+		Get:         func(cfg *types.Config) string { return strconv.FormatBool(cfg.CompressTools) },
+		Set:         boolSetter(func(cfg *types.Config, b bool) { cfg.CompressTools = b }),
+	},
+	{
+		Name:        "save_raw",
+		Kind:        "bool",
+		Description: "Whether to keep raw help output for 'tabgen reparse'",
+		Get:         func(cfg *types.Config) string { return strconv.FormatBool(cfg.SaveRawOutput) },
+		Set:         boolSetter(func(cfg *types.Config, b bool) { cfg.SaveRawOutput = b }),
+	},
+	{
+		Name:        "dedupe_links",
+		Kind:        "bool",
+		Description: "Whether scan records symlinked names (vi -> vim) as aliases of one tool",
+		Get:         func(cfg *types.Config) string { return strconv.FormatBool(cfg.DedupeLinks) },
+		Set:         boolSetter(func(cfg *types.Config, b bool) { cfg.DedupeLinks = b }),
+	},
+	{
+		Name:        "man_fallback",
+		Kind:        "bool",
+		Description: "Whether tools without --help also get commands parsed from their man page",
+		Get:         func(cfg *types.Config) string { return strconv.FormatBool(cfg.ManFallback) },
+		Set:         boolSetter(func(cfg *types.Config, b bool) { cfg.ManFallback = b }),
+	},
+	{
+		Name:        "keep_backups",
+		Kind:        "int",
+		Description: "Previous completion scripts kept per tool for 'tabgen rollback'",
+		Get:         func(cfg *types.Config) string { return strconv.Itoa(cfg.KeepBackups) },
+		Set:         intSetter(func(cfg *types.Config, n int) { cfg.KeepBackups = n }),
+	},
+	{
+		Name:        "help_retries",
+		Kind:        "int",
+		Description: "Extra --help attempts when a tool can't be started for a transient reason (0 = default of 2)",
+		Get:         func(cfg *types.Config) string { return strconv.Itoa(cfg.HelpRetries) },
+		Set:         intSetter(func(cfg *types.Config, n int) { cfg.HelpRetries = n }),
+	},
+	{
+		Name:        "completion_style",
+		Kind:        "string",
+		Description: "Zsh completion menus: verbose (with descriptions) or compact",
+		Get:         func(cfg *types.Config) string { return cfg.CompletionStyle },
+		Set: func(cfg *types.Config, v string) error {
+			if _, err := compactStyle(v); err != nil {
+				return err
+			}
+			cfg.CompletionStyle = v
+			return nil
+		},
+	},
+	{
+		Name:        "extra_dirs",
+		Kind:        "list",
+		Description: "Directories scanned after $PATH (added by 'scan --dir'; set \"\" to clear)",
+		Get:         func(cfg *types.Config) string { return strings.Join(cfg.ExtraDirs, ",") },
+		Set: func(cfg *types.Config, v string) error {
+			cfg.ExtraDirs = nil
+			for dir := range strings.SplitSeq(v, ",") {
+				if dir = strings.TrimSpace(dir); dir != "" {
+					abs, err := filepath.Abs(dir)
+					if err != nil {
+						return fmt.Errorf("invalid directory %q: %w", dir, err)
+					}
+					cfg.ExtraDirs = append(cfg.ExtraDirs, abs)
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Name:        "extra_dirs_all",
+		Kind:        "bool",
+		Description: "Whether tools in extra_dirs are cataloged even if absent from shell history",
+		Get:         func(cfg *types.Config) string { return strconv.FormatBool(cfg.ExtraDirsAll) },
+		Set:         boolSetter(func(cfg *types.Config, b bool) { cfg.ExtraDirsAll = b }),
+	},
+	{
+		Name:        "excluded",
+		Kind:        "list",
+		Description: "Excluded tool patterns (manage with 'tabgen exclude')",
+		Get:         func(cfg *types.Config) string { return strings.Join(cfg.Excluded, ",") },
+	},
+	{
+		Name:        "overrides",
+		Kind:        "map",
+		Description: "Tools with parser overrides (edit config.json)",
+		Get: func(cfg *types.Config) string {
+			return strings.Join(slices.Sorted(maps.Keys(cfg.Overrides)), ",")
+		},
+	},
+}
+
+// boolSetter wraps a bool assignment with parsing and validation
+func boolSetter(assign func(cfg *types.Config, b bool)) func(*types.Config, string) error {
+	return func(cfg *types.Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q (use true or false)", value)
+		}
+		assign(cfg, b)
+		return nil
+	}
+}
+
+// intSetter wraps a non-negative int assignment with parsing and validation
+func intSetter(assign func(cfg *types.Config, n int)) func(*types.Config, string) error {
+	return func(cfg *types.Config, value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid count %q (use a number >= 0)", value)
+		}
+		assign(cfg, n)
+		return nil
+	}
+}
+
+// lookupConfigKey finds a config key by name
+func lookupConfigKey(name string) (*configKey, error) {
+	names := make([]string, 0, len(configKeys))
+	for i := range configKeys {
+		if configKeys[i].Name == name {
+			return &configKeys[i], nil
+		}
+		names = append(names, configKeys[i].Name)
+	}
+	return nil, fmt.Errorf("unknown config key: %s (valid keys: %s)", name, strings.Join(names, ", "))
+}
+
+// Config views and edits config.json
+func Config(action, key, value string) error {
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	switch action {
+	case "list", "":
+		return configList(cfg)
+	case "get":
+		return configGet(cfg, key)
+	case "set":
+		return configSet(storage, cfg, key, value)
+	default:
+		return fmt.Errorf("unknown action: %s (use: list, get, set)", action)
+	}
+}
+
+func configList(cfg *types.Config) error {
+	for _, k := range configKeys {
+		fmt.Printf("%-16s = %-12s # %s (%s)\n", k.Name, k.Get(cfg), k.Description, k.Kind)
+	}
+	return nil
+}
+
+func configGet(cfg *types.Config, name string) error {
+	if name == "" {
+		return fmt.Errorf("key required: tabgen config get <key>")
+	}
+	k, err := lookupConfigKey(name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(k.Get(cfg))
+	return nil
+}
+
+func configSet(storage *config.Storage, cfg *types.Config, name, value string) error {
+	if name == "" {
+		return fmt.Errorf("key required: tabgen config set <key> <value>")
+	}
+	k, err := lookupConfigKey(name)
+	if err != nil {
+		return err
+	}
+	if k.Set == nil {
+		if k.ReadOnly != "" {
+			return fmt.Errorf("%s has no effect and cannot be set; %s", name, k.ReadOnly)
+		}
+		return fmt.Errorf("%s cannot be set with 'tabgen config'", name)
+	}
+	if value == "" && k.Kind != "list" {
+		return fmt.Errorf("value required: tabgen config set %s <value>", name)
+	}
+	if err := k.Set(cfg, value); err != nil {
+		return err
+	}
+
+	if err := storage.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Set %s = %s\n", name, k.Get(cfg))
+	return nil
+}
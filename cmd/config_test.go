@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+func TestConfigSet_UnusedKeys(t *testing.T) {
+	storage, err := config.New(useDataDir(t))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cfg, _ := storage.LoadConfig()
+
+	for _, name := range []string{"tabgen_dir", "scan_on_startup"} {
+		err := configSet(storage, cfg, name, "false")
+		if err == nil || !strings.Contains(err.Error(), "has no effect") {
+			t.Errorf("config set %s error = %v, want it refused as having no effect", name, err)
+		}
+	}
+	if cfg.TabGenDir != "~/.tabgen" || !cfg.ScanOnStartup {
+		t.Errorf("refused keys changed the config: %+v", cfg)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- data
+	}()
+	runErr := fn()
+	w.Close()
+	out := <-done
+	if runErr != nil {
+		t.Fatalf("command failed: %v", runErr)
+	}
+	return string(out)
+}
+
+// useDataDir points commands at a fresh data directory, with HOME moved
+// out of the way, and returns the directory
+func useDataDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	dataDir := filepath.Join(t.TempDir(), "instance")
+	old := config.DataDir
+	config.DataDir = dataDir
+	t.Cleanup(func() { config.DataDir = old })
+	return dataDir
+}
+
+func TestList_SuppliedDataDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dataDir := filepath.Join(t.TempDir(), "instance")
+
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"git": {Name: "git", Generated: true}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+
+	old := config.DataDir
+	config.DataDir = dataDir
+	defer func() { config.DataDir = old }()
+
+	out := captureStdout(t, func() error { return List(ListOptions{JSON: true}) })
+	var entries []types.CatalogEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(entries) != 1 || entries[0].Name != "git" {
+		t.Errorf("expected the catalog from the supplied directory, got %+v", entries)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".tabgen")); !os.IsNotExist(err) {
+		t.Error("expected nothing read from or written to ~/.tabgen")
+	}
+}
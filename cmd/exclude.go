@@ -7,12 +7,13 @@ import (
 	"strings"
 
 	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/scanner"
 	"github.com/jvalentini/tabgen/internal/types"
 )
 
 // Exclude manages the exclusion list
 func Exclude(action, pattern string) error {
-	storage, err := config.New("")
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -31,8 +32,10 @@ func Exclude(action, pattern string) error {
 		return excludeRemove(storage, cfg, pattern)
 	case "clear":
 		return excludeClear(storage, cfg)
+	case "test":
+		return excludeTest(cfg, pattern)
 	default:
-		return fmt.Errorf("unknown action: %s (use: list, add, remove, clear)", action)
+		return fmt.Errorf("unknown action: %s (use: list, add, remove, clear, test)", action)
 	}
 }
 
@@ -113,6 +116,24 @@ func excludeClear(storage *config.Storage, cfg *types.Config) error {
 	return nil
 }
 
+// excludeTest reports whether scan would exclude a tool name, and which pattern matched
+func excludeTest(cfg *types.Config, name string) error {
+	if name == "" {
+		return fmt.Errorf("name required: tabgen exclude test <name>")
+	}
+
+	pattern, err := scanner.MatchExclusion(cfg.Excluded, name)
+	if err != nil {
+		return err
+	}
+	if pattern == "" {
+		fmt.Printf("%s is not excluded.\n", name)
+		return nil
+	}
+	fmt.Printf("%s is excluded by pattern: %s\n", name, pattern)
+	return nil
+}
+
 // ExcludeHelp returns usage help for the exclude command
 func ExcludeHelp() string {
 	return strings.TrimSpace(`
@@ -123,6 +144,7 @@ Actions:
   add <pattern>  Add a pattern to exclusions
   remove <pattern>  Remove a pattern from exclusions
   clear          Remove all exclusions
+  test <name>    Show whether a tool name is excluded, and by which pattern
 
 Patterns are matched against tool names. Examples:
   tabgen exclude add python2.7
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Forget removes a tool from the catalog along with its parsed data and
+// completion scripts. The next scan adds it back if it's still installed and
+// used; exclude it to keep it out for good.
+func Forget(name string) error {
+	if name == "" {
+		return fmt.Errorf("tool required: tabgen forget <tool>")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid tool name %q", name)
+	}
+
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	storage.SetBackups(cfg.KeepBackups)
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	entry, ok := catalog.Tools[name]
+	if !ok && !storage.ToolExists(name) {
+		return fmt.Errorf("tool %q not found in catalog", name)
+	}
+	if !ok {
+		entry = types.CatalogEntry{Name: name}
+	}
+
+	if err := deleteToolFiles(storage, entry); err != nil {
+		return err
+	}
+	if ok {
+		delete(catalog.Tools, name)
+		if err := storage.SaveCatalog(catalog); err != nil {
+			return fmt.Errorf("failed to save catalog: %w", err)
+		}
+	}
+
+	fmt.Printf("Forgot %s\n", name)
+	fmt.Printf("Run 'tabgen exclude add %s' to keep the next scan from adding it back.\n", name)
+	return nil
+}
+
+// deleteToolFiles deletes a tool's stored data and completion scripts,
+// including the scripts written for its aliases
+func deleteToolFiles(storage *config.Storage, entry types.CatalogEntry) error {
+	if err := storage.DeleteTool(entry.Name); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", entry.Name, err)
+	}
+	for _, alias := range entry.Aliases {
+		if err := storage.RemoveCompletions(alias); err != nil {
+			return fmt.Errorf("failed to remove completions for %s: %w", alias, err)
+		}
+	}
+	return nil
+}
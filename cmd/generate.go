@@ -1,60 +1,183 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"maps"
+	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/jvalentini/tabgen/internal/config"
 	"github.com/jvalentini/tabgen/internal/generator"
 	"github.com/jvalentini/tabgen/internal/parser"
+	"github.com/jvalentini/tabgen/internal/scanner"
 	"github.com/jvalentini/tabgen/internal/types"
 )
 
 // GenerateOptions configures the generate command
 type GenerateOptions struct {
-	Tool    string // Specific tool to generate (empty = all)
-	Force   bool   // Force regeneration even if up-to-date
-	Workers int    // Number of concurrent workers (default: NumCPU)
+	Tools           []string      // Specific tools to generate (empty = all)
+	Force           bool          // Force regeneration even if up-to-date
+	Workers         int           // Number of concurrent workers (default: NumCPU)
+	Dynamic         bool          // Emit runtime value completions for known tools (git branches, pods, ...)
+	Output          string        // Write scripts to Output/bash and Output/zsh instead of the data directory
+	Quiet           bool          // Only report failures and the summary (with a live progress line on a TTY)
+	Source          string        // Parse only "help" or "man" output, or "both" (default); beats per-tool overrides
+	ManOnlyFallback bool          // For tools without --help, parse commands from the man page too (also config man_fallback)
+	SkipEmpty       bool          // Don't write scripts for tools that parsed to no subcommands or flags
+	OutputFormat    string        // "scripts" (default) or "json" to emit the parsed tools instead of scripts
+	NoVersion       bool          // Skip version detection; only the content hash decides what to regenerate
+	NoHelp          bool          // Never run --help; parse man pages only (applies on top of per-tool overrides)
+	NoMan           bool          // Never read man pages; parse --help only (applies on top of per-tool overrides)
+	CompletionStyle string        // "verbose" or "compact" zsh output (default: config completion_style)
+	Keep            int           // Previous scripts to back up per tool for 'tabgen rollback' (0 = config keep_backups)
+	Bundle          bool          // Also combine all bash and zsh scripts into one file per shell for the shell hooks
+	Exclude         []string      // Exclusion patterns for this run only, on top of the stored ones
+	MaxTime         time.Duration // Stop starting new tools after this long (0 = no limit); in-flight ones finish
+	VersionTimeout  time.Duration // Timeout for each version command (0 = parser default)
+	IncludeHidden   bool          // Complete flags the help marks as hidden
+	OlderThan       time.Duration // Regenerate tools last generated longer ago than this (0 = off)
+	DryRun          bool          // Parse and decide what to do, but write nothing; print the decision per tool
 }
 
 // toolResult holds the outcome of processing a single tool
 type toolResult struct {
 	Name             string
-	Status           string // "success", "skipped", "failed"
+	Status           string // "success", "skipped", "failed", "unparseable"
+	Source           string // Where the parse got its data
+	Description      string // Tool's one-line summary
 	Version          string
 	GeneratedVersion string
-	ContentHash      string // Hash of parsed tool content
+	GeneratedAt      time.Time // When the scripts were written
+	ContentHash      string    // Hash of parsed tool content
 	Error            error
 	Message          string
-	Warnings         []string // Truncation/bounds warnings
+	Warnings         []string    // Truncation/bounds warnings
+	Empty            bool        // Parsed to no subcommands or flags, so scripts only complete files
+	Tool             *types.Tool // Parsed tool, set in json output mode
 }
 
 // Generate creates completion scripts for one or all tools
 func Generate(opts GenerateOptions) error {
-	storage, err := config.New("")
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	storage.SetCompression(cfg.CompressTools)
+	keep := cfg.KeepBackups
+	if opts.Keep > 0 {
+		keep = opts.Keep
+	}
+	storage.SetBackups(keep)
+
+	parserCfg := parser.DefaultConfig()
+	if cfg.SaveRawOutput && !opts.DryRun {
+		parserCfg.RawDir = storage.RawPath()
+	}
+	parserCfg.ManFallback = cfg.ManFallback || opts.ManOnlyFallback
+	parserCfg.DetectVersion = !opts.NoVersion
+	if opts.VersionTimeout > 0 {
+		parserCfg.VersionTimeout = opts.VersionTimeout
+	}
+	if cfg.HelpRetries > 0 {
+		parserCfg.HelpRetries = cfg.HelpRetries
+	}
+	if opts.CompletionStyle == "" {
+		opts.CompletionStyle = cfg.CompletionStyle
+	}
+	if _, err := compactStyle(opts.CompletionStyle); err != nil {
+		return err
+	}
+	switch opts.Source {
+	case "", "both":
+	case "help", "man":
+		parserCfg.ForceSource = opts.Source
+	default:
+		return fmt.Errorf("invalid source %q: must be help, man, or both", opts.Source)
+	}
+	if opts.NoHelp && opts.NoMan {
+		return fmt.Errorf("--no-help and --no-man together leave nothing to parse")
+	}
+	parserCfg.SkipHelp = opts.NoHelp
+	parserCfg.SkipMan = opts.NoMan
+
+	jsonMode := false
+	switch opts.OutputFormat {
+	case "", "scripts":
+	case "json":
+		jsonMode = true
+	default:
+		return fmt.Errorf("invalid output format %q: must be scripts or json", opts.OutputFormat)
+	}
+	if opts.VersionTimeout < 0 {
+		return fmt.Errorf("invalid --version-timeout %v: must be positive", opts.VersionTimeout)
+	}
+	if opts.OlderThan < 0 {
+		return fmt.Errorf("invalid --older-than %v: must be positive", opts.OlderThan)
+	}
+	if opts.MaxTime < 0 {
+		return fmt.Errorf("invalid --max-time %v: must be positive", opts.MaxTime)
+	}
+	if opts.DryRun && jsonMode {
+		return fmt.Errorf("--dry-run only applies to script generation")
+	}
+	if opts.Bundle && (jsonMode || opts.Output != "") {
+		return fmt.Errorf("--bundle only applies to scripts written to the data directory")
+	}
+
+	// Progress goes to stderr when the JSON itself is printed to stdout
+	var log io.Writer = os.Stdout
+	if jsonMode && opts.Output == "" {
+		log = os.Stderr
+	}
+
+	// Scripts go to the data directory unless --output redirects them
+	var writer config.CompletionWriter = storage
+	if jsonMode && opts.Output != "" {
+		if err := os.MkdirAll(opts.Output, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	} else if opts.Output != "" {
+		out, err := config.NewOutputDir(opts.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		writer = out
+	}
+
 	catalog, err := storage.LoadCatalog()
 	if err != nil {
 		return fmt.Errorf("failed to load catalog: %w", err)
 	}
 
 	if len(catalog.Tools) == 0 {
-		fmt.Println("No tools in catalog. Run 'tabgen scan' first.")
+		fmt.Fprintln(log, "No tools in catalog. Run 'tabgen scan' first.")
 		return nil
 	}
 
 	// Determine which tools to generate
 	var tools []string
-	if opts.Tool != "" {
-		if _, ok := catalog.Tools[opts.Tool]; !ok {
-			return fmt.Errorf("tool %q not found in catalog. Run 'tabgen scan' first.", opts.Tool)
+	if len(opts.Tools) > 0 {
+		for _, name := range opts.Tools {
+			if _, ok := catalog.Tools[name]; !ok {
+				return fmt.Errorf("tool %q not found in catalog. Run 'tabgen scan' first.", name)
+			}
+			if !slices.Contains(tools, name) {
+				tools = append(tools, name)
+			}
 		}
-		tools = []string{opts.Tool}
 	} else {
 		// Generate for all tools (parser will skip unparseable ones)
 		for name := range catalog.Tools {
@@ -63,11 +186,25 @@ func Generate(opts GenerateOptions) error {
 	}
 
 	if len(tools) == 0 {
-		fmt.Println("No tools in catalog. Run 'tabgen scan' first.")
+		fmt.Fprintln(log, "No tools in catalog. Run 'tabgen scan' first.")
 		return nil
 	}
 
-	fmt.Printf("Processing %d tools...\n", len(tools))
+	// Exclusions added since the last scan, and those for this run, still apply
+	tools, excluded, err := filterExcluded(tools, append(slices.Clone(cfg.Excluded), opts.Exclude...))
+	if err != nil {
+		return err
+	}
+	if len(tools) == 0 {
+		fmt.Fprintln(log, "Nothing to generate: every tool is excluded.")
+		return nil
+	}
+
+	fmt.Fprintf(log, "Processing %d tools...\n", len(tools))
+	if excluded > 0 {
+		fmt.Fprintf(log, "  (excluding %d)\n", excluded)
+	}
+	start := time.Now()
 
 	// Set default workers
 	workers := opts.Workers
@@ -79,23 +216,52 @@ func Generate(opts GenerateOptions) error {
 		workers = len(tools)
 	}
 
-	// Create channels
-	toolChan := make(chan string, len(tools))
+	// The deadline stops dispatch; tools already handed to a worker finish
+	ctx := context.Background()
+	if opts.MaxTime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.MaxTime)
+		defer cancel()
+		// Never-generated tools first, so repeated capped runs make progress
+		sort.SliceStable(tools, func(i, j int) bool {
+			gi, gj := catalog.Tools[tools[i]].Generated, catalog.Tools[tools[j]].Generated
+			if gi != gj {
+				return !gi
+			}
+			return tools[i] < tools[j]
+		})
+	}
+
+	// Create channels. Tools are handed over one at a time so dispatch can
+	// stop at the deadline.
+	toolChan := make(chan string)
 	resultChan := make(chan toolResult, len(tools))
 
 	// Start workers
 	var wg sync.WaitGroup
 	for i := 0; i < workers; i++ {
 		wg.Go(func() {
-			processTools(toolChan, resultChan, catalog, storage, opts.Force)
+			processTools(toolChan, resultChan, catalog, storage, writer, parserCfg, cfg.Overrides, opts)
 		})
 	}
 
-	// Send tools to workers
-	for _, name := range tools {
-		toolChan <- name
-	}
-	close(toolChan)
+	// Send tools to workers until they run out or time does. dispatched is
+	// only read once resultChan is closed, after this goroutine is done.
+	dispatched := 0
+	go func() {
+		defer close(toolChan)
+		for _, name := range tools {
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case toolChan <- name:
+				dispatched++
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
 
 	// Wait for workers to finish, then close results
 	go func() {
@@ -105,88 +271,274 @@ func Generate(opts GenerateOptions) error {
 
 	// Collect results
 	succeeded := 0
+	empty := 0
 	skipped := 0
 	failed := 0
+	var parsed []*types.Tool // json mode without --output, printed at the end
 
 	catalogUpdates := make(map[string]types.CatalogEntry)
+	prog := newProgress(len(tools), opts.Quiet && isTerminal(os.Stderr))
 
 	for result := range resultChan {
+		counter := prog.next()
+		if opts.DryRun {
+			switch printDryRun(log, counter, result, catalog.Tools[result.Name], opts) {
+			case "generate":
+				succeeded++
+			case "skip":
+				skipped++
+			case "fail":
+				failed++
+			}
+			prog.draw(result.Name)
+			continue
+		}
 		switch result.Status {
-		case "success":
-			if result.Version != "" {
-				fmt.Printf("  ✓ %s (v%s)\n", result.Name, result.Version)
-			} else {
-				fmt.Printf("  ✓ %s\n", result.Name)
+		case "success", "version_changed", "hash_changed":
+			if !opts.Quiet {
+				printGenerated(log, counter, result)
 			}
-			for _, w := range result.Warnings {
-				fmt.Printf("    ⚠ %s\n", w)
+			entry := catalog.Tools[result.Name]
+			if result.Empty {
+				empty++
+				if opts.SkipEmpty {
+					// Nothing written; only record what the parse found
+					entry.Source = result.Source
+					entry.Description = result.Description
+					entry.LastError = ""
+					entry.FailedAt = time.Time{}
+					catalogUpdates[result.Name] = entry
+					break
+				}
+			} else {
+				succeeded++
 			}
-			succeeded++
 			// Queue catalog update
-			entry := catalog.Tools[result.Name]
 			entry.Generated = true
 			entry.Version = result.Version
 			entry.GeneratedVersion = result.GeneratedVersion
+			entry.GeneratedAt = result.GeneratedAt
 			entry.ContentHash = result.ContentHash
+			entry.Dynamic = opts.Dynamic
+			entry.IncludeHidden = opts.IncludeHidden
+			entry.CompletionStyle = styleName(opts.CompletionStyle == "compact")
+			entry.Imported = false
+			entry.Source = result.Source
+			entry.Description = result.Description
+			entry.LastError = ""
+			entry.FailedAt = time.Time{}
 			catalogUpdates[result.Name] = entry
+		case "parsed":
+			succeeded++
+			if opts.Output == "" {
+				parsed = append(parsed, result.Tool)
+				break
+			}
+			path := filepath.Join(opts.Output, result.Name+".json")
+			if err := writeToolJSON(path, result.Tool); err != nil {
+				prog.clear()
+				fmt.Fprintf(log, "  %s ✗ %s: %v\n", counter, result.Name, err)
+				succeeded--
+				failed++
+			} else if !opts.Quiet {
+				fmt.Fprintf(log, "  %s ✓ %s → %s\n", counter, result.Name, path)
+				for _, w := range result.Warnings {
+					fmt.Fprintf(log, "    ⚠ %s\n", w)
+				}
+			}
 		case "skipped":
 			skipped++
+			// The summary isn't part of the content hash; keep it current anyway
+			if entry := catalog.Tools[result.Name]; entry.Description != result.Description {
+				entry.Description = result.Description
+				catalogUpdates[result.Name] = entry
+			}
 		case "failed":
-			fmt.Printf("  ✗ %s: %v\n", result.Name, result.Error)
+			prog.clear()
+			fmt.Fprintf(log, "  %s ✗ %s: %v\n", counter, result.Name, result.Error)
 			failed++
-		case "version_changed", "hash_changed":
-			fmt.Printf("  ↻ %s: %s\n", result.Name, result.Message)
-			if result.Version != "" {
-				fmt.Printf("  ✓ %s (v%s)\n", result.Name, result.Version)
-			} else {
-				fmt.Printf("  ✓ %s\n", result.Name)
-			}
-			for _, w := range result.Warnings {
-				fmt.Printf("    ⚠ %s\n", w)
-			}
-			succeeded++
-			// Queue catalog update
 			entry := catalog.Tools[result.Name]
-			entry.Generated = true
-			entry.Version = result.Version
-			entry.GeneratedVersion = result.GeneratedVersion
-			entry.ContentHash = result.ContentHash
+			entry.LastError = result.Error.Error()
+			entry.FailedAt = time.Now()
+			catalogUpdates[result.Name] = entry
+		case "unparseable":
+			// No help or man page; recorded so 'tabgen list --unparseable' can find it
+			entry := catalog.Tools[result.Name]
+			entry.Source = result.Source
+			entry.LastError = ""
+			entry.FailedAt = time.Time{}
 			catalogUpdates[result.Name] = entry
 		}
+		prog.draw(result.Name)
 	}
+	prog.clear()
 
-	// Apply catalog updates
-	maps.Copy(catalog.Tools, catalogUpdates)
+	if opts.DryRun {
+		fmt.Fprintf(log, "\nDry run: %d would be generated, %d skipped, %d failed in %v (nothing written)\n",
+			succeeded, skipped, failed, time.Since(start).Round(time.Millisecond))
+		return nil
+	}
 
-	// Save updated catalog
-	if err := storage.SaveCatalog(catalog); err != nil {
-		return fmt.Errorf("failed to save catalog: %w", err)
+	// Apply catalog updates. The catalog describes the data directory, so
+	// a run into --output leaves it alone.
+	if opts.Output == "" {
+		maps.Copy(catalog.Tools, catalogUpdates)
+		if err := storage.SaveCatalog(catalog); err != nil {
+			return fmt.Errorf("failed to save catalog: %w", err)
+		}
 	}
 
-	fmt.Printf("\nDone: %d generated, %d skipped (up-to-date), %d failed\n", succeeded, skipped, failed)
+	if dispatched < len(tools) {
+		fmt.Fprintf(log, "\nStopped after --max-time %v: %d of %d tools processed, %d not started (run again to continue)\n",
+			opts.MaxTime, dispatched, len(tools), len(tools)-dispatched)
+	}
+
+	if jsonMode {
+		fmt.Fprintf(log, "\nDone: %d parsed, %d skipped, %d failed in %v\n",
+			succeeded, skipped, failed, time.Since(start).Round(time.Millisecond))
+		return printToolsJSON(parsed, len(tools) == 1 && len(opts.Tools) > 0)
+	}
 
-	if succeeded > 0 {
-		bashDir, zshDir := storage.CompletionPaths()
-		fmt.Printf("\nCompletions saved to:\n")
-		fmt.Printf("  Bash: %s\n", bashDir)
-		fmt.Printf("  Zsh:  %s\n", zshDir)
+	fmt.Fprintf(log, "\nDone: %d generated, %d empty, %d skipped (up-to-date), %d failed in %v\n",
+		succeeded, empty, skipped, failed, time.Since(start).Round(time.Millisecond))
+
+	if succeeded > 0 || (empty > 0 && !opts.SkipEmpty) {
+		bashDir, zshDir := writer.CompletionPaths()
+		fmt.Fprintf(log, "\nCompletions saved to:\n")
+		fmt.Fprintf(log, "  Bash: %s\n", bashDir)
+		fmt.Fprintf(log, "  Zsh:  %s\n", zshDir)
+		fmt.Fprintf(log, "  Elvish: %s\n", writer.ElvishCompletionPath())
+		fmt.Fprintf(log, "  Tcsh: %s\n", writer.TcshCompletionPath())
+		fmt.Fprintf(log, "  Xonsh: %s\n", writer.XonshCompletionPath())
+		fmt.Fprintf(log, "  Nushell: %s\n", writer.NushellCompletionPath())
+	}
+
+	if opts.Output != "" {
+		return nil
+	}
+	if opts.Bundle {
+		if err := storage.WriteBundles(); err != nil {
+			return fmt.Errorf("failed to write bundles: %w", err)
+		}
+		fmt.Fprintf(log, "\nBundles saved to:\n")
+		fmt.Fprintf(log, "  Bash: %s\n", storage.BashBundlePath())
+		fmt.Fprintf(log, "  Zsh:  %s\n", storage.ZshBundlePath())
+	} else if err := storage.RefreshBundles(); err != nil {
+		return fmt.Errorf("failed to refresh bundles: %w", err)
 	}
 
 	return nil
 }
 
+// printDryRun reports what generate would do with a tool and returns
+// "generate", "skip", or "fail". Only failures are printed in quiet mode.
+func printDryRun(w io.Writer, counter string, result toolResult, entry types.CatalogEntry, opts GenerateOptions) string {
+	name := result.Name
+	if result.Version != "" {
+		name += " (v" + result.Version + ")"
+	}
+
+	var action, reason string
+	switch result.Status {
+	case "failed":
+		fmt.Fprintf(w, "  %s ✗ %s: %v\n", counter, name, result.Error)
+		return "fail"
+	case "unparseable":
+		action, reason = "skip", "no help or man page"
+	case "skipped":
+		action, reason = "skip", result.Message
+	default:
+		action, reason = "generate", result.Message
+		switch {
+		case result.Empty && opts.SkipEmpty:
+			action, reason = "skip", "no completions extracted (--skip-empty)"
+		case result.Empty:
+			reason = "no completions extracted"
+		case reason != "":
+			// Already explained: version or help changed, or too old
+		case opts.Force && entry.Generated:
+			reason = "--force"
+		case entry.Generated:
+			reason = "no version recorded"
+		default:
+			reason = "new"
+		}
+	}
+
+	if !opts.Quiet {
+		fmt.Fprintf(w, "  %s would %s %s: %s\n", counter, action, name, reason)
+		for _, warning := range result.Warnings {
+			fmt.Fprintf(w, "    ⚠ %s\n", warning)
+		}
+	}
+	return action
+}
+
+// filterExcluded drops the tools matching any of patterns and returns the
+// rest with how many were dropped
+func filterExcluded(tools, patterns []string) ([]string, int, error) {
+	if len(patterns) == 0 {
+		return tools, 0, nil
+	}
+	var kept []string
+	for _, name := range tools {
+		pattern, err := scanner.MatchExclusion(patterns, name)
+		if err != nil {
+			return nil, 0, err
+		}
+		if pattern == "" {
+			kept = append(kept, name)
+		}
+	}
+	return kept, len(tools) - len(kept), nil
+}
+
 // processTools is the worker function that processes tools from the input channel
-func processTools(toolChan <-chan string, resultChan chan<- toolResult, catalog *types.Catalog, storage *config.Storage, force bool) {
-	p := parser.New()
-	bashGen := generator.NewBash()
-	zshGen := generator.NewZsh()
+func processTools(toolChan <-chan string, resultChan chan<- toolResult, catalog *types.Catalog, storage *config.Storage, writer config.CompletionWriter, parserCfg parser.ParserConfig, overrides map[string]types.ToolOverride, opts GenerateOptions) {
+	p := parser.New(parserCfg)
+	outputs := newShellOutputs(writer, opts.Dynamic, opts.CompletionStyle == "compact")
+	// Scripts rendered elsewhere don't count as generated, so --output
+	// always renders and never skips what the data directory has
+	external := opts.Output != ""
+	force := opts.Force || external
+	cutoff := time.Now().Add(-opts.OlderThan)
 
 	for name := range toolChan {
 		entry := catalog.Tools[name]
 		result := toolResult{Name: name}
 
-		// Parse the tool (also detects version)
-		tool, err := p.Parse(name, entry.Path)
+		// Imported specs replace --help parsing; only re-parse when forced,
+		// and never when there is no binary to run. --output renders the
+		// stored spec instead.
+		keepSpec := entry.Imported && (entry.Path == "" || !opts.Force)
+		if keepSpec && !external {
+			result.Status = "skipped"
+			if entry.Path == "" {
+				result.Message = "imported spec with no binary on PATH"
+			} else {
+				result.Message = "imported spec (use --force to re-parse)"
+			}
+			resultChan <- result
+			continue
+		}
+
+		var tool *types.Tool
+		var err error
+		if keepSpec {
+			tool, err = storage.LoadTool(name)
+		} else {
+			// Parse the tool (also detects version), honoring any per-tool override
+			toolParser := p
+			if override, ok := overrides[name]; ok {
+				toolCfg := parserCfg.WithOverride(override)
+				// An explicit --source wins over the config file
+				if opts.Source != "" {
+					toolCfg.ForceSource = parserCfg.ForceSource
+				}
+				toolParser = parser.New(toolCfg)
+			}
+			tool, err = toolParser.Parse(name, entry.Path)
+		}
 		if err != nil {
 			result.Status = "failed"
 			result.Error = err
@@ -196,19 +548,50 @@ func processTools(toolChan <-chan string, resultChan chan<- toolResult, catalog
 
 		// Skip tools we couldn't parse
 		if tool.Source == "none" {
+			result.Status = "unparseable"
+			result.Source = tool.Source
+			resultChan <- result
+			continue
+		}
+
+		// Drop malformed flags and commands that would break the scripts, and
+		// those marked as false positives in 'tabgen review'
+		issues := tool.Sanitize()
+		tool.Remove(overrides[name].Remove)
+
+		// Debugging output: hand the parsed tool back instead of writing scripts
+		if opts.OutputFormat == "json" {
+			result.Status = "parsed"
+			result.Tool = tool
+			result.Warnings = issues
+			resultChan <- result
 			continue
 		}
 
 		// Compute content hash for cache invalidation
 		contentHash := tool.ContentHash()
+		result.Description = tool.Description
 
-		// Check if we can skip (already generated with same version AND content hash)
-		if !force && entry.Generated && entry.GeneratedVersion != "" {
+		// Without version detection, keep the last known version so later
+		// runs still have something to compare against
+		if opts.NoVersion {
+			tool.Version = entry.GeneratedVersion
+		}
+
+		// Check if we can skip (already generated with same version AND content hash,
+		// and with the same script options). Without version detection, the
+		// content hash decides alone.
+		if !force && entry.Generated && (entry.GeneratedVersion != "" || opts.NoVersion) {
 			versionMatch := entry.GeneratedVersion == tool.Version
 			hashMatch := entry.ContentHash != "" && entry.ContentHash == contentHash
+			dynamicMatch := entry.Dynamic == opts.Dynamic
+			hiddenMatch := entry.IncludeHidden == opts.IncludeHidden
+			styleMatch := (entry.CompletionStyle == "compact") == (opts.CompletionStyle == "compact")
+			stale := opts.OlderThan > 0 && entry.GeneratedBefore(cutoff)
 
-			if versionMatch && hashMatch {
+			if versionMatch && hashMatch && dynamicMatch && hiddenMatch && styleMatch && !stale {
 				result.Status = "skipped"
+				result.Message = "up to date"
 				resultChan <- result
 				continue
 			}
@@ -220,42 +603,209 @@ func processTools(toolChan <-chan string, resultChan chan<- toolResult, catalog
 			} else if !hashMatch {
 				result.Status = "hash_changed"
 				result.Message = "help output changed"
+			} else if !dynamicMatch {
+				result.Status = "success"
+				result.Message = "--dynamic changed"
+			} else if !hiddenMatch {
+				result.Status = "success"
+				result.Message = "--include-hidden changed"
+			} else if !styleMatch {
+				result.Status = "success"
+				result.Message = "completion style changed"
+			} else {
+				result.Status = "success"
+				result.Message = fmt.Sprintf("older than %v", opts.OlderThan)
 			}
 		} else {
 			result.Status = "success"
 		}
 
-		// Save parsed tool data
-		if err := storage.SaveTool(tool); err != nil {
-			result.Status = "failed"
-			result.Error = fmt.Errorf("failed to save: %w", err)
+		// Nothing extracted: the scripts would only fall back to file completion
+		result.Empty = len(tool.Subcommands) == 0 && len(tool.GlobalFlags) == 0
+		if result.Empty && opts.SkipEmpty {
+			result.Version = tool.Version
+			result.Source = tool.Source
 			resultChan <- result
 			continue
 		}
 
-		// Generate bash completion with bounds checking
-		bashResult := bashGen.GenerateWithLimits(tool)
-		if err := storage.SaveBashCompletion(name, bashResult.Script); err != nil {
-			result.Status = "failed"
-			result.Error = fmt.Errorf("failed to save bash completion: %w", err)
+		// A dry run stops once the decision is made
+		if opts.DryRun {
+			result.Version = tool.Version
+			result.Source = tool.Source
+			result.Warnings = issues
 			resultChan <- result
 			continue
 		}
 
-		// Generate zsh completion with bounds checking
-		zshResult := zshGen.GenerateWithLimits(tool)
-		if err := storage.SaveZshCompletion(name, zshResult.Script); err != nil {
+		// Save parsed tool data, which belongs to the data directory's scripts
+		if !external {
+			if err := storage.SaveTool(tool); err != nil {
+				result.Status = "failed"
+				result.Error = fmt.Errorf("failed to save: %w", err)
+				resultChan <- result
+				continue
+			}
+		}
+
+		// Generate each shell's completion with bounds checking
+		completed := completedTool(tool, opts.IncludeHidden)
+		warnings, err := writeCompletions(outputs, completed)
+		if err == nil {
+			err = writeAliasCompletions(outputs, completed, entry.Aliases)
+		}
+		if err != nil {
 			result.Status = "failed"
-			result.Error = fmt.Errorf("failed to save zsh completion: %w", err)
+			result.Error = err
 			resultChan <- result
 			continue
 		}
 
 		// Collect warnings
-		result.Warnings = append(bashResult.Warnings, zshResult.Warnings...)
+		result.Warnings = append(issues, warnings...)
 		result.Version = tool.Version
 		result.GeneratedVersion = tool.Version
+		result.GeneratedAt = time.Now()
 		result.ContentHash = contentHash
+		result.Source = tool.Source
 		resultChan <- result
 	}
 }
+
+// shellOutput pairs a shell's generator with where its scripts are saved
+type shellOutput struct {
+	shell string
+	gen   generator.Generator
+	save  func(name, content string) error
+}
+
+// newShellOutputs creates a generator for every supported shell. compact drops
+// descriptions from the zsh menus.
+func newShellOutputs(writer config.CompletionWriter, dynamic, compact bool) []shellOutput {
+	bashGen := generator.NewBash()
+	zshGen := generator.NewZsh()
+	zshGen.SetCompact(compact)
+	elvishGen := generator.NewElvish()
+	xonshGen := generator.NewXonsh()
+	nushellGen := generator.NewNushell()
+	if dynamic {
+		bashGen.SetDynamicCompletions(generator.DefaultDynamicCompletions)
+		zshGen.SetDynamicCompletions(generator.DefaultDynamicCompletions)
+		elvishGen.SetDynamicCompletions(generator.DefaultDynamicCompletions)
+		xonshGen.SetDynamicCompletions(generator.DefaultDynamicCompletions)
+		nushellGen.SetDynamicCompletions(generator.DefaultDynamicCompletions)
+	}
+	return []shellOutput{
+		{"bash", bashGen, writer.SaveBashCompletion},
+		{"zsh", zshGen, writer.SaveZshCompletion},
+		{"elvish", elvishGen, writer.SaveElvishCompletion},
+		{"tcsh", generator.NewTcsh(), writer.SaveTcshCompletion},
+		{"xonsh", xonshGen, writer.SaveXonshCompletion},
+		{"nushell", nushellGen, writer.SaveNushellCompletion},
+	}
+}
+
+// compactStyle validates a completion style and reports whether it is compact
+func compactStyle(style string) (bool, error) {
+	switch style {
+	case "", "verbose":
+		return false, nil
+	case "compact":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid completion style %q: must be verbose or compact", style)
+}
+
+// styleName is the completion style recorded in the catalog for scripts
+// written compact or not
+func styleName(compact bool) string {
+	if compact {
+		return "compact"
+	}
+	return "verbose"
+}
+
+// completedTool returns the tool scripts are written from: tool itself, or
+// with hidden flags unhidden for --include-hidden
+func completedTool(tool *types.Tool, includeHidden bool) *types.Tool {
+	if includeHidden {
+		return generator.UnhideFlags(tool)
+	}
+	return tool
+}
+
+// writeCompletions generates and saves every shell's script for a tool with
+// bounds checking, returning any truncation warnings
+func writeCompletions(outputs []shellOutput, tool *types.Tool) ([]string, error) {
+	var warnings []string
+	for _, out := range outputs {
+		result := out.gen.GenerateWithLimits(tool)
+		if err := out.save(tool.Name, result.Script); err != nil {
+			return nil, fmt.Errorf("failed to save %s completion: %w", out.shell, err)
+		}
+		warnings = append(warnings, result.Warnings...)
+	}
+	return warnings, nil
+}
+
+// writeAliasCompletions saves completions under each alias of a tool (names
+// that resolve to the same binary) from the already parsed tool
+func writeAliasCompletions(outputs []shellOutput, tool *types.Tool, aliases []string) error {
+	for _, alias := range aliases {
+		aliasTool := *tool
+		aliasTool.Name = alias
+		if _, err := writeCompletions(outputs, &aliasTool); err != nil {
+			return fmt.Errorf("alias %s: %w", alias, err)
+		}
+	}
+	return nil
+}
+
+// writeToolJSON writes a parsed tool as indented JSON
+func writeToolJSON(path string, tool *types.Tool) error {
+	data, err := json.MarshalIndent(tool, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tool: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// printToolsJSON prints parsed tools to stdout, sorted by name: a single
+// object when one tool was requested, otherwise an array
+func printToolsJSON(tools []*types.Tool, single bool) error {
+	if len(tools) == 0 {
+		return nil
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	var v any = tools
+	if single {
+		v = tools[0]
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode tools: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// printGenerated prints a generated tool with its progress counter, flagging
+// tools whose parse found nothing to complete
+func printGenerated(w io.Writer, counter string, result toolResult) {
+	name := result.Name
+	if result.Version != "" {
+		name += " (v" + result.Version + ")"
+	}
+	if result.Empty {
+		fmt.Fprintf(w, "  %s ⚠ %s (no completions extracted)\n", counter, name)
+	} else if result.Message != "" {
+		// Regenerated because something changed
+		fmt.Fprintf(w, "  %s ↻ %s: %s\n", counter, name, result.Message)
+	} else {
+		fmt.Fprintf(w, "  %s ✓ %s\n", counter, name)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Fprintf(w, "    ⚠ %s\n", warning)
+	}
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// writeFakeTool puts a script on PATH whose --help lists a couple of flags
+func writeFakeTool(t *testing.T, name string) string {
+	t.Helper()
+	return writeFakeToolHelp(t, name, `Options:\n  -v, --verbose   Be verbose\n  --color         Use color\n`)
+}
+
+// writeFakeToolHelp puts a script on PATH whose --help prints a usage line
+// and then options, a printf format
+func writeFakeToolHelp(t *testing.T, name, options string) string {
+	t.Helper()
+	bin := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+--version) echo "` + name + ` 1.0.0" ;;
+*) printf 'Usage: ` + name + ` [options]\n\n` + options + `' ;;
+esac
+`
+	path := filepath.Join(bin, name)
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return path
+}
+
+func TestGenerate_OutputLeavesDataDir(t *testing.T) {
+	dataDir := useDataDir(t)
+	path := writeFakeTool(t, "fakecli")
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"fakecli": {Name: "fakecli", Path: path}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+
+	out := t.TempDir()
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}, Output: out}) })
+	if _, err := os.Stat(filepath.Join(out, "bash", "fakecli")); err != nil {
+		t.Errorf("expected a bash script in the output directory: %v", err)
+	}
+	if storage.ToolExists("fakecli") {
+		t.Error("expected no stored tool data after --output")
+	}
+	catalog, _ = storage.LoadCatalog()
+	if entry := catalog.Tools["fakecli"]; entry.Generated || entry.ContentHash != "" {
+		t.Errorf("expected the catalog untouched by --output, got %+v", entry)
+	}
+
+	// A plain run still has the data directory's scripts to write
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}}) })
+	bashDir, _ := storage.CompletionPaths()
+	if _, err := os.Stat(filepath.Join(bashDir, "fakecli")); err != nil {
+		t.Errorf("expected generate to write the data directory's script: %v", err)
+	}
+
+	// --output renders tools the data directory already has, without --force
+	again := t.TempDir()
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}, Output: again}) })
+	if _, err := os.Stat(filepath.Join(again, "bash", "fakecli")); err != nil {
+		t.Errorf("expected --output to render an up-to-date tool: %v", err)
+	}
+}
+
+func TestGenerate_DynamicRegenerates(t *testing.T) {
+	dataDir := useDataDir(t)
+	path := writeFakeToolHelp(t, "git", `Options:\n  -b, --branch <branch>   Branch to use\n`)
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"git": {Name: "git", Path: path}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"git"}}) })
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"git"}, Dynamic: true}) })
+
+	bashDir, _ := storage.CompletionPaths()
+	data, err := os.ReadFile(filepath.Join(bashDir, "git"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "git branch --format") {
+		t.Errorf("expected --dynamic to regenerate an up-to-date tool:\n%s", data)
+	}
+	catalog, _ = storage.LoadCatalog()
+	if !catalog.Tools["git"].Dynamic {
+		t.Error("expected the catalog to record --dynamic")
+	}
+}
+
+func TestGenerate_CompletionStyleRegenerates(t *testing.T) {
+	dataDir := useDataDir(t)
+	path := writeFakeTool(t, "fakecli")
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"fakecli": {Name: "fakecli", Path: path}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}}) })
+	// The config changes the style for later runs
+	cfg := types.DefaultConfig()
+	cfg.CompletionStyle = "compact"
+	if err := storage.SaveConfig(&cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}}) })
+
+	_, zshDir := storage.CompletionPaths()
+	data, err := os.ReadFile(filepath.Join(zshDir, "_fakecli"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "Be verbose") {
+		t.Errorf("expected a compact script after the style changed:\n%s", data)
+	}
+	catalog, _ = storage.LoadCatalog()
+	if got := catalog.Tools["fakecli"].CompletionStyle; got != "compact" {
+		t.Errorf("recorded completion style = %q, want compact", got)
+	}
+}
+
+func TestGenerate_IncludeHiddenRegenerates(t *testing.T) {
+	dataDir := useDataDir(t)
+	path := writeFakeToolHelp(t, "fakecli", `Options:\n  --color         Use color\n\nHidden Flags:\n  --secret        Internal use\n`)
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"fakecli": {Name: "fakecli", Path: path}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}}) })
+	bashDir, _ := storage.CompletionPaths()
+	script := filepath.Join(bashDir, "fakecli")
+	if data, _ := os.ReadFile(script); strings.Contains(string(data), "--secret") {
+		t.Fatalf("expected the hidden flag left out by default:\n%s", data)
+	}
+
+	captureStdout(t, func() error { return Generate(GenerateOptions{Tools: []string{"fakecli"}, IncludeHidden: true}) })
+	if data, _ := os.ReadFile(script); !strings.Contains(string(data), "--secret") {
+		t.Errorf("expected --include-hidden to regenerate an up-to-date tool:\n%s", data)
+	}
+	catalog, _ = storage.LoadCatalog()
+	if !catalog.Tools["fakecli"].IncludeHidden {
+		t.Error("expected the catalog to record --include-hidden")
+	}
+}
@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/parser"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Import ingests an existing command spec and generates completions from it
+func Import(path, name string) error {
+	if path == "" {
+		return fmt.Errorf("file required: tabgen import <file>")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read spec: %w", err)
+	}
+
+	tool, err := parser.ParseSpec(name, data)
+	if err != nil {
+		return fmt.Errorf("failed to parse spec: %w", err)
+	}
+	// The name becomes file names under tools/ and completions/
+	if tool.Name == "." || tool.Name == ".." || strings.ContainsAny(tool.Name, `/\`) {
+		return fmt.Errorf("invalid tool name %q", tool.Name)
+	}
+
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	storage.SetCompression(cfg.CompressTools)
+	storage.SetBackups(cfg.KeepBackups)
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	entry, ok := catalog.Tools[tool.Name]
+	if !ok {
+		entry = types.CatalogEntry{Name: tool.Name, LastScan: time.Now()}
+	}
+	if tool.Path == "" {
+		tool.Path = entry.Path
+	}
+	if tool.Path == "" {
+		if found, err := exec.LookPath(tool.Name); err == nil {
+			tool.Path = found
+		}
+	}
+	entry.Path = tool.Path
+
+	tool.Source = "import"
+	tool.ParsedAt = time.Now()
+
+	// The same cleanup generate applies to parsed tools
+	issues := tool.Sanitize()
+	tool.Remove(cfg.Overrides[tool.Name].Remove)
+
+	if err := storage.SaveTool(tool); err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	}
+
+	warnings, err := writeCompletions(newShellOutputs(storage, false, cfg.CompletionStyle == "compact"), tool)
+	if err != nil {
+		return err
+	}
+	if err := storage.RefreshBundles(); err != nil {
+		return fmt.Errorf("failed to refresh bundles: %w", err)
+	}
+
+	entry.Generated = true
+	entry.Imported = true
+	entry.Source = tool.Source
+	entry.Description = tool.Description
+	entry.LastError = ""
+	entry.FailedAt = time.Time{}
+	entry.Version = tool.Version
+	entry.GeneratedVersion = tool.Version
+	entry.GeneratedAt = time.Now()
+	entry.ContentHash = tool.ContentHash()
+	entry.Dynamic = false
+	entry.IncludeHidden = false
+	entry.CompletionStyle = styleName(cfg.CompletionStyle == "compact")
+	catalog.Tools[tool.Name] = entry
+
+	if err := storage.SaveCatalog(catalog); err != nil {
+		return fmt.Errorf("failed to save catalog: %w", err)
+	}
+
+	fmt.Printf("Imported %s: %d subcommands, %d global flags\n",
+		tool.Name, len(tool.Subcommands), len(tool.GlobalFlags))
+	for _, w := range append(issues, warnings...) {
+		fmt.Printf("  ⚠ %s\n", w)
+	}
+	if tool.Path == "" {
+		fmt.Printf("%s isn't on PATH, so 'tabgen generate' always skips it.\n", tool.Name)
+	} else {
+		fmt.Println("Imported tools are skipped by 'tabgen generate' unless --force is given.")
+	}
+
+	return nil
+}
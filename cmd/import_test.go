@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestImport_RejectsPathNames(t *testing.T) {
+	dataDir := useDataDir(t)
+	spec := filepath.Join(t.TempDir(), "spec.json")
+
+	for _, name := range []string{"../x", "a/b", "..", "."} {
+		content := `{"name": "` + name + `", "global_flags": [{"name": "--ok"}]}`
+		if err := os.WriteFile(spec, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := Import(spec, "")
+		if err == nil || !strings.Contains(err.Error(), fmt.Sprintf("invalid tool name %q", name)) {
+			t.Errorf("expected an invalid name error importing a tool named %q, got %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "x.json")); !os.IsNotExist(err) {
+		t.Error("expected nothing written outside tools/")
+	}
+}
+
+func TestImport_CleansSpec(t *testing.T) {
+	useDataDir(t)
+	t.Setenv("PATH", t.TempDir())
+
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cfg := types.DefaultConfig()
+	cfg.Overrides = map[string]types.ToolOverride{"specd": {Remove: []string{"--bogus"}}}
+	if err := storage.SaveConfig(&cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+
+	spec := filepath.Join(t.TempDir(), "spec.json")
+	content := `{"name": "specd", "global_flags": [{"name": "--ok"}, {"name": "--with space"}, {"name": "--bogus"}]}`
+	if err := os.WriteFile(spec, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() error { return Import(spec, "") })
+
+	tool, err := storage.LoadTool("specd")
+	if err != nil {
+		t.Fatalf("LoadTool failed: %v", err)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--ok" {
+		t.Errorf("expected only --ok after cleanup, got %+v", tool.GlobalFlags)
+	}
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		t.Fatalf("LoadCatalog failed: %v", err)
+	}
+	if entry := catalog.Tools["specd"]; !entry.Imported || entry.Path != "" {
+		t.Errorf("expected an imported entry without a path, got %+v", entry)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/xml"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,9 +12,40 @@ import (
 	"github.com/jvalentini/tabgen/internal/config"
 )
 
+// InstallOptions configures the install command
+type InstallOptions struct {
+	SkipTimer bool // Skip systemd timer/cron setup
+	System    bool // Install into system-wide completion dirs instead of per-user
+	Print     bool // Print the shell hook snippets instead of installing anything
+}
+
 // Install sets up TabGen: symlinks, timers, and shell hooks
-func Install(skipTimer bool) error {
-	storage, err := config.New("")
+func Install(opts InstallOptions) error {
+	if opts.System {
+		if err := requireRoot(); err != nil {
+			return err
+		}
+		dataDir, err := systemDataDir()
+		if err != nil {
+			return err
+		}
+		// Root only reads here: creating missing directories would leave
+		// root-owned ones in the user's data directory
+		storage, err := config.Open(dataDir)
+		if err != nil {
+			return fmt.Errorf("failed to open data directory: %w", err)
+		}
+		fmt.Println("Installing TabGen system-wide...")
+		fmt.Printf("  (from %s)\n", storage.BaseDir())
+		if err := installSystem(storage); err != nil {
+			return err
+		}
+		fmt.Println("\nInstallation complete! Completions load automatically in new shells.")
+		fmt.Println("Re-run 'sudo tabgen install --system' after generating new completions.")
+		return nil
+	}
+
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -23,6 +55,10 @@ func Install(skipTimer bool) error {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
+	if opts.Print {
+		return printShellHooks(storage, home)
+	}
+
 	fmt.Println("Installing TabGen...")
 
 	// Step 1: Create symlinks for completions
@@ -31,7 +67,7 @@ func Install(skipTimer bool) error {
 	}
 
 	// Step 2: Set up timer/cron for daily scans
-	if !skipTimer {
+	if !opts.SkipTimer {
 		if err := installTimer(storage, home); err != nil {
 			fmt.Printf("Warning: failed to set up timer: %v\n", err)
 			fmt.Println("You can run 'tabgen scan' manually instead.")
@@ -47,6 +83,9 @@ func Install(skipTimer bool) error {
 	fmt.Println("\nTo activate completions, restart your shell or run:")
 	fmt.Println("  source ~/.bashrc  # for bash")
 	fmt.Println("  source ~/.zshrc   # for zsh")
+	fmt.Println("  source ~/.tcshrc  # for tcsh")
+	fmt.Println("  source ~/.xonshrc # for xonsh")
+	fmt.Println("  or restart nushell")
 
 	return nil
 }
@@ -96,18 +135,36 @@ func createSymlink(src, dest string) error {
 
 // installTimer sets up systemd user timer, launchd agent, or cron
 func installTimer(storage *config.Storage, home string) error {
+	dataDir, err := scheduledDataDir(storage)
+	if err != nil {
+		return err
+	}
+
 	// On macOS, use launchd
 	if hasLaunchd() {
-		return installLaunchd(home)
+		return installLaunchd(home, dataDir)
 	}
 
 	// On Linux, check if systemd user instance is available
 	if hasSystemdUser() {
-		return installSystemdTimer(home)
+		return installSystemdTimer(home, dataDir)
 	}
 
 	// Fall back to cron
-	return installCron()
+	return installCron(dataDir)
+}
+
+// scheduledDataDir returns the absolute data directory the daily scan must be
+// pointed at, or "" when tabgen is using the default ~/.tabgen
+func scheduledDataDir(storage *config.Storage) (string, error) {
+	if config.DataDir == "" {
+		return "", nil
+	}
+	dir, err := filepath.Abs(storage.BaseDir())
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve data directory: %w", err)
+	}
+	return dir, nil
 }
 
 // hasSystemdUser checks if systemd user instance is available
@@ -126,7 +183,7 @@ func hasLaunchd() bool {
 }
 
 // installLaunchd installs a macOS launchd agent for daily scans
-func installLaunchd(home string) error {
+func installLaunchd(home, dataDir string) error {
 	launchAgentsDir := filepath.Join(home, "Library", "LaunchAgents")
 	if err := os.MkdirAll(launchAgentsDir, 0755); err != nil {
 		return fmt.Errorf("failed to create LaunchAgents dir: %w", err)
@@ -139,33 +196,8 @@ func installLaunchd(home string) error {
 	}
 
 	// Create launchd plist for daily scan at 4am
-	plistContent := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
-<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
-<plist version="1.0">
-<dict>
-    <key>Label</key>
-    <string>com.tabgen.scan</string>
-    <key>ProgramArguments</key>
-    <array>
-        <string>%s</string>
-        <string>scan</string>
-    </array>
-    <key>StartCalendarInterval</key>
-    <dict>
-        <key>Hour</key>
-        <integer>4</integer>
-        <key>Minute</key>
-        <integer>0</integer>
-    </dict>
-    <key>StandardOutPath</key>
-    <string>%s</string>
-    <key>StandardErrorPath</key>
-    <string>%s</string>
-</dict>
-</plist>
-`, tabgenPath,
-		filepath.Join(home, "Library", "Logs", "tabgen-scan.log"),
-		filepath.Join(home, "Library", "Logs", "tabgen-scan.log"))
+	logPath := filepath.Join(home, "Library", "Logs", "tabgen-scan.log")
+	plistContent := launchdPlist(tabgenPath, dataDir, logPath)
 
 	plistPath := filepath.Join(launchAgentsDir, "com.tabgen.scan.plist")
 	if err := os.WriteFile(plistPath, []byte(plistContent), 0644); err != nil {
@@ -188,8 +220,50 @@ func installLaunchd(home string) error {
 	return nil
 }
 
+// launchdPlist returns the launchd agent that runs the scan daily at 4am
+func launchdPlist(tabgenPath, dataDir, logPath string) string {
+	args := []string{tabgenPath}
+	if dataDir != "" {
+		args = append(args, "--data-dir", dataDir)
+	}
+	args = append(args, "scan")
+
+	var program strings.Builder
+	for _, arg := range args {
+		program.WriteString("        <string>")
+		xml.EscapeText(&program, []byte(arg))
+		program.WriteString("</string>\n")
+	}
+	var log strings.Builder
+	xml.EscapeText(&log, []byte(logPath))
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+    <key>Label</key>
+    <string>com.tabgen.scan</string>
+    <key>ProgramArguments</key>
+    <array>
+%s    </array>
+    <key>StartCalendarInterval</key>
+    <dict>
+        <key>Hour</key>
+        <integer>4</integer>
+        <key>Minute</key>
+        <integer>0</integer>
+    </dict>
+    <key>StandardOutPath</key>
+    <string>%s</string>
+    <key>StandardErrorPath</key>
+    <string>%s</string>
+</dict>
+</plist>
+`, program.String(), log.String(), log.String())
+}
+
 // installSystemdTimer installs a systemd user timer
-func installSystemdTimer(home string) error {
+func installSystemdTimer(home, dataDir string) error {
 	userDir := filepath.Join(home, ".config", "systemd", "user")
 	if err := os.MkdirAll(userDir, 0755); err != nil {
 		return err
@@ -202,13 +276,7 @@ func installSystemdTimer(home string) error {
 	}
 
 	// Write service file
-	serviceContent := fmt.Sprintf(`[Unit]
-Description=TabGen completion scanner
-
-[Service]
-Type=oneshot
-ExecStart=%s scan
-`, tabgenPath)
+	serviceContent := systemdService(tabgenPath, dataDir)
 
 	servicePath := filepath.Join(userDir, "tabgen-scan.service")
 	if err := os.WriteFile(servicePath, []byte(serviceContent), 0644); err != nil {
@@ -248,14 +316,42 @@ WantedBy=timers.target
 	return nil
 }
 
+// systemdService returns the oneshot unit the daily timer starts
+func systemdService(tabgenPath, dataDir string) string {
+	execStart := tabgenPath + " scan"
+	if dataDir != "" {
+		// Quoted for systemd, which also expands "%" specifiers
+		quoted := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%").Replace(dataDir)
+		execStart = fmt.Sprintf(`%s --data-dir "%s" scan`, tabgenPath, quoted)
+	}
+	return fmt.Sprintf(`[Unit]
+Description=TabGen completion scanner
+
+[Service]
+Type=oneshot
+ExecStart=%s
+`, execStart)
+}
+
+// cronLine returns the crontab entry for the daily scan at 4am
+func cronLine(tabgenPath, dataDir string) string {
+	command := tabgenPath + " scan"
+	if dataDir != "" {
+		// Single-quoted for sh; cron turns an unescaped "%" into a newline
+		quoted := strings.NewReplacer("'", `'\''`, "%", `\%`).Replace(dataDir)
+		command = fmt.Sprintf("%s --data-dir '%s' scan", tabgenPath, quoted)
+	}
+	return fmt.Sprintf("0 4 * * * %s >/dev/null 2>&1 # tabgen daily scan\n", command)
+}
+
 // installCron adds a cron job for daily scanning
-func installCron() error {
+func installCron(dataDir string) error {
 	tabgenPath, err := os.Executable()
 	if err != nil {
 		tabgenPath = "tabgen"
 	}
 
-	cronLine := fmt.Sprintf("0 4 * * * %s scan >/dev/null 2>&1 # tabgen daily scan\n", tabgenPath)
+	line := cronLine(tabgenPath, dataDir)
 
 	// Get current crontab
 	cmd := exec.Command("crontab", "-l")
@@ -263,17 +359,27 @@ func installCron() error {
 	currentCron := string(output)
 
 	// Check if already installed
-	if strings.Contains(currentCron, "# tabgen daily scan") {
+	if strings.Contains(currentCron, line) {
 		fmt.Println("  ✓ Cron job already installed")
 		return nil
 	}
 
-	// Add our line
-	newCron := currentCron + cronLine
+	// Add our line, replacing one for another data directory, as the
+	// systemd and launchd installs overwrite their single job
+	var newCron strings.Builder
+	for _, existing := range strings.SplitAfter(currentCron, "\n") {
+		if existing != "" && !strings.Contains(existing, "# tabgen daily scan") {
+			newCron.WriteString(existing)
+		}
+	}
+	if newCron.Len() > 0 && !strings.HasSuffix(newCron.String(), "\n") {
+		newCron.WriteString("\n")
+	}
+	newCron.WriteString(line)
 
 	// Install new crontab
 	cmd = exec.Command("crontab", "-")
-	cmd.Stdin = strings.NewReader(newCron)
+	cmd.Stdin = strings.NewReader(newCron.String())
 	if err := cmd.Run(); err != nil {
 		return fmt.Errorf("failed to install cron job: %w", err)
 	}
@@ -282,47 +388,196 @@ func installCron() error {
 	return nil
 }
 
-// installShellHooks adds shell startup hooks
-func installShellHooks(storage *config.Storage, home string) error {
-	bashSrc, zshSrc := storage.CompletionPaths()
+// Lines that open and close every hook so uninstall can remove the whole block
+const (
+	hookStartMarker = "# TabGen completions"
+	hookEndMarker   = "# End TabGen completions"
+)
 
-	// Bash hook
-	bashrcPath := filepath.Join(home, ".bashrc")
-	bashHook := fmt.Sprintf(`
+// shellHook is a snippet that loads TabGen completions from a shell's startup file
+type shellHook struct {
+	shell   string // Display name, e.g. "Bash"
+	rcPath  string // Startup file the snippet belongs in
+	content string // Snippet, wrapped in the hookStartMarker and hookEndMarker lines
+	enabled bool   // Whether install adds it; optional shells only get hooks for their users
+}
+
+// shellHooks returns the startup snippets for every supported shell
+func shellHooks(storage *config.Storage, home string) []shellHook {
+	bashSrc, zshSrc := storage.CompletionPaths()
+	tcshSrc := storage.TcshCompletionPath()
+	tcshrcPath := filepath.Join(home, ".tcshrc")
+	xonshrcPath := filepath.Join(home, ".xonshrc")
+	nuConfigPath := nushellConfigPath(home)
+
+	return []shellHook{
+		{
+			shell:  "Bash",
+			rcPath: filepath.Join(home, ".bashrc"),
+			content: fmt.Sprintf(`
 # TabGen completions
-if [ -d "%s" ]; then
+if [ -f "%s" ]; then
+    source "%s"
+elif [ -d "%s" ]; then
     for f in "%s"/*; do
         [ -f "$f" ] && source "$f"
     done
 fi
-`, bashSrc, bashSrc)
-
-	if err := appendIfNotPresent(bashrcPath, bashHook, "# TabGen completions"); err != nil {
-		fmt.Printf("Warning: could not update .bashrc: %v\n", err)
-	} else {
-		fmt.Println("  ✓ Bash hook added to ~/.bashrc")
-	}
-
-	// Zsh hook
-	zshrcPath := filepath.Join(home, ".zshrc")
-	zshHook := fmt.Sprintf(`
+# End TabGen completions
+`, storage.BashBundlePath(), storage.BashBundlePath(), bashSrc, bashSrc),
+			enabled: true,
+		},
+		{
+			shell:  "Zsh",
+			rcPath: filepath.Join(home, ".zshrc"),
+			content: fmt.Sprintf(`
 # TabGen completions
-if [ -d "%s" ]; then
+if [ -f "%s" ]; then
+    autoload -Uz compinit && compinit -C
+    source "%s"
+elif [ -d "%s" ]; then
     fpath=("%s" $fpath)
     autoload -Uz compinit && compinit -C
 fi
-`, zshSrc, zshSrc)
+# End TabGen completions
+`, storage.ZshBundlePath(), storage.ZshBundlePath(), zshSrc, zshSrc),
+			enabled: true,
+		},
+		{
+			// Only for tcsh users so other shells don't get a stray .tcshrc.
+			// A glob keeps paths with spaces whole; nonomatch lets it match
+			// nothing in an empty directory.
+			shell:  "Tcsh",
+			rcPath: tcshrcPath,
+			content: fmt.Sprintf(`
+# TabGen completions
+if ( -d "%s" ) then
+    if ( ! $?nonomatch ) then
+        set nonomatch _tabgen_nm
+    endif
+    foreach f ( "%s"/* )
+        if ( -f "$f" ) source "$f"
+    end
+    if ( $?_tabgen_nm ) unset nonomatch _tabgen_nm
+endif
+# End TabGen completions
+`, tcshSrc, tcshSrc),
+			enabled: usesTcsh(tcshrcPath),
+		},
+		{
+			shell:  "Xonsh",
+			rcPath: xonshrcPath,
+			content: fmt.Sprintf(`
+# TabGen completions
+for _tabgen_f in g`+"`%s/*.xsh`"+`:
+    source @(_tabgen_f)
+# End TabGen completions
+`, storage.XonshCompletionPath()),
+			enabled: usesXonsh(xonshrcPath),
+		},
+		{
+			// source needs a constant path, so config.nu sources an index
+			// that lists every script
+			shell:  "Nushell",
+			rcPath: nuConfigPath,
+			content: fmt.Sprintf(`
+# TabGen completions
+source "%s"
+# End TabGen completions
+`, storage.NushellIndexPath()),
+			enabled: usesNushell(nuConfigPath),
+		},
+	}
+}
 
-	if err := appendIfNotPresent(zshrcPath, zshHook, "# TabGen completions"); err != nil {
-		fmt.Printf("Warning: could not update .zshrc: %v\n", err)
-	} else {
-		fmt.Println("  ✓ Zsh hook added to ~/.zshrc")
+// installShellHooks adds shell startup hooks
+func installShellHooks(storage *config.Storage, home string) error {
+	for _, hook := range shellHooks(storage, home) {
+		if !hook.enabled {
+			continue
+		}
+		if hook.shell == "Nushell" {
+			// The index must exist before config.nu sources it
+			if err := storage.WriteNushellIndex(); err != nil {
+				fmt.Printf("Warning: could not write nushell index: %v\n", err)
+			}
+		}
+
+		if err := appendIfNotPresent(hook.rcPath, hook.content, hookStartMarker); err != nil {
+			fmt.Printf("Warning: could not update %s: %v\n", filepath.Base(hook.rcPath), err)
+		} else {
+			fmt.Printf("  ✓ %s hook added to %s\n", hook.shell, tildePath(home, hook.rcPath))
+		}
 	}
 
 	return nil
 }
 
-// appendIfNotPresent appends content to a file if marker is not present
+// printShellHooks writes every shell's startup snippet to stdout, for users
+// who manage their rc files themselves
+func printShellHooks(storage *config.Storage, home string) error {
+	// Nushell fails to start if the sourced index is missing
+	if err := storage.WriteNushellIndex(); err != nil {
+		return fmt.Errorf("failed to write nushell index: %w", err)
+	}
+
+	for i, hook := range shellHooks(storage, home) {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("# %s: add to %s\n", hook.shell, tildePath(home, hook.rcPath))
+		fmt.Print(strings.TrimPrefix(hook.content, "\n"))
+	}
+	return nil
+}
+
+// tildePath abbreviates a path under home with "~"
+func tildePath(home, path string) string {
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
+// nushellConfigPath returns nushell's config.nu, under $XDG_CONFIG_HOME or ~/.config
+func nushellConfigPath(home string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "nushell", "config.nu")
+	}
+	return filepath.Join(home, ".config", "nushell", "config.nu")
+}
+
+// usesNushell reports whether the user runs nushell: either configPath exists
+// or $SHELL names it
+func usesNushell(configPath string) bool {
+	if _, err := os.Stat(configPath); err == nil {
+		return true
+	}
+	return filepath.Base(os.Getenv("SHELL")) == "nu"
+}
+
+// usesXonsh reports whether the user runs xonsh: either rcPath exists or
+// $SHELL names it
+func usesXonsh(rcPath string) bool {
+	if _, err := os.Stat(rcPath); err == nil {
+		return true
+	}
+	return filepath.Base(os.Getenv("SHELL")) == "xonsh"
+}
+
+// usesTcsh reports whether the user runs tcsh or csh: either rcPath exists or
+// $SHELL names one of them
+func usesTcsh(rcPath string) bool {
+	if _, err := os.Stat(rcPath); err == nil {
+		return true
+	}
+	shell := filepath.Base(os.Getenv("SHELL"))
+	return shell == "tcsh" || shell == "csh"
+}
+
+// appendIfNotPresent appends content to a file if marker is not present. A
+// block from before hooks ended with hookEndMarker is replaced, so uninstall
+// can later remove it whole.
 func appendIfNotPresent(path, content, marker string) error {
 	// Read existing content
 	existing, err := os.ReadFile(path)
@@ -331,8 +586,15 @@ func appendIfNotPresent(path, content, marker string) error {
 	}
 
 	// Check if already present
-	if strings.Contains(string(existing), marker) {
-		return nil // Already installed
+	if text := string(existing); strings.Contains(text, marker) {
+		if strings.Contains(text, hookEndMarker) {
+			return nil // Already installed
+		}
+		stripped, _ := stripHookBlock(text, marker)
+		if stripped != "" && !strings.HasSuffix(stripped, "\n") {
+			stripped += "\n"
+		}
+		return os.WriteFile(path, []byte(stripped+content), 0644)
 	}
 
 	// Append content
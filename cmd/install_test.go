@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+func TestScheduledScan_DataDir(t *testing.T) {
+	tests := []struct {
+		name, got, want string
+	}{
+		{"systemd default", systemdService("/bin/tabgen", ""), "ExecStart=/bin/tabgen scan\n"},
+		{"systemd data dir", systemdService("/bin/tabgen", "/tmp/a b%"), `ExecStart=/bin/tabgen --data-dir "/tmp/a b%%" scan` + "\n"},
+		{"cron default", cronLine("/bin/tabgen", ""), "0 4 * * * /bin/tabgen scan >/dev/null"},
+		{"cron data dir", cronLine("/bin/tabgen", "/tmp/it's%"), `0 4 * * * /bin/tabgen --data-dir '/tmp/it'\''s\%' scan >/dev/null`},
+		{"launchd data dir", launchdPlist("/bin/tabgen", "/tmp/a&b", "/tmp/log"),
+			"<string>/bin/tabgen</string>\n        <string>--data-dir</string>\n        <string>/tmp/a&amp;b</string>\n        <string>scan</string>\n"},
+	}
+	for _, tt := range tests {
+		if !strings.Contains(tt.got, tt.want) {
+			t.Errorf("%s: expected %q in:\n%s", tt.name, tt.want, tt.got)
+		}
+	}
+	if plist := launchdPlist("/bin/tabgen", "", "/tmp/log"); strings.Contains(plist, "--data-dir") {
+		t.Errorf("expected no --data-dir for the default directory:\n%s", plist)
+	}
+}
+
+func TestUninstall_HookLeavesValidRC(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	storage, err := config.New(filepath.Join(home, ".tabgen"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, hook := range shellHooks(storage, home) {
+		if hook.shell != "Bash" && hook.shell != "Zsh" {
+			continue
+		}
+		before := "export FOO=1\n"
+		after := "alias ll='ls -l'\n"
+		if err := os.WriteFile(hook.rcPath, []byte(before+hook.content+after), 0644); err != nil {
+			t.Fatal(err)
+		}
+		removeHookFromFile(hook.rcPath, hookStartMarker, false)
+
+		data, err := os.ReadFile(hook.rcPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "TabGen") || strings.Contains(string(data), "elif") {
+			t.Errorf("%s: hook left behind:\n%s", hook.shell, data)
+		}
+		if !strings.Contains(string(data), "FOO=1") || !strings.Contains(string(data), "alias ll") {
+			t.Errorf("%s: user lines removed:\n%s", hook.shell, data)
+		}
+		if out, err := exec.Command(bash, "-n", hook.rcPath).CombinedOutput(); err != nil {
+			t.Errorf("%s: rc file no longer parses: %v\n%s", hook.shell, err, out)
+		}
+	}
+}
+
+func TestInstallHook_ReplacesLegacyBlock(t *testing.T) {
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	home := t.TempDir()
+	storage, err := config.New(filepath.Join(home, ".tabgen"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	rc := filepath.Join(home, ".bashrc")
+	legacy := "export FOO=1\n\n# TabGen completions\nif [ -f \"/b\" ]; then\n    source \"/b\"\nelif [ -d \"/d\" ]; then\n    for f in \"/d\"/*; do\n        [ -f \"$f\" ] && source \"$f\"\n    done\nfi\nalias ll='ls -l'\n"
+	if err := os.WriteFile(rc, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error { return installShellHooks(storage, home) })
+	data, _ := os.ReadFile(rc)
+	if n := strings.Count(string(data), hookStartMarker); n != 1 || !strings.Contains(string(data), hookEndMarker) {
+		t.Fatalf("expected the legacy hook replaced by one marked block:\n%s", data)
+	}
+	if !strings.Contains(string(data), "alias ll") {
+		t.Errorf("expected user lines kept:\n%s", data)
+	}
+
+	// Reinstalling leaves the marked block alone, and uninstall removes it whole
+	captureStdout(t, func() error { return installShellHooks(storage, home) })
+	removeHookFromFile(rc, hookStartMarker, false)
+	data, _ = os.ReadFile(rc)
+	if strings.Contains(string(data), "TabGen") || strings.Contains(string(data), "elif") {
+		t.Errorf("hook left behind:\n%s", data)
+	}
+	if out, err := exec.Command(bash, "-n", rc).CombinedOutput(); err != nil {
+		t.Errorf("rc file no longer parses: %v\n%s", err, out)
+	}
+}
+
+func TestStripHookBlock_Legacy(t *testing.T) {
+	legacy := `export FOO=1
+
+# TabGen completions
+if [ -f "/b" ]; then
+    source "/b"
+elif [ -d "/d" ]; then
+    for f in "/d"/*; do
+        [ -f "$f" ] && source "$f"
+    done
+fi
+alias ll='ls -l'`
+	got, _ := stripHookBlock(legacy, hookStartMarker)
+	if want := "export FOO=1\n\nalias ll='ls -l'"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTcshHook_PathWithSpace(t *testing.T) {
+	home := t.TempDir()
+	storage, err := config.New(filepath.Join(home, "My Data"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, hook := range shellHooks(storage, home) {
+		if hook.shell != "Tcsh" {
+			continue
+		}
+		want := `foreach f ( "` + storage.TcshCompletionPath() + `"/* )`
+		if !strings.Contains(hook.content, want) || strings.Contains(hook.content, "`ls") {
+			t.Errorf("expected the tcsh hook to glob the quoted directory:\n%s", hook.content)
+		}
+	}
+}
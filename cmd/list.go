@@ -1,15 +1,76 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
+	"strings"
 
 	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/parser"
+	"github.com/jvalentini/tabgen/internal/scanner"
+	"github.com/jvalentini/tabgen/internal/types"
 )
 
+// ListOptions configures the list command. Filters combine: a tool must match all that are set.
+type ListOptions struct {
+	All         bool   // Show every tool instead of a summary
+	JSON        bool   // Emit matching catalog entries as JSON
+	Generated   bool   // Only tools with generated completions
+	Failed      bool   // Only tools whose last generation failed
+	Unparseable bool   // Only tools with no usable --help or man page
+	Stale       bool   // Only tools whose binary changed since completions were generated
+	Sort        string // "name" (default), "usage", "subcommands", or "version"
+}
+
+// filtering reports whether any filter is set
+func (o ListOptions) filtering() bool {
+	return o.Generated || o.Failed || o.Unparseable || o.Stale
+}
+
+// matches reports whether a catalog entry passes every filter that is set
+func (o ListOptions) matches(storage *config.Storage, entry types.CatalogEntry) bool {
+	if o.Generated && !entry.Generated {
+		return false
+	}
+	if o.Failed && entry.LastError == "" {
+		return false
+	}
+	if o.Unparseable && entry.Source != "none" {
+		return false
+	}
+	if o.Stale && !isStale(storage, entry) {
+		return false
+	}
+	return true
+}
+
+// isStale reports whether a tool's binary was modified after its completions were generated
+func isStale(storage *config.Storage, entry types.CatalogEntry) bool {
+	if !entry.Generated || entry.Path == "" {
+		return false
+	}
+	info, err := os.Stat(entry.Path)
+	if err != nil {
+		return false
+	}
+	tool, err := storage.LoadTool(entry.Name)
+	if err != nil {
+		return false
+	}
+	return info.ModTime().After(tool.ParsedAt)
+}
+
 // List shows discovered tools and their status
-func List(showAll bool) error {
-	storage, err := config.New("")
+func List(opts ListOptions) error {
+	switch opts.Sort {
+	case "", "name", "usage", "subcommands", "version":
+	default:
+		return fmt.Errorf("invalid sort %q: must be name, usage, subcommands, or version", opts.Sort)
+	}
+
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -19,17 +80,41 @@ func List(showAll bool) error {
 		return fmt.Errorf("failed to load catalog: %w", err)
 	}
 
-	if len(catalog.Tools) == 0 {
+	if len(catalog.Tools) == 0 && !opts.JSON {
 		fmt.Println("No tools in catalog. Run 'tabgen scan' first.")
 		return nil
 	}
 
-	// Sort tool names
+	// Sort tool names, keeping only those that match the filters
 	names := make([]string, 0, len(catalog.Tools))
-	for name := range catalog.Tools {
-		names = append(names, name)
+	for name, entry := range catalog.Tools {
+		if opts.matches(storage, entry) {
+			names = append(names, name)
+		}
 	}
 	sort.Strings(names)
+	details := sortListNames(names, storage, catalog, opts.Sort)
+
+	if opts.JSON {
+		entries := make([]types.CatalogEntry, 0, len(names))
+		for _, name := range names {
+			entries = append(entries, catalog.Tools[name])
+		}
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode tools: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if opts.filtering() {
+		fmt.Printf("%d of %d tools match\n\n", len(names), len(catalog.Tools))
+		for _, name := range names {
+			printListEntry(catalog.Tools[name], details[name])
+		}
+		return nil
+	}
 
 	// Count generated
 	generated := 0
@@ -42,7 +127,7 @@ func List(showAll bool) error {
 
 	fmt.Printf("Catalog: %d tools (%d with completions generated)\n\n", len(names), generated)
 
-	if !showAll && len(names) > 50 {
+	if !opts.All && len(names) > 50 {
 		// Show just generated tools and first 20
 		fmt.Println("Generated completions:")
 		hasGenerated := false
@@ -57,29 +142,96 @@ func List(showAll bool) error {
 			fmt.Println("  (none yet)")
 		}
 
-		fmt.Println("\nFirst 20 tools in catalog:")
+		if opts.Sort == "" || opts.Sort == "name" {
+			fmt.Println("\nFirst 20 tools in catalog:")
+		} else {
+			fmt.Printf("\nTop 20 tools by %s:\n", opts.Sort)
+		}
 		for i, name := range names {
 			if i >= 20 {
 				break
 			}
-			entry := catalog.Tools[name]
-			status := " "
-			if entry.Generated {
-				status = "✓"
-			}
-			fmt.Printf("  [%s] %s\n", status, name)
+			printListEntry(catalog.Tools[name], details[name])
 		}
 		fmt.Printf("\n... and %d more. Use 'tabgen list --all' to see all.\n", len(names)-20)
 	} else {
 		for _, name := range names {
-			entry := catalog.Tools[name]
-			status := " "
-			if entry.Generated {
-				status = "✓"
-			}
-			fmt.Printf("  [%s] %s\n", status, name)
+			printListEntry(catalog.Tools[name], details[name])
 		}
 	}
 
 	return nil
 }
+
+// sortListNames reorders alphabetically sorted names by key: history uses or
+// stored subcommand count (most first), or version (highest first, unknown
+// last). It returns what each tool was sorted by, for display.
+func sortListNames(names []string, storage *config.Storage, catalog *types.Catalog, key string) map[string]string {
+	details := make(map[string]string, len(names))
+	switch key {
+	case "usage":
+		counts, _ := scanner.CommandCounts(false)
+		uses := make(map[string]int, len(names))
+		for _, name := range names {
+			for _, alias := range append([]string{name}, catalog.Tools[name].Aliases...) {
+				uses[name] += counts[alias]
+			}
+			details[name] = fmt.Sprintf("%d uses", uses[name])
+		}
+		sort.SliceStable(names, func(i, j int) bool { return uses[names[i]] > uses[names[j]] })
+	case "subcommands":
+		subcommands := make(map[string]int, len(names))
+		for _, name := range names {
+			if tool, err := storage.LoadTool(name); err == nil {
+				for _, e := range tool.Entries() {
+					if !e.IsFlag {
+						subcommands[name]++
+					}
+				}
+			}
+			details[name] = fmt.Sprintf("%d subcommands", subcommands[name])
+		}
+		sort.SliceStable(names, func(i, j int) bool { return subcommands[names[i]] > subcommands[names[j]] })
+	case "version":
+		for _, name := range names {
+			if v := catalog.Tools[name].Version; v != "" {
+				details[name] = "version " + v
+			}
+		}
+		sort.SliceStable(names, func(i, j int) bool {
+			a, b := catalog.Tools[names[i]].Version, catalog.Tools[names[j]].Version
+			if a == "" || b == "" {
+				return a != "" && b == ""
+			}
+			return parser.CompareVersions(a, b) > 0
+		})
+	}
+	return details
+}
+
+// printListEntry prints one tool with its generated marker, summary, and any
+// failure. detail, if set, is what the list is sorted by.
+func printListEntry(entry types.CatalogEntry, detail string) {
+	status := " "
+	if entry.Generated {
+		status = "✓"
+	}
+	line := fmt.Sprintf("  [%s] %s", status, entry.Name)
+	if detail != "" {
+		line += " (" + detail + ")"
+	}
+	if entry.Description != "" {
+		line += " - " + entry.Description
+	}
+	if len(entry.Aliases) > 0 {
+		line += " (aliases: " + strings.Join(entry.Aliases, ", ") + ")"
+	}
+	if entry.LastError != "" {
+		failed := "failed"
+		if !entry.FailedAt.IsZero() {
+			failed += " " + entry.FailedAt.Local().Format("2006-01-02 15:04")
+		}
+		line += " (" + failed + ": " + strings.TrimSpace(entry.LastError) + ")"
+	}
+	fmt.Println(line)
+}
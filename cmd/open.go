@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+// OpenOptions configures the open command
+type OpenOptions struct {
+	Tool string
+	Edit bool // Open the files in $EDITOR after printing their paths
+}
+
+// Open prints the absolute paths of a tool's parsed JSON and its bash and zsh
+// completion scripts, and with Edit opens them in $EDITOR
+func Open(opts OpenOptions) error {
+	name := opts.Tool
+	if name == "" {
+		return fmt.Errorf("tool required: tabgen open <tool>")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid tool name %q", name)
+	}
+
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+	entry, ok := catalog.Tools[name]
+	if !ok {
+		return fmt.Errorf("tool %q not found in catalog", name)
+	}
+	if !entry.Generated {
+		return fmt.Errorf("no completions generated for %s yet: run 'tabgen generate %s'", name, name)
+	}
+
+	bashDir, zshDir := storage.CompletionPaths()
+	files := []struct {
+		label string
+		path  string
+	}{
+		{"json", storage.ToolFile(name)},
+		{"bash", filepath.Join(bashDir, name)},
+		{"zsh", filepath.Join(zshDir, "_"+name)},
+	}
+
+	var paths []string
+	for _, f := range files {
+		if f.path == "" {
+			fmt.Printf("%-5s (missing)\n", f.label)
+			continue
+		}
+		path, err := filepath.Abs(f.path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", f.path, err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			fmt.Printf("%-5s %s (missing)\n", f.label, path)
+			continue
+		}
+		fmt.Printf("%-5s %s\n", f.label, path)
+		paths = append(paths, path)
+	}
+
+	if !opts.Edit {
+		return nil
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no files to edit for %s", name)
+	}
+	// $EDITOR may carry arguments, as in "code -w"
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		return fmt.Errorf("--edit needs $EDITOR to be set")
+	}
+	editCmd := exec.Command(editor[0], append(editor[1:], paths...)...)
+	editCmd.Stdin, editCmd.Stdout, editCmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := editCmd.Run(); err != nil {
+		return fmt.Errorf("failed to run %s: %w", editor[0], err)
+	}
+	return nil
+}
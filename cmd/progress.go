@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// progress tracks how far a batch operation has got and, when live, redraws
+// a single status line on stderr in place of per-item output
+type progress struct {
+	total int
+	done  int
+	live  bool
+}
+
+// newProgress creates a progress tracker for total items
+func newProgress(total int, live bool) *progress {
+	return &progress{total: total, live: live}
+}
+
+// next advances the count and returns a "[ 12/400]" counter for the item
+func (p *progress) next() string {
+	p.done++
+	width := len(strconv.Itoa(p.total))
+	return fmt.Sprintf("[%*d/%d]", width, p.done, p.total)
+}
+
+// draw redraws the live status line after an item finishes
+func (p *progress) draw(name string) {
+	if p.live {
+		fmt.Fprintf(os.Stderr, "\r\033[K[%d/%d] %s", p.done, p.total, name)
+	}
+}
+
+// clear erases the live status line so regular output can be printed
+func (p *progress) clear() {
+	if p.live {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
+
+// isTerminal reports whether f is connected to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
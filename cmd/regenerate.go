@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/scanner"
+)
+
+// Regenerate clears a tool's cached parse and completions, then re-parses it
+func Regenerate(name string) error {
+	if name == "" {
+		return fmt.Errorf("tool required: tabgen regenerate <tool>")
+	}
+
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	storage.SetBackups(cfg.KeepBackups)
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	entry, ok := catalog.Tools[name]
+	if !ok {
+		return fmt.Errorf("tool %q not found in catalog. Run 'tabgen scan' first.", name)
+	}
+
+	// Generate would skip the tool, so check before deleting anything
+	pattern, err := scanner.MatchExclusion(cfg.Excluded, name)
+	if err != nil {
+		return err
+	}
+	if pattern != "" {
+		return fmt.Errorf("%s is excluded by pattern %q; remove the exclusion to regenerate it", name, pattern)
+	}
+	if entry.Path == "" {
+		return fmt.Errorf("%s has no binary on PATH to re-parse", name)
+	}
+	if _, err := os.Stat(entry.Path); err != nil {
+		return fmt.Errorf("%s can't be re-parsed: %w", name, err)
+	}
+
+	if entry.Imported {
+		// The imported spec stays until a parse replaces it
+		for _, script := range append([]string{name}, entry.Aliases...) {
+			if err := storage.RemoveCompletions(script); err != nil {
+				return fmt.Errorf("failed to remove completions for %s: %w", script, err)
+			}
+		}
+	} else if err := deleteToolFiles(storage, entry); err != nil {
+		return err
+	}
+
+	// Reset cache state so the skip logic has nothing stale to compare against
+	entry.Generated = false
+	entry.GeneratedVersion = ""
+	entry.ContentHash = ""
+	catalog.Tools[name] = entry
+	if err := storage.SaveCatalog(catalog); err != nil {
+		return fmt.Errorf("failed to save catalog: %w", err)
+	}
+
+	fmt.Printf("Cleared cached data for %s\n", name)
+	return Generate(GenerateOptions{Tools: []string{name}, Force: true})
+}
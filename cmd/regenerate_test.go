@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestRegenerate_KeepsWhatItCantRebuild(t *testing.T) {
+	dataDir := useDataDir(t)
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	path := writeFakeTool(t, "kept")
+	cfg := types.DefaultConfig()
+	cfg.Excluded = []string{"kept"}
+	if err := storage.SaveConfig(&cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{
+		"specd": {Name: "specd", Imported: true, Generated: true},
+		"kept":  {Name: "kept", Path: path, Generated: true},
+	}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+	for _, name := range []string{"specd", "kept"} {
+		tool := &types.Tool{Name: name, GlobalFlags: []types.Flag{{Name: "--ok"}}}
+		if err := storage.SaveTool(tool); err != nil {
+			t.Fatalf("SaveTool failed: %v", err)
+		}
+	}
+
+	for _, name := range []string{"specd", "kept"} {
+		if err := Regenerate(name); err == nil {
+			t.Errorf("expected regenerate %s to refuse", name)
+		}
+		if !storage.ToolExists(name) {
+			t.Errorf("expected %s's stored data kept", name)
+		}
+	}
+}
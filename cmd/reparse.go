@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/parser"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Reparse regenerates completions from saved raw help output without running any tools
+func Reparse(name string) error {
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	storage.SetCompression(cfg.CompressTools)
+	storage.SetBackups(cfg.KeepBackups)
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	names := []string{name}
+	if name == "" {
+		names, err = storage.ListRaw()
+		if err != nil {
+			return fmt.Errorf("failed to list raw output: %w", err)
+		}
+		sort.Strings(names)
+	}
+
+	if len(names) == 0 {
+		fmt.Println("No raw output saved. Run 'tabgen config set save_raw true', then 'tabgen generate --force'.")
+		return nil
+	}
+
+	compact := cfg.CompletionStyle == "compact"
+	succeeded, failed := 0, 0
+
+	for _, n := range names {
+		parserCfg := parser.DefaultConfig()
+		parserCfg.ManFallback = cfg.ManFallback
+		p := parser.New(parserCfg.WithOverride(cfg.Overrides[n]))
+		if err := reparseTool(p, storage, compact, catalog, n, cfg.Overrides[n].Remove); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", n, err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", n)
+		succeeded++
+	}
+
+	if err := storage.SaveCatalog(catalog); err != nil {
+		return fmt.Errorf("failed to save catalog: %w", err)
+	}
+	if err := storage.RefreshBundles(); err != nil {
+		return fmt.Errorf("failed to refresh bundles: %w", err)
+	}
+
+	fmt.Printf("\nDone: %d reparsed, %d failed\n", succeeded, failed)
+	return nil
+}
+
+// reparseTool parses one tool's raw output and saves its data and completions,
+// leaving out the entries at the remove paths. Scripts keep the dynamic
+// completions and hidden flags they were last generated with.
+func reparseTool(p *parser.Parser, storage *config.Storage, compact bool, catalog *types.Catalog, name string, remove []string) error {
+	text, err := storage.LoadRaw(name)
+	if err != nil {
+		return fmt.Errorf("failed to load raw output: %w", err)
+	}
+
+	tool, err := p.ParseFromText(name, text)
+	if err != nil {
+		return err
+	}
+	if tool.Source == "none" {
+		return fmt.Errorf("no help or man page output in raw file")
+	}
+
+	// Running the tool is what detects these, so carry them over from the catalog
+	entry, ok := catalog.Tools[name]
+	if !ok {
+		entry = types.CatalogEntry{Name: name}
+	}
+	tool.Path = entry.Path
+	tool.Version = entry.Version
+	issues := tool.Sanitize()
+	tool.Remove(remove)
+
+	if err := storage.SaveTool(tool); err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	}
+
+	outputs := newShellOutputs(storage, entry.Dynamic, compact)
+	completed := completedTool(tool, entry.IncludeHidden)
+	warnings, err := writeCompletions(outputs, completed)
+	if err != nil {
+		return err
+	}
+	if err := writeAliasCompletions(outputs, completed, entry.Aliases); err != nil {
+		return err
+	}
+	for _, w := range append(issues, warnings...) {
+		fmt.Printf("    ⚠ %s\n", w)
+	}
+
+	entry.Generated = true
+	entry.CompletionStyle = styleName(compact)
+	entry.Imported = false
+	entry.Source = tool.Source
+	entry.Description = tool.Description
+	entry.LastError = ""
+	entry.FailedAt = time.Time{}
+	entry.GeneratedVersion = tool.Version
+	entry.GeneratedAt = time.Now()
+	entry.ContentHash = tool.ContentHash()
+	catalog.Tools[name] = entry
+	return nil
+}
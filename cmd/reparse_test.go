@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestReparse_KeepsDynamic(t *testing.T) {
+	dataDir := useDataDir(t)
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"git": {Name: "git", Generated: true, Dynamic: true}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+	raw := "usage: git [options]\n\nOptions:\n  -b, --branch <branch>   Branch to use\n"
+	if err := os.MkdirAll(storage.RawPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storage.RawPath(), "git.txt"), []byte(raw), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error { return Reparse("git") })
+
+	bashDir, _ := storage.CompletionPaths()
+	data, err := os.ReadFile(filepath.Join(bashDir, "git"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "git branch --format") {
+		t.Errorf("expected reparse to keep dynamic completions:\n%s", data)
+	}
+}
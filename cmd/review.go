@@ -0,0 +1,308 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// reviewSummary is a generated tool's line in the review list
+type reviewSummary struct {
+	name     string
+	commands int
+	flags    int
+	suspects int
+}
+
+// Review walks through parsed tools in the terminal so false-positive flags
+// and commands can be removed. Removals are saved to the tool JSON and its
+// scripts right away, and as the tool's "remove" override so later parses
+// drop them too. Nothing is run; it works from the stored tool data.
+func Review(name string) error {
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	cfg, err := storage.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	storage.SetCompression(cfg.CompressTools)
+	storage.SetBackups(cfg.KeepBackups)
+
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		return fmt.Errorf("failed to load catalog: %w", err)
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	if name != "" {
+		return reviewTool(in, storage, cfg, catalog, name)
+	}
+
+	for {
+		summaries := reviewSummaries(storage, catalog)
+		if len(summaries) == 0 {
+			fmt.Println("No generated tools to review. Run 'tabgen generate' first.")
+			return nil
+		}
+
+		fmt.Printf("%4s  %-24s %9s %6s %8s\n", "#", "TOOL", "COMMANDS", "FLAGS", "SUSPECT")
+		for i, s := range summaries {
+			fmt.Printf("%4d  %-24s %9d %6d %8d\n", i+1, s.name, s.commands, s.flags, s.suspects)
+		}
+
+		answer, ok := prompt(in, "\nReview which tool? (number or name, q to quit): ")
+		if !ok || answer == "q" {
+			return nil
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(summaries) {
+			answer = summaries[n-1].name
+		}
+		if err := reviewTool(in, storage, cfg, catalog, answer); err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		fmt.Println()
+	}
+}
+
+// reviewSummaries counts the entries of every generated tool, most suspect first
+func reviewSummaries(storage *config.Storage, catalog *types.Catalog) []reviewSummary {
+	var summaries []reviewSummary
+	for name, entry := range catalog.Tools {
+		if !entry.Generated {
+			continue
+		}
+		tool, err := storage.LoadTool(name)
+		if err != nil {
+			continue
+		}
+		s := reviewSummary{name: name}
+		for _, e := range tool.Entries() {
+			if e.IsFlag {
+				s.flags++
+			} else {
+				s.commands++
+			}
+			if e.Suspect != "" {
+				s.suspects++
+			}
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].suspects != summaries[j].suspects {
+			return summaries[i].suspects > summaries[j].suspects
+		}
+		return summaries[i].name < summaries[j].name
+	})
+	return summaries
+}
+
+// reviewTool lets the user mark entries of one tool for removal, then saves
+// the removals
+func reviewTool(in *bufio.Scanner, storage *config.Storage, cfg *types.Config, catalog *types.Catalog, name string) error {
+	entry, ok := catalog.Tools[name]
+	if !ok {
+		return fmt.Errorf("tool %q not found in catalog", name)
+	}
+	tool, err := storage.LoadTool(name)
+	if err != nil {
+		return fmt.Errorf("no parsed data for %s (run 'tabgen generate %s'): %w", name, name, err)
+	}
+
+	entries := tool.Entries()
+	marked := make([]bool, len(entries))
+	printEntries(tool, entries, marked)
+	if removed := cfg.Overrides[name].Remove; len(removed) > 0 {
+		fmt.Printf("Already removed: %s\n", strings.Join(removed, ", "))
+	}
+
+	for {
+		answer, ok := prompt(in, "\nNumbers to mark or unmark (e.g. 3 5-7), s marks suspects, l lists, w saves, c clears saved removals, q goes back: ")
+		if !ok {
+			return nil
+		}
+		switch answer {
+		case "q":
+			return nil
+		case "l":
+			printEntries(tool, entries, marked)
+		case "s":
+			for i, e := range entries {
+				if e.Suspect != "" {
+					marked[i] = true
+				}
+			}
+			printMarked(entries, marked)
+		case "c":
+			return clearRemovals(storage, cfg, name)
+		case "w":
+			var paths []string
+			for i, e := range entries {
+				if marked[i] {
+					paths = append(paths, e.Path)
+				}
+			}
+			if len(paths) == 0 {
+				fmt.Println("Nothing marked.")
+				continue
+			}
+			return saveRemovals(storage, cfg, catalog, entry, tool, paths)
+		default:
+			indexes, err := parseSelection(answer, len(entries))
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			for _, i := range indexes {
+				marked[i] = !marked[i]
+			}
+			printMarked(entries, marked)
+		}
+	}
+}
+
+// printEntries prints a tool's entries numbered, indented by depth, with
+// removal marks and the reason an entry looks suspect
+func printEntries(tool *types.Tool, entries []types.Entry, marked []bool) {
+	fmt.Printf("\n%s (%s): %d entries\n", tool.Name, tool.Source, len(entries))
+	for i, e := range entries {
+		mark := " "
+		if marked[i] {
+			mark = "x"
+		}
+		label := strings.Repeat("  ", e.Depth) + e.Path[strings.LastIndex(e.Path, " ")+1:]
+		line := fmt.Sprintf("%4d %s %-30s %s", i+1, mark, label, truncateLine(e.Description, 50))
+		if e.Suspect != "" {
+			line += "  [suspect: " + e.Suspect + "]"
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+}
+
+// printMarked lists the entries currently marked for removal
+func printMarked(entries []types.Entry, marked []bool) {
+	var paths []string
+	for i, e := range entries {
+		if marked[i] {
+			paths = append(paths, e.Path)
+		}
+	}
+	if len(paths) == 0 {
+		fmt.Println("Marked: none")
+		return
+	}
+	fmt.Printf("Marked: %s\n", strings.Join(paths, ", "))
+}
+
+// truncateLine shortens s to at most n runes, ending in "..." when cut
+func truncateLine(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-3]) + "..."
+	}
+	return s
+}
+
+// parseSelection parses space- or comma-separated entry numbers and ranges
+// ("3 5-7") into zero-based indexes below count
+func parseSelection(s string, count int) ([]int, error) {
+	var indexes []int
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' }) {
+		lo, hi, isRange := strings.Cut(field, "-")
+		if !isRange {
+			hi = lo
+		}
+		start, err1 := strconv.Atoi(lo)
+		end, err2 := strconv.Atoi(hi)
+		if err1 != nil || err2 != nil || start < 1 || end > count || start > end {
+			return nil, fmt.Errorf("invalid selection %q: use numbers from 1 to %d", field, count)
+		}
+		for n := start; n <= end; n++ {
+			indexes = append(indexes, n-1)
+		}
+	}
+	return indexes, nil
+}
+
+// saveRemovals drops paths from the tool, rewrites its JSON and scripts, and
+// adds paths to the tool's remove override so regeneration keeps them out
+func saveRemovals(storage *config.Storage, cfg *types.Config, catalog *types.Catalog, entry types.CatalogEntry, tool *types.Tool, paths []string) error {
+	removed := tool.Remove(paths)
+
+	if err := storage.SaveTool(tool); err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	}
+	outputs := newShellOutputs(storage, entry.Dynamic, cfg.CompletionStyle == "compact")
+	completed := completedTool(tool, entry.IncludeHidden)
+	if _, err := writeCompletions(outputs, completed); err != nil {
+		return err
+	}
+	if err := writeAliasCompletions(outputs, completed, entry.Aliases); err != nil {
+		return err
+	}
+	if err := storage.RefreshBundles(); err != nil {
+		return fmt.Errorf("failed to refresh bundles: %w", err)
+	}
+
+	entry.ContentHash = tool.ContentHash()
+	entry.CompletionStyle = styleName(cfg.CompletionStyle == "compact")
+	catalog.Tools[tool.Name] = entry
+	if err := storage.SaveCatalog(catalog); err != nil {
+		return fmt.Errorf("failed to save catalog: %w", err)
+	}
+
+	if cfg.Overrides == nil {
+		cfg.Overrides = make(map[string]types.ToolOverride)
+	}
+	override := cfg.Overrides[tool.Name]
+	for _, path := range paths {
+		if !slices.Contains(override.Remove, path) {
+			override.Remove = append(override.Remove, path)
+		}
+	}
+	cfg.Overrides[tool.Name] = override
+	if err := storage.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("Removed %d entries from %s and rewrote its completions\n", removed, tool.Name)
+	return nil
+}
+
+// clearRemovals forgets the tool's saved removals. The entries come back the
+// next time the tool is parsed.
+func clearRemovals(storage *config.Storage, cfg *types.Config, name string) error {
+	override, ok := cfg.Overrides[name]
+	if !ok || len(override.Remove) == 0 {
+		fmt.Printf("No saved removals for %s\n", name)
+		return nil
+	}
+	override.Remove = nil
+	cfg.Overrides[name] = override
+	if len(override.VersionCmds) == 0 && override.MaxDepth == 0 && override.ForceSource == "" && len(override.ExtraHelpArgs) == 0 {
+		delete(cfg.Overrides, name)
+	}
+	if err := storage.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("Cleared saved removals for %s. Run 'tabgen regenerate %s' to bring them back.\n", name, name)
+	return nil
+}
+
+// prompt prints question and reads a trimmed answer. ok is false at end of input.
+func prompt(in *bufio.Scanner, question string) (answer string, ok bool) {
+	fmt.Print(question)
+	if !in.Scan() {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(in.Text()), true
+}
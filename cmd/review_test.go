@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestSaveRemovals_KeepsDynamic(t *testing.T) {
+	storage, err := config.New(useDataDir(t))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cfg := types.DefaultConfig()
+	entry := types.CatalogEntry{Name: "git", Generated: true, Dynamic: true}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"git": entry}}
+	tool := &types.Tool{Name: "git", GlobalFlags: []types.Flag{
+		{Name: "--branch", Arg: "BRANCH", Dynamic: "branch"},
+		{Name: "--bogus"},
+	}}
+
+	captureStdout(t, func() error { return saveRemovals(storage, &cfg, catalog, entry, tool, []string{"--bogus"}) })
+
+	bashDir, _ := storage.CompletionPaths()
+	data, err := os.ReadFile(filepath.Join(bashDir, "git"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "git branch --format") {
+		t.Errorf("expected the rewritten script to keep dynamic completions:\n%s", data)
+	}
+	if strings.Contains(string(data), "--bogus") {
+		t.Errorf("expected --bogus removed:\n%s", data)
+	}
+}
+
+func TestSaveRemovals_KeepsHiddenFlags(t *testing.T) {
+	storage, err := config.New(useDataDir(t))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cfg := types.DefaultConfig()
+	entry := types.CatalogEntry{Name: "fakecli", Generated: true, IncludeHidden: true}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"fakecli": entry}}
+	tool := &types.Tool{Name: "fakecli", GlobalFlags: []types.Flag{
+		{Name: "--secret", Hidden: true},
+		{Name: "--bogus"},
+	}}
+
+	captureStdout(t, func() error { return saveRemovals(storage, &cfg, catalog, entry, tool, []string{"--bogus"}) })
+
+	bashDir, _ := storage.CompletionPaths()
+	data, err := os.ReadFile(filepath.Join(bashDir, "fakecli"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "--secret") {
+		t.Errorf("expected the rewritten script to keep hidden flags:\n%s", data)
+	}
+}
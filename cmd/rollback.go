@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+// Rollback restores a tool's completion scripts from their newest backups
+func Rollback(name string) error {
+	if name == "" {
+		return fmt.Errorf("tool required: tabgen rollback <tool>")
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid tool name %q", name)
+	}
+
+	storage, err := config.New(config.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	restored, err := storage.RestoreCompletions(name)
+	if err != nil {
+		return fmt.Errorf("failed to restore completions: %w", err)
+	}
+	if len(restored) == 0 {
+		return fmt.Errorf("no backups found for %s (enable them with 'tabgen config set keep_backups N' or 'generate --keep N')", name)
+	}
+
+	for _, path := range restored {
+		fmt.Printf("Restored %s\n", path)
+	}
+	return nil
+}
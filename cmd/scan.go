@@ -1,16 +1,104 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"time"
 
 	"github.com/jvalentini/tabgen/internal/config"
 	"github.com/jvalentini/tabgen/internal/scanner"
+	"github.com/jvalentini/tabgen/internal/types"
 )
 
+// ScanOptions configures the scan command
+type ScanOptions struct {
+	Dirs []string // Extra directories to walk after $PATH
+	All  bool     // Catalog tools in Dirs even if absent from shell history
+	Full bool     // Check each tool for --help and man pages (slower)
+	Jobs int      // Concurrent checks in full mode (default: NumCPU)
+
+	SkipNonCLI bool // Skip shared libraries and GUI programs by reading file headers
+
+	IncludeRotated bool // Also read rotated (possibly gzipped) and session history files
+
+	Since time.Duration // Keep catalog entries for binaries unmodified in this long (0 = re-examine all)
+
+	Exclude []string // Exclusion patterns for this run only, on top of the stored ones
+
+	StatsJSON bool // Print a JSON report to stdout after scanning (human output goes to stderr)
+	Prune     bool // Delete stored data and completions of tools whose binary is gone
+}
+
+// scanStats is the report printed by scan --stats-json
+type scanStats struct {
+	Total     int      `json:"total"`      // Tools in the new catalog
+	Added     int      `json:"added"`      // Tools not in the previous catalog
+	Removed   int      `json:"removed"`    // Tools in the previous catalog that are gone
+	Excluded  int      `json:"excluded"`   // Executables skipped by exclusion patterns
+	Pruned    int      `json:"pruned"`     // Vanished tools whose files were deleted (with --prune)
+	ElapsedMS int64    `json:"elapsed_ms"` // Scan duration in milliseconds
+	NewTools  []string `json:"new_tools"`  // Names of the added tools, sorted
+}
+
+// rememberExtraDirs adds this run's --dir directories, made absolute, to the
+// ones saved in the config so later scans (including the scheduled one) walk
+// them too, and returns them all
+func rememberExtraDirs(storage *config.Storage, cfg *types.Config, dirs []string, all bool) ([]string, error) {
+	if len(dirs) == 0 {
+		return cfg.ExtraDirs, nil
+	}
+	for _, dir := range dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", dir, err)
+		}
+		if !slices.Contains(cfg.ExtraDirs, abs) {
+			cfg.ExtraDirs = append(cfg.ExtraDirs, abs)
+		}
+	}
+	if all {
+		cfg.ExtraDirsAll = true
+	}
+	if err := storage.SaveConfig(cfg); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	return cfg.ExtraDirs, nil
+}
+
+// keepGeneratedState copies what generate recorded about each tool from the
+// previous catalog into the freshly scanned one. Imported tools are kept even
+// when the scan didn't find them, since their spec needs no binary.
+func keepGeneratedState(catalog, existingCatalog *types.Catalog) {
+	for name, entry := range catalog.Tools {
+		if existing, ok := existingCatalog.Tools[name]; ok {
+			entry.Generated = existing.Generated
+			entry.GeneratedAt = existing.GeneratedAt
+			entry.Imported = existing.Imported
+			entry.Dynamic = existing.Dynamic
+			entry.IncludeHidden = existing.IncludeHidden
+			entry.CompletionStyle = existing.CompletionStyle
+			entry.Source = existing.Source
+			entry.Description = existing.Description
+			entry.LastError = existing.LastError
+			entry.FailedAt = existing.FailedAt
+			catalog.Tools[name] = entry
+		}
+	}
+	for name, existing := range existingCatalog.Tools {
+		if _, ok := catalog.Tools[name]; !ok && existing.Imported {
+			catalog.Tools[name] = existing
+		}
+	}
+}
+
 // Scan walks $PATH and discovers executable tools
-func Scan() error {
-	storage, err := config.New("")
+func Scan(opts ScanOptions) error {
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -21,36 +109,201 @@ func Scan() error {
 	// Load existing catalog to preserve generated status
 	existingCatalog, _ := storage.LoadCatalog()
 
-	fmt.Println("Scanning $PATH for executables...")
-	if len(cfg.Excluded) > 0 {
-		fmt.Printf("  (excluding %d patterns)\n", len(cfg.Excluded))
+	// Human output goes to stderr when the JSON report is printed to stdout
+	var log io.Writer = os.Stdout
+	if opts.StatsJSON {
+		log = os.Stderr
+	}
+
+	excluded := append(slices.Clone(cfg.Excluded), opts.Exclude...)
+
+	fmt.Fprintln(log, "Scanning $PATH for executables...")
+	if len(excluded) > 0 {
+		fmt.Fprintf(log, "  (excluding %d patterns)\n", len(excluded))
 	}
 	start := time.Now()
 
-	s := scanner.New(cfg.Excluded)
+	s := scanner.New(excluded)
+	if opts.Full {
+		s = scanner.NewFull(excluded)
+		s.SetWorkers(opts.Jobs)
+		if cfg.HelpRetries > 0 {
+			s.SetHelpRetries(cfg.HelpRetries)
+		}
+		s.SetProgress(func(done, total int) {
+			fmt.Fprintf(log, "\r  Checking --help and man pages: %d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(log)
+			}
+		})
+	}
+	s.SetDedupe(cfg.DedupeLinks)
+	if opts.SkipNonCLI {
+		fmt.Fprintln(log, "  (skipping shared libraries and GUI programs)")
+		s.SetSkipNonCLI(true)
+	}
+	if opts.Since > 0 && existingCatalog != nil {
+		fmt.Fprintf(log, "  (re-examining only binaries modified in the last %v)\n", opts.Since)
+		s.SetSince(start.Add(-opts.Since), existingCatalog)
+	}
+	if opts.IncludeRotated {
+		fmt.Fprintln(log, "  (reading rotated and session history files)")
+		s.SetIncludeRotated(true)
+	}
+	dirs, err := rememberExtraDirs(storage, cfg, opts.Dirs, opts.All)
+	if err != nil {
+		return err
+	}
+	if len(dirs) > 0 {
+		fmt.Fprintf(log, "  (including %d extra directories)\n", len(dirs))
+		s.AddDirs(dirs, opts.All || cfg.ExtraDirsAll)
+	}
 	catalog, err := s.Scan()
 	if err != nil {
 		return fmt.Errorf("scan failed: %w", err)
 	}
 
-	// Preserve generated status from existing catalog
-	for name, entry := range catalog.Tools {
-		if existing, ok := existingCatalog.Tools[name]; ok {
-			entry.Generated = existing.Generated
-			catalog.Tools[name] = entry
+	keepGeneratedState(catalog, existingCatalog)
+
+	// Tools left out only by this run's --exclude keep their entries
+	for name, entry := range existingCatalog.Tools {
+		if _, ok := catalog.Tools[name]; ok {
+			continue
+		}
+		if pattern, _ := scanner.MatchExclusion(opts.Exclude, name); pattern == "" {
+			continue
 		}
+		if pattern, _ := scanner.MatchExclusion(cfg.Excluded, name); pattern != "" {
+			continue
+		}
+		catalog.Tools[name] = entry
 	}
 
 	if err := storage.SaveCatalog(catalog); err != nil {
 		return fmt.Errorf("failed to save catalog: %w", err)
 	}
 
+	vanished, err := vanishedTools(storage, existingCatalog, catalog)
+	if err != nil {
+		return fmt.Errorf("failed to list stored tools: %w", err)
+	}
+	pruned := 0
+	if opts.Prune && len(vanished) > 0 {
+		storage.SetBackups(cfg.KeepBackups)
+		if pruned, err = pruneTools(storage, vanished); err != nil {
+			return err
+		}
+	}
+
 	elapsed := time.Since(start)
 
-	fmt.Printf("Found %d executables in %v\n", len(catalog.Tools), elapsed.Round(time.Millisecond))
-	fmt.Printf("Catalog saved to %s/catalog.json\n", storage.BaseDir())
-	fmt.Printf("\nRun 'tabgen generate <tool>' to create completions for a specific tool.")
-	fmt.Printf("\nRun 'tabgen generate' to process all tools (may take a while).\n")
+	fmt.Fprintf(log, "Found %d executables in %v\n", len(catalog.Tools), elapsed.Round(time.Millisecond))
+	if opts.Full {
+		withHelp, withMan := 0, 0
+		for _, entry := range catalog.Tools {
+			if entry.HasHelp {
+				withHelp++
+			}
+			if entry.HasManPage {
+				withMan++
+			}
+		}
+		fmt.Fprintf(log, "  %d respond to --help, %d have man pages\n", withHelp, withMan)
+	}
+	if opts.Prune {
+		fmt.Fprintf(log, "Pruned %d tools no longer on disk\n", pruned)
+	} else if len(vanished) > 0 {
+		fmt.Fprintf(log, "%d tools are no longer on disk; run 'tabgen scan --prune' to delete their completions\n", len(vanished))
+	}
+	fmt.Fprintf(log, "Catalog saved to %s/catalog.json\n", storage.BaseDir())
+	fmt.Fprintf(log, "\nRun 'tabgen generate <tool>' to create completions for a specific tool.")
+	fmt.Fprintf(log, "\nRun 'tabgen generate' to process all tools (may take a while).\n")
+
+	if opts.StatsJSON {
+		return printScanStats(existingCatalog, catalog, s.ExcludedCount(), pruned, elapsed)
+	}
+	return nil
+}
+
+// vanishedTools returns the tools with stored data that are left out of the
+// new catalog and whose binary no longer exists. Entries from the previous
+// catalog supply paths and aliases; tools that only dropped out of shell
+// history or were newly excluded are still installed, so they are left alone,
+// as are imported specs, which don't need a binary.
+func vanishedTools(storage *config.Storage, previous, current *types.Catalog) ([]types.CatalogEntry, error) {
+	stored, err := storage.ListTools()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(stored)
+
+	var vanished []types.CatalogEntry
+	for _, name := range stored {
+		if _, ok := current.Tools[name]; ok {
+			continue
+		}
+		entry := types.CatalogEntry{Name: name}
+		if previous != nil {
+			if prev, ok := previous.Tools[name]; ok {
+				entry = prev
+			}
+		}
+		if entry.Imported {
+			continue
+		}
+		if tool, err := storage.LoadTool(name); err == nil && tool.Path != "" {
+			entry.Path = tool.Path
+		}
+		if entry.Path != "" {
+			if _, err := os.Stat(entry.Path); !os.IsNotExist(err) {
+				continue
+			}
+		}
+		vanished = append(vanished, entry)
+	}
+	return vanished, nil
+}
+
+// pruneTools deletes everything stored for each entry, alias scripts
+// included, returning how many were pruned
+func pruneTools(storage *config.Storage, entries []types.CatalogEntry) (int, error) {
+	for i, entry := range entries {
+		if err := deleteToolFiles(storage, entry); err != nil {
+			return i, err
+		}
+	}
+	return len(entries), nil
+}
+
+// printScanStats prints the --stats-json report comparing the previous and new catalogs
+func printScanStats(previous, current *types.Catalog, excluded, pruned int, elapsed time.Duration) error {
+	if previous == nil {
+		previous = &types.Catalog{}
+	}
+	stats := scanStats{
+		Total:     len(current.Tools),
+		Excluded:  excluded,
+		Pruned:    pruned,
+		ElapsedMS: elapsed.Milliseconds(),
+		NewTools:  []string{},
+	}
+	for name := range current.Tools {
+		if _, ok := previous.Tools[name]; !ok {
+			stats.NewTools = append(stats.NewTools, name)
+		}
+	}
+	for name := range previous.Tools {
+		if _, ok := current.Tools[name]; !ok {
+			stats.Removed++
+		}
+	}
+	sort.Strings(stats.NewTools)
+	stats.Added = len(stats.NewTools)
 
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode scan stats: %w", err)
+	}
+	fmt.Println(string(data))
 	return nil
 }
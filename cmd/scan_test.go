@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestKeepGeneratedState_OlderThan(t *testing.T) {
+	now := time.Now()
+	existing := &types.Catalog{Tools: map[string]types.CatalogEntry{
+		"fresh": {Name: "fresh", Generated: true, GeneratedAt: now.Add(-time.Hour)},
+		"old":   {Name: "old", Generated: true, GeneratedAt: now.Add(-48 * time.Hour)},
+	}}
+	// A rescan finds the same tools but knows nothing about generation
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{
+		"fresh": {Name: "fresh", LastScan: now},
+		"old":   {Name: "old", LastScan: now},
+		"new":   {Name: "new", LastScan: now},
+	}}
+
+	keepGeneratedState(catalog, existing)
+
+	// What generate --older-than 24h decides afterwards
+	cutoff := now.Add(-24 * time.Hour)
+	want := map[string]bool{"fresh": false, "old": true, "new": false}
+	for name, stale := range want {
+		if got := catalog.Tools[name].GeneratedBefore(cutoff); got != stale {
+			t.Errorf("%s: GeneratedBefore after a scan = %v, want %v (entry %+v)", name, got, stale, catalog.Tools[name])
+		}
+	}
+}
+
+func TestKeepGeneratedState_Description(t *testing.T) {
+	existing := &types.Catalog{Tools: map[string]types.CatalogEntry{
+		"jq": {Name: "jq", Generated: true, Description: "Command-line JSON processor"},
+	}}
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"jq": {Name: "jq"}}}
+
+	keepGeneratedState(catalog, existing)
+
+	if got := catalog.Tools["jq"].Description; got != "Command-line JSON processor" {
+		t.Errorf("Description after a scan = %q, want the previous summary", got)
+	}
+}
+
+func TestRememberExtraDirs(t *testing.T) {
+	dataDir := useDataDir(t)
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cfg, _ := storage.LoadConfig()
+	dir := t.TempDir()
+
+	if _, err := rememberExtraDirs(storage, cfg, []string{dir, dir}, true); err != nil {
+		t.Fatalf("rememberExtraDirs failed: %v", err)
+	}
+
+	// A later scan without --dir gets the saved directories back
+	cfg, _ = storage.LoadConfig()
+	dirs, err := rememberExtraDirs(storage, cfg, nil, false)
+	if err != nil {
+		t.Fatalf("rememberExtraDirs failed: %v", err)
+	}
+	if !slices.Equal(dirs, []string{dir}) {
+		t.Errorf("dirs on the next scan = %v, want [%s]", dirs, dir)
+	}
+	if !cfg.ExtraDirsAll {
+		t.Error("ExtraDirsAll not saved after --all")
+	}
+}
+
+func TestScan_KeepsImportedSpecs(t *testing.T) {
+	dataDir := useDataDir(t)
+	t.Setenv("PATH", t.TempDir())
+
+	spec := filepath.Join(t.TempDir(), "spec.json")
+	if err := os.WriteFile(spec, []byte(`{"name": "specd", "global_flags": [{"name": "--ok"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() error { return Import(spec, "") })
+
+	captureStdout(t, func() error { return Scan(ScanOptions{Prune: true}) })
+
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	catalog, err := storage.LoadCatalog()
+	if err != nil {
+		t.Fatalf("LoadCatalog failed: %v", err)
+	}
+	if entry, ok := catalog.Tools["specd"]; !ok || !entry.Imported {
+		t.Errorf("expected the imported entry kept by scan, got %+v", catalog.Tools)
+	}
+	if !storage.ToolExists("specd") {
+		t.Error("expected scan --prune to keep the imported spec")
+	}
+}
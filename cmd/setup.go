@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+)
+
+// SetupOptions configures the setup command
+type SetupOptions struct {
+	SkipTimer bool // Skip systemd timer/cron setup during install
+	Dynamic   bool // Generate runtime value completions for known tools
+}
+
+// Setup runs scan, generate, and install in order for a first-time setup.
+// Each step is safe to repeat: scan keeps generation status, generate skips
+// up-to-date tools, and install replaces its own hooks and links.
+func Setup(opts SetupOptions) error {
+	fmt.Println("==> Step 1/3: Scanning for tools")
+	if err := Scan(ScanOptions{}); err != nil {
+		return fmt.Errorf("scan step failed: %w", err)
+	}
+
+	fmt.Println("\n==> Step 2/3: Generating completions")
+	if err := Generate(GenerateOptions{Quiet: true, Dynamic: opts.Dynamic}); err != nil {
+		return fmt.Errorf("generate step failed: %w", err)
+	}
+
+	fmt.Println("\n==> Step 3/3: Installing shell hooks")
+	if err := Install(InstallOptions{SkipTimer: opts.SkipTimer}); err != nil {
+		return fmt.Errorf("install step failed: %w", err)
+	}
+
+	fmt.Println("\nSetup complete! Open a new shell to start using completions.")
+	fmt.Println("Re-run 'tabgen setup' at any time; it only redoes what changed.")
+	return nil
+}
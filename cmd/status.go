@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -11,9 +12,59 @@ import (
 	"github.com/jvalentini/tabgen/internal/config"
 )
 
+// StatusOptions configures the status command
+type StatusOptions struct {
+	System bool // Report on the system-wide installation
+	JSON   bool // Emit machine-readable JSON instead of text
+}
+
+// Check states reported for installation components
+const (
+	checkOK      = "ok"      // Installed and working
+	checkMissing = "missing" // Not installed
+	checkWarning = "warning" // Installed but not working correctly
+	checkError   = "error"   // Could not determine state
+)
+
+// componentCheck is the result of checking one installation component
+type componentCheck struct {
+	Name   string `json:"name"`
+	State  string `json:"state"` // ok, missing, warning, or error
+	Detail string `json:"detail"`
+	Path   string `json:"path,omitempty"`
+}
+
+// completionDirStatus describes a completion output directory
+type completionDirStatus struct {
+	Dir   string `json:"dir"`
+	Files int    `json:"files"`
+}
+
+// statusReport is the full status, shared by the text and JSON renderers
+type statusReport struct {
+	DataDir       string              `json:"data_dir"`
+	DataDirExists bool                `json:"data_dir_exists"`
+	CatalogError  string              `json:"catalog_error,omitempty"`
+	Tools         int                 `json:"tools"`
+	Generated     int                 `json:"generated"`
+	WithHelp      int                 `json:"with_help"`     // Populated by 'scan --full'
+	WithManPage   int                 `json:"with_man_page"` // Populated by 'scan --full'
+	LastScan      *time.Time          `json:"last_scan,omitempty"`
+	Bash          completionDirStatus `json:"bash"`
+	Zsh           completionDirStatus `json:"zsh"`
+	Elvish        completionDirStatus `json:"elvish"`
+	Tcsh          completionDirStatus `json:"tcsh"`
+	Xonsh         completionDirStatus `json:"xonsh"`
+	Nushell       completionDirStatus `json:"nushell"`
+	Symlinks      []componentCheck    `json:"symlinks,omitempty"`
+	Timer         *componentCheck     `json:"timer,omitempty"`
+	ShellHooks    []componentCheck    `json:"shell_hooks,omitempty"`
+	System        []componentCheck    `json:"system,omitempty"`
+}
+
 // Status shows the current state of TabGen installation
-func Status() error {
-	storage, err := config.New("")
+func Status(opts StatusOptions) error {
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -23,61 +74,150 @@ func Status() error {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	fmt.Println("TabGen Status")
-	fmt.Println("=============")
-	fmt.Println()
+	report := buildStatusReport(storage, home, opts.System)
 
-	// Data directory
-	baseDir := storage.BaseDir()
-	fmt.Printf("Data directory: %s\n", baseDir)
-	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
-		fmt.Println("  Status: Not initialized (run 'tabgen scan' first)")
+	if opts.JSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode status: %w", err)
+		}
+		fmt.Println(string(data))
 		return nil
 	}
-	fmt.Println("  Status: OK")
-	fmt.Println()
+
+	printStatusReport(report)
+	return nil
+}
+
+// buildStatusReport gathers installation state without printing anything
+func buildStatusReport(storage *config.Storage, home string, system bool) *statusReport {
+	report := &statusReport{DataDir: storage.BaseDir()}
+
+	if _, err := os.Stat(report.DataDir); os.IsNotExist(err) {
+		return report
+	}
+	report.DataDirExists = true
 
 	// Catalog info
 	catalog, err := storage.LoadCatalog()
 	if err != nil {
-		fmt.Printf("Catalog: Error loading (%v)\n", err)
+		report.CatalogError = err.Error()
 	} else {
-		generated := 0
+		report.Tools = len(catalog.Tools)
 		for _, entry := range catalog.Tools {
 			if entry.Generated {
-				generated++
+				report.Generated++
+			}
+			if entry.HasHelp {
+				report.WithHelp++
+			}
+			if entry.HasManPage {
+				report.WithManPage++
 			}
 		}
-		fmt.Printf("Catalog: %d tools discovered, %d with completions\n", len(catalog.Tools), generated)
 		if !catalog.LastScan.IsZero() {
-			age := time.Since(catalog.LastScan)
-			fmt.Printf("  Last scan: %s (%s ago)\n", catalog.LastScan.Format("2006-01-02 15:04"), formatDuration(age))
+			lastScan := catalog.LastScan
+			report.LastScan = &lastScan
 		}
 	}
-	fmt.Println()
 
 	// Completion directories
 	bashDir, zshDir := storage.CompletionPaths()
-	bashCount := countFiles(bashDir)
-	zshCount := countFiles(zshDir)
+	report.Bash = completionDirStatus{Dir: bashDir, Files: countFiles(bashDir)}
+	report.Zsh = completionDirStatus{Dir: zshDir, Files: countFiles(zshDir)}
+	report.Elvish = newCompletionDirStatus(storage.ElvishCompletionPath())
+	report.Tcsh = newCompletionDirStatus(storage.TcshCompletionPath())
+	report.Xonsh = newCompletionDirStatus(storage.XonshCompletionPath())
+	report.Nushell = newCompletionDirStatus(storage.NushellCompletionPath())
+
+	if system {
+		report.System = checkSystem()
+		return report
+	}
+
+	report.Symlinks = []componentCheck{
+		checkSymlink(filepath.Join(home, ".local", "share", "bash-completion", "completions", "tabgen-completions"), "Bash symlink"),
+		checkSymlink(filepath.Join(home, ".zfunc", "tabgen-completions"), "Zsh symlink"),
+	}
+	timer := checkTimer(home)
+	report.Timer = &timer
+	for _, f := range hookFiles(home) {
+		report.ShellHooks = append(report.ShellHooks, checkShellHook(f.path, f.shell+" hook"))
+	}
+
+	return report
+}
+
+// printStatusReport renders the status report as human-readable text
+func printStatusReport(report *statusReport) {
+	fmt.Println("TabGen Status")
+	fmt.Println("=============")
+	fmt.Println()
+
+	// Data directory
+	fmt.Printf("Data directory: %s\n", report.DataDir)
+	if !report.DataDirExists {
+		fmt.Println("  Status: Not initialized (run 'tabgen scan' first)")
+		return
+	}
+	fmt.Println("  Status: OK")
+	fmt.Println()
+
+	// Catalog info
+	if report.CatalogError != "" {
+		fmt.Printf("Catalog: Error loading (%s)\n", report.CatalogError)
+	} else {
+		fmt.Printf("Catalog: %d tools discovered, %d with completions\n", report.Tools, report.Generated)
+		if report.WithHelp > 0 || report.WithManPage > 0 {
+			fmt.Printf("  %d respond to --help, %d have man pages\n", report.WithHelp, report.WithManPage)
+		}
+		if report.LastScan != nil {
+			age := time.Since(*report.LastScan)
+			fmt.Printf("  Last scan: %s (%s ago)\n", report.LastScan.Format("2006-01-02 15:04"), formatDuration(age))
+		}
+	}
+	fmt.Println()
+
+	// Completion directories
 	fmt.Printf("Completions:\n")
-	fmt.Printf("  Bash: %d files in %s\n", bashCount, bashDir)
-	fmt.Printf("  Zsh:  %d files in %s\n", zshCount, zshDir)
+	fmt.Printf("  Bash:    %d files in %s\n", report.Bash.Files, report.Bash.Dir)
+	fmt.Printf("  Zsh:     %d files in %s\n", report.Zsh.Files, report.Zsh.Dir)
+	fmt.Printf("  Elvish:  %d files in %s\n", report.Elvish.Files, report.Elvish.Dir)
+	fmt.Printf("  Tcsh:    %d files in %s\n", report.Tcsh.Files, report.Tcsh.Dir)
+	fmt.Printf("  Xonsh:   %d files in %s\n", report.Xonsh.Files, report.Xonsh.Dir)
+	fmt.Printf("  Nushell: %d files in %s\n", report.Nushell.Files, report.Nushell.Dir)
 	fmt.Println()
 
-	// Symlinks
-	fmt.Println("Installation:")
-	checkSymlink(filepath.Join(home, ".local", "share", "bash-completion", "completions", "tabgen-completions"), "Bash symlink")
-	checkSymlink(filepath.Join(home, ".zfunc", "tabgen-completions"), "Zsh symlink")
+	if report.System != nil {
+		fmt.Println("System installation:")
+		printChecks(report.System)
+		return
+	}
 
-	// Timer/Cron
-	checkTimer(home)
+	fmt.Println("Installation:")
+	printChecks(report.Symlinks)
+	if report.Timer != nil {
+		printChecks([]componentCheck{*report.Timer})
+	}
+	printChecks(report.ShellHooks)
+}
 
-	// Shell hooks
-	checkShellHook(filepath.Join(home, ".bashrc"), "Bash hook")
-	checkShellHook(filepath.Join(home, ".zshrc"), "Zsh hook")
+// printChecks prints component checks with a state marker
+func printChecks(checks []componentCheck) {
+	markers := map[string]string{
+		checkOK:      "✓",
+		checkMissing: "✗",
+		checkWarning: "!",
+		checkError:   "?",
+	}
+	for _, c := range checks {
+		fmt.Printf("  [%s] %s: %s\n", markers[c.State], c.Name, c.Detail)
+	}
+}
 
-	return nil
+// newCompletionDirStatus reports the number of files in a completion directory
+func newCompletionDirStatus(dir string) completionDirStatus {
+	return completionDirStatus{Dir: dir, Files: countFiles(dir)}
 }
 
 // countFiles counts files in a directory
@@ -96,76 +236,82 @@ func countFiles(dir string) int {
 }
 
 // checkSymlink checks if a symlink exists and is valid
-func checkSymlink(path, name string) {
+func checkSymlink(path, name string) componentCheck {
+	check := componentCheck{Name: name, Path: path}
+
 	info, err := os.Lstat(path)
 	if os.IsNotExist(err) {
-		fmt.Printf("  [✗] %s: not installed\n", name)
-		return
+		check.State, check.Detail = checkMissing, "not installed"
+		return check
 	}
 	if err != nil {
-		fmt.Printf("  [?] %s: error (%v)\n", name, err)
-		return
+		check.State, check.Detail = checkError, fmt.Sprintf("error (%v)", err)
+		return check
 	}
 	if info.Mode()&os.ModeSymlink == 0 {
-		fmt.Printf("  [!] %s: exists but not a symlink\n", name)
-		return
+		check.State, check.Detail = checkWarning, "exists but not a symlink"
+		return check
 	}
 
 	// Check if target exists
 	target, err := os.Readlink(path)
 	if err != nil {
-		fmt.Printf("  [!] %s: broken symlink\n", name)
-		return
+		check.State, check.Detail = checkWarning, "broken symlink"
+		return check
 	}
 	if _, err := os.Stat(target); os.IsNotExist(err) {
-		fmt.Printf("  [!] %s: broken symlink (target missing)\n", name)
-		return
+		check.State, check.Detail = checkWarning, "broken symlink (target missing)"
+		return check
 	}
 
-	fmt.Printf("  [✓] %s: %s\n", name, path)
+	check.State, check.Detail = checkOK, path
+	return check
 }
 
 // checkTimer checks for systemd timer or cron job
-func checkTimer(home string) {
+func checkTimer(home string) componentCheck {
 	// Check systemd timer
 	timerPath := filepath.Join(home, ".config", "systemd", "user", "tabgen-scan.timer")
 	if _, err := os.Stat(timerPath); err == nil {
+		check := componentCheck{Name: "Systemd timer", Path: timerPath}
 		// Check if active
 		cmd := exec.Command("systemctl", "--user", "is-active", "tabgen-scan.timer")
 		output, _ := cmd.Output()
 		status := strings.TrimSpace(string(output))
 		if status == "active" {
-			fmt.Printf("  [✓] Systemd timer: active\n")
+			check.State, check.Detail = checkOK, "active"
 		} else {
-			fmt.Printf("  [!] Systemd timer: installed but %s\n", status)
+			check.State, check.Detail = checkWarning, "installed but "+status
 		}
-		return
+		return check
 	}
 
 	// Check cron
 	cmd := exec.Command("crontab", "-l")
 	output, err := cmd.Output()
 	if err == nil && strings.Contains(string(output), "# tabgen daily scan") {
-		fmt.Printf("  [✓] Cron job: installed\n")
-		return
+		return componentCheck{Name: "Cron job", State: checkOK, Detail: "installed"}
 	}
 
-	fmt.Printf("  [✗] Timer/Cron: not installed\n")
+	return componentCheck{Name: "Timer/Cron", State: checkMissing, Detail: "not installed"}
 }
 
 // checkShellHook checks if a shell hook is installed
-func checkShellHook(path, name string) {
+func checkShellHook(path, name string) componentCheck {
+	check := componentCheck{Name: name, Path: path}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
-		fmt.Printf("  [✗] %s: %s not found\n", name, filepath.Base(path))
-		return
+		check.State, check.Detail = checkMissing, filepath.Base(path)+" not found"
+		return check
 	}
 
-	if strings.Contains(string(data), "# TabGen completions") {
-		fmt.Printf("  [✓] %s: installed in %s\n", name, filepath.Base(path))
+	if strings.Contains(string(data), hookStartMarker) {
+		check.State, check.Detail = checkOK, "installed in "+filepath.Base(path)
 	} else {
-		fmt.Printf("  [✗] %s: not found in %s\n", name, filepath.Base(path))
+		check.State, check.Detail = checkMissing, "not found in "+filepath.Base(path)
 	}
+	return check
 }
 
 // formatDuration formats a duration in a human-readable way
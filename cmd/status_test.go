@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+func TestBuildStatusReport_AllShells(t *testing.T) {
+	dataDir := useDataDir(t)
+	home := os.Getenv("HOME")
+	storage, err := config.New(dataDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SaveTcshCompletion("foo", "complete foo"); err != nil {
+		t.Fatal(err)
+	}
+	for _, rc := range []string{".tcshrc", ".xonshrc"} {
+		content := hookStartMarker + "\n" + hookEndMarker + "\n"
+		if err := os.WriteFile(filepath.Join(home, rc), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report := buildStatusReport(storage, home, false)
+
+	if report.Tcsh.Files != 1 {
+		t.Errorf("Tcsh.Files = %d, want 1", report.Tcsh.Files)
+	}
+	states := map[string]string{}
+	for _, c := range report.ShellHooks {
+		states[c.Name] = c.State
+	}
+	want := map[string]string{
+		"Bash hook":    checkMissing,
+		"Zsh hook":     checkMissing,
+		"Tcsh hook":    checkOK,
+		"Xonsh hook":   checkOK,
+		"Nushell hook": checkMissing,
+	}
+	for name, state := range want {
+		if states[name] != state {
+			t.Errorf("%s = %q, want %q", name, states[name], state)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+// System-wide completion directories used by install --system (variables so
+// tests can point them elsewhere)
+var (
+	systemBashDir = "/usr/share/bash-completion/completions"
+	systemZshDir  = "/usr/share/zsh/site-functions"
+)
+
+// generatedMarker identifies completion scripts written by TabGen
+const generatedMarker = "# Generated by TabGen"
+
+// errNotRoot is returned when a system-wide operation runs without root
+var errNotRoot = errors.New("system-wide mode requires root (try: sudo tabgen ...)")
+
+// requireRoot fails with a permissions hint unless running as root
+func requireRoot() error {
+	if os.Geteuid() != 0 {
+		return errNotRoot
+	}
+	return nil
+}
+
+// systemDataDir returns the data directory install --system copies from.
+// Under sudo, ~ is root's home, so without --data-dir or $TABGEN_DATA_DIR it
+// is the ~/.tabgen of the user who ran sudo.
+func systemDataDir() (string, error) {
+	name := os.Getenv("SUDO_USER")
+	if config.DataDir != "" || name == "" || name == "root" {
+		return config.DataDir, nil
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to look up %s (pass --data-dir instead): %w", name, err)
+	}
+	dir := filepath.Join(u.HomeDir, ".tabgen")
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("no TabGen data for %s in %s (run 'tabgen generate' first, or pass --data-dir): %w", name, dir, err)
+	}
+	return dir, nil
+}
+
+// installSystem copies generated completions into the system completion directories.
+// Existing non-TabGen completions are left untouched.
+func installSystem(storage *config.Storage) error {
+	if err := requireRoot(); err != nil {
+		return err
+	}
+
+	bashSrc, zshSrc := storage.CompletionPaths()
+	targets := []struct {
+		label, src, dest string
+	}{
+		{"Bash", bashSrc, systemBashDir},
+		{"Zsh", zshSrc, systemZshDir},
+	}
+
+	for _, t := range targets {
+		if err := os.MkdirAll(t.dest, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", t.dest, err)
+		}
+		copied, skipped, err := copyCompletions(t.src, t.dest)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  ✓ %s completions installed: %d files in %s\n", t.label, copied, t.dest)
+		if skipped > 0 {
+			fmt.Printf("    (skipped %d existing non-TabGen completions)\n", skipped)
+		}
+	}
+
+	return nil
+}
+
+// copyCompletions copies completion files from src to dest, never overwriting
+// files that TabGen didn't generate. A missing src has nothing to copy.
+func copyCompletions(src, dest string) (copied, skipped int, err error) {
+	entries, err := os.ReadDir(src)
+	if os.IsNotExist(err) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s: %w", src, err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		target := filepath.Join(dest, entry.Name())
+		if existing, err := os.ReadFile(target); err == nil && !isGeneratedCompletion(existing) {
+			skipped++
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		if err != nil {
+			return copied, skipped, err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			if os.IsPermission(err) {
+				return copied, skipped, fmt.Errorf("%w: %v", errNotRoot, err)
+			}
+			return copied, skipped, err
+		}
+		copied++
+	}
+	return copied, skipped, nil
+}
+
+// isGeneratedCompletion reports whether a completion script was written by TabGen
+func isGeneratedCompletion(data []byte) bool {
+	return strings.Contains(string(data), generatedMarker)
+}
+
+// systemCompletionFiles returns TabGen-generated files in a system completion directory
+func systemCompletionFiles(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		if data, err := os.ReadFile(path); err == nil && isGeneratedCompletion(data) {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// checkSystem reports system-wide installation state
+func checkSystem() []componentCheck {
+	var checks []componentCheck
+	for _, t := range []struct{ label, dir string }{
+		{"Bash", systemBashDir},
+		{"Zsh", systemZshDir},
+	} {
+		check := componentCheck{Name: t.label, Path: t.dir}
+		if _, err := os.Stat(t.dir); os.IsNotExist(err) {
+			check.State, check.Detail = checkMissing, t.dir+" does not exist"
+		} else if count := len(systemCompletionFiles(t.dir)); count == 0 {
+			check.State, check.Detail = checkMissing, "no TabGen completions in "+t.dir
+		} else {
+			check.State, check.Detail = checkOK, fmt.Sprintf("%d TabGen completions in %s", count, t.dir)
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// uninstallSystem removes TabGen-generated files from the system completion directories
+func uninstallSystem() error {
+	if err := requireRoot(); err != nil {
+		return err
+	}
+
+	for _, dir := range []string{systemBashDir, systemZshDir} {
+		removed := 0
+		for _, path := range systemCompletionFiles(dir) {
+			if err := os.Remove(path); err != nil {
+				fmt.Printf("Warning: failed to remove %s: %v\n", path, err)
+				continue
+			}
+			removed++
+		}
+		if removed > 0 {
+			fmt.Printf("  ✓ Removed %d completions from %s\n", removed, dir)
+		}
+	}
+	return nil
+}
+
+// previewSystemUninstall lists the files uninstallSystem would remove
+func previewSystemUninstall() {
+	for _, dir := range []string{systemBashDir, systemZshDir} {
+		for _, path := range systemCompletionFiles(dir) {
+			fmt.Printf("  • Would remove %s\n", path)
+		}
+	}
+}
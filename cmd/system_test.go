@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+// useSystemDirs points the system completion directories at temp directories
+func useSystemDirs(t *testing.T) (bashDir, zshDir string) {
+	t.Helper()
+	oldBash, oldZsh := systemBashDir, systemZshDir
+	systemBashDir = filepath.Join(t.TempDir(), "bash-completion")
+	systemZshDir = filepath.Join(t.TempDir(), "site-functions")
+	t.Cleanup(func() { systemBashDir, systemZshDir = oldBash, oldZsh })
+	return systemBashDir, systemZshDir
+}
+
+func TestInstallSystem_KeepsForeignCompletions(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("install --system requires root")
+	}
+	bashDir, zshDir := useSystemDirs(t)
+	storage, err := config.New(useDataDir(t))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, name := range []string{"mytool", "git"} {
+		if err := storage.SaveBashCompletion(name, generatedMarker+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(bashDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(bashDir, "git"), []byte("# from the git package\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error { return installSystem(storage) })
+
+	if got := systemCompletionFiles(bashDir); len(got) != 1 || filepath.Base(got[0]) != "mytool" {
+		t.Errorf("expected only mytool installed by TabGen, got %v", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join(bashDir, "git")); string(data) != "# from the git package\n" {
+		t.Errorf("expected the packaged git completion untouched, got %q", data)
+	}
+	if _, err := os.Stat(zshDir); err != nil {
+		t.Errorf("expected the zsh directory created: %v", err)
+	}
+
+	captureStdout(t, uninstallSystem)
+	if got := systemCompletionFiles(bashDir); len(got) != 0 {
+		t.Errorf("expected uninstall to remove TabGen's files, got %v", got)
+	}
+	if _, err := os.Stat(filepath.Join(bashDir, "git")); err != nil {
+		t.Errorf("expected uninstall to keep the packaged git completion: %v", err)
+	}
+}
+
+func TestSystemDataDir_SudoUser(t *testing.T) {
+	u, err := user.Current()
+	if err != nil {
+		t.Skip("no current user")
+	}
+	old := config.DataDir
+	config.DataDir = ""
+	t.Cleanup(func() { config.DataDir = old })
+
+	t.Setenv("SUDO_USER", "")
+	if dir, err := systemDataDir(); err != nil || dir != "" {
+		t.Errorf("without sudo: got %q, %v; want the default", dir, err)
+	}
+
+	t.Setenv("SUDO_USER", "root")
+	if dir, err := systemDataDir(); err != nil || dir != "" {
+		t.Errorf("sudo from root: got %q, %v; want the default", dir, err)
+	}
+
+	if u.Username != "root" {
+		t.Setenv("SUDO_USER", u.Username)
+		want := filepath.Join(u.HomeDir, ".tabgen")
+		dir, err := systemDataDir()
+		if _, statErr := os.Stat(want); statErr != nil {
+			if err == nil {
+				t.Errorf("expected an error when %s doesn't exist", want)
+			}
+		} else if err != nil || dir != want {
+			t.Errorf("under sudo: got %q, %v; want %q", dir, err, want)
+		}
+	}
+
+	t.Setenv("SUDO_USER", "no-such-user-tabgen")
+	if _, err := systemDataDir(); err == nil {
+		t.Error("expected an error for an unknown SUDO_USER")
+	}
+
+	config.DataDir = "/explicit"
+	if dir, err := systemDataDir(); err != nil || dir != "/explicit" {
+		t.Errorf("with --data-dir: got %q, %v; want /explicit", dir, err)
+	}
+}
+
+func TestCopyCompletions_MissingSource(t *testing.T) {
+	copied, skipped, err := copyCompletions(filepath.Join(t.TempDir(), "missing"), t.TempDir())
+	if err != nil || copied != 0 || skipped != 0 {
+		t.Errorf("copyCompletions from a missing directory = %d, %d, %v; want nothing copied", copied, skipped, err)
+	}
+}
@@ -11,9 +11,30 @@ import (
 	"github.com/jvalentini/tabgen/internal/config"
 )
 
+// UninstallOptions configures the uninstall command
+type UninstallOptions struct {
+	KeepData bool // Keep the data directory
+	System   bool // Remove the system-wide installation instead of per-user
+	DryRun   bool // Only print what would be removed
+}
+
 // Uninstall removes TabGen: symlinks, timers, shell hooks, and optionally data
-func Uninstall(keepData bool) error {
-	storage, err := config.New("")
+func Uninstall(opts UninstallOptions) error {
+	if opts.System {
+		if opts.DryRun {
+			fmt.Println("Dry run: the following system-wide completions would be removed")
+			previewSystemUninstall()
+			return nil
+		}
+		fmt.Println("Uninstalling system-wide TabGen completions...")
+		if err := uninstallSystem(); err != nil {
+			return err
+		}
+		fmt.Println("\nUninstall complete!")
+		return nil
+	}
+
+	storage, err := config.New(config.DataDir)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -23,21 +44,29 @@ func Uninstall(keepData bool) error {
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	fmt.Println("Uninstalling TabGen...")
+	if opts.DryRun {
+		fmt.Println("Dry run: nothing will be removed")
+	} else {
+		fmt.Println("Uninstalling TabGen...")
+	}
 
 	// Step 1: Remove symlinks
-	removeSymlinks(home)
+	removeSymlinks(home, opts.DryRun)
 
 	// Step 2: Remove timer/cron
-	removeTimer(home)
+	removeTimer(home, opts.DryRun)
 
 	// Step 3: Remove shell hooks
-	removeShellHooks(home)
+	removeShellHooks(home, opts.DryRun)
 
 	// Step 4: Remove data if requested
-	if !keepData {
+	if !opts.KeepData {
 		baseDir := storage.BaseDir()
-		if err := os.RemoveAll(baseDir); err != nil {
+		if opts.DryRun {
+			if _, err := os.Stat(baseDir); err == nil {
+				fmt.Printf("  • Would remove data directory: %s\n", baseDir)
+			}
+		} else if err := os.RemoveAll(baseDir); err != nil {
 			fmt.Printf("Warning: failed to remove data directory: %v\n", err)
 		} else {
 			fmt.Printf("  ✓ Removed data directory: %s\n", baseDir)
@@ -46,14 +75,19 @@ func Uninstall(keepData bool) error {
 		fmt.Printf("  ℹ Data preserved at: %s\n", storage.BaseDir())
 	}
 
+	if opts.DryRun {
+		fmt.Println("\nRun without --dry-run to remove these.")
+		return nil
+	}
+
 	fmt.Println("\nUninstall complete!")
 	fmt.Println("Restart your shell to fully remove TabGen completions.")
 
 	return nil
 }
 
-// removeSymlinks removes TabGen symlinks
-func removeSymlinks(home string) {
+// removeSymlinks removes TabGen symlinks, or only reports them when dryRun is set
+func removeSymlinks(home string, dryRun bool) {
 	links := []string{
 		filepath.Join(home, ".local", "share", "bash-completion", "completions", "tabgen-completions"),
 		filepath.Join(home, ".zfunc", "tabgen-completions"),
@@ -62,6 +96,10 @@ func removeSymlinks(home string) {
 	for _, link := range links {
 		if info, err := os.Lstat(link); err == nil {
 			if info.Mode()&os.ModeSymlink != 0 {
+				if dryRun {
+					fmt.Printf("  • Would remove symlink: %s\n", link)
+					continue
+				}
 				os.Remove(link)
 				fmt.Printf("  ✓ Removed symlink: %s\n", link)
 			}
@@ -69,8 +107,14 @@ func removeSymlinks(home string) {
 	}
 }
 
-// removeTimer removes systemd timer, launchd agent, and cron job
-func removeTimer(home string) {
+// removeTimer removes systemd timer, launchd agent, and cron job, or only
+// reports them when dryRun is set
+func removeTimer(home string, dryRun bool) {
+	if dryRun {
+		previewTimer(home)
+		return
+	}
+
 	// Remove launchd agent (macOS)
 	plistPath := filepath.Join(home, "Library", "LaunchAgents", "com.tabgen.scan.plist")
 	if _, err := os.Stat(plistPath); err == nil {
@@ -128,14 +172,34 @@ func removeTimer(home string) {
 	}
 }
 
-// removeShellHooks removes TabGen hooks from shell config files
-func removeShellHooks(home string) {
-	removeHookFromFile(filepath.Join(home, ".bashrc"), "# TabGen completions")
-	removeHookFromFile(filepath.Join(home, ".zshrc"), "# TabGen completions")
+// hookFile is a shell config file that install may add a hook to
+type hookFile struct {
+	shell string
+	path  string
+}
+
+// hookFiles lists every shell config file install may add a hook to
+func hookFiles(home string) []hookFile {
+	return []hookFile{
+		{"Bash", filepath.Join(home, ".bashrc")},
+		{"Zsh", filepath.Join(home, ".zshrc")},
+		{"Tcsh", filepath.Join(home, ".tcshrc")},
+		{"Xonsh", filepath.Join(home, ".xonshrc")},
+		{"Nushell", nushellConfigPath(home)},
+	}
+}
+
+// removeShellHooks removes TabGen hooks from shell config files, or only
+// reports them when dryRun is set
+func removeShellHooks(home string, dryRun bool) {
+	for _, f := range hookFiles(home) {
+		removeHookFromFile(f.path, hookStartMarker, dryRun)
+	}
 }
 
-// removeHookFromFile removes a marked section from a file
-func removeHookFromFile(path, marker string) {
+// removeHookFromFile removes a marked section from a file. With dryRun set the
+// lines that would be removed are printed instead.
+func removeHookFromFile(path, marker string, dryRun bool) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return
@@ -146,8 +210,63 @@ func removeHookFromFile(path, marker string) {
 		return
 	}
 
+	newContent, removed := stripHookBlock(content, marker)
+	if newContent == content {
+		return
+	}
+
+	if dryRun {
+		fmt.Printf("  • Would remove hook from %s:\n", path)
+		for _, line := range removed {
+			fmt.Printf("      %s\n", line)
+		}
+		return
+	}
+
+	os.WriteFile(path, []byte(newContent), 0644)
+	fmt.Printf("  ✓ Removed hook from %s\n", filepath.Base(path))
+}
+
+// stripHookBlock returns content without the TabGen block that starts at
+// marker, along with the lines that were dropped. Blocks closed by
+// hookEndMarker are removed whole; unterminated blocks from older versions
+// fall back to stripLegacyHookBlock.
+func stripHookBlock(content, marker string) (string, []string) {
+	lines := strings.Split(content, "\n")
+	var result, removed []string
+	for i := 0; i < len(lines); i++ {
+		if !strings.Contains(lines[i], marker) {
+			result = append(result, lines[i])
+			continue
+		}
+		end := -1
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == hookEndMarker {
+				end = j
+				break
+			}
+			if strings.Contains(lines[j], marker) {
+				break
+			}
+		}
+		if end < 0 {
+			rest, dropped := stripLegacyHookBlock(strings.Join(lines[i:], "\n"), marker)
+			result = append(result, rest)
+			removed = append(removed, dropped...)
+			break
+		}
+		removed = append(removed, lines[i:end+1]...)
+		i = end
+	}
+
+	return strings.Join(result, "\n"), removed
+}
+
+// stripLegacyHookBlock removes hooks written before the end marker existed,
+// recognizing the block by the shape of the lines after marker
+func stripLegacyHookBlock(content, marker string) (string, []string) {
 	// Read line by line and skip the TabGen block
-	var result []string
+	var result, removed []string
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	inBlock := false
 	blockLines := 0
@@ -158,21 +277,26 @@ func removeHookFromFile(path, marker string) {
 		if strings.Contains(line, marker) {
 			inBlock = true
 			blockLines = 0
+			removed = append(removed, line)
 			continue
 		}
 
 		if inBlock {
 			blockLines++
-			// Skip the next few lines of the block (typically 4-5 lines)
-			if blockLines <= 5 && (strings.HasPrefix(strings.TrimSpace(line), "if") ||
-				strings.HasPrefix(strings.TrimSpace(line), "for") ||
-				strings.HasPrefix(strings.TrimSpace(line), "[") ||
-				strings.HasPrefix(strings.TrimSpace(line), "fpath") ||
-				strings.HasPrefix(strings.TrimSpace(line), "autoload") ||
-				strings.HasPrefix(strings.TrimSpace(line), "source") ||
-				strings.HasPrefix(strings.TrimSpace(line), "done") ||
-				strings.HasPrefix(strings.TrimSpace(line), "fi") ||
+			// Skip the rest of the block (at most 7 lines for the bundle hooks)
+			trimmed := strings.TrimSpace(line)
+			if blockLines <= 7 && (strings.HasPrefix(trimmed, "if") ||
+				strings.HasPrefix(trimmed, "elif") ||
+				strings.HasPrefix(trimmed, "for") ||
+				strings.HasPrefix(trimmed, "[") ||
+				strings.HasPrefix(trimmed, "fpath") ||
+				strings.HasPrefix(trimmed, "autoload") ||
+				strings.HasPrefix(trimmed, "source") ||
+				strings.HasPrefix(trimmed, "done") ||
+				strings.HasPrefix(trimmed, "fi") ||
+				strings.HasPrefix(trimmed, "end") ||
 				line == "") {
+				removed = append(removed, line)
 				continue
 			}
 			inBlock = false
@@ -181,9 +305,30 @@ func removeHookFromFile(path, marker string) {
 		result = append(result, line)
 	}
 
-	newContent := strings.Join(result, "\n")
-	if newContent != content {
-		os.WriteFile(path, []byte(newContent), 0644)
-		fmt.Printf("  ✓ Removed hook from %s\n", filepath.Base(path))
+	return strings.Join(result, "\n"), removed
+}
+
+// previewTimer reports the scheduled scan artifacts removeTimer would delete
+func previewTimer(home string) {
+	plistPath := filepath.Join(home, "Library", "LaunchAgents", "com.tabgen.scan.plist")
+	userDir := filepath.Join(home, ".config", "systemd", "user")
+	for _, path := range []string{
+		plistPath,
+		filepath.Join(userDir, "tabgen-scan.timer"),
+		filepath.Join(userDir, "tabgen-scan.service"),
+	} {
+		if _, err := os.Stat(path); err == nil {
+			fmt.Printf("  • Would remove %s\n", path)
+		}
+	}
+
+	output, err := exec.Command("crontab", "-l").Output()
+	if err != nil {
+		return
+	}
+	for line := range strings.SplitSeq(string(output), "\n") {
+		if strings.Contains(line, "# tabgen daily scan") {
+			fmt.Printf("  • Would remove cron job: %s\n", line)
+		}
 	}
 }
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SetBackups makes completion saves keep the previous n versions of each
+// script for 'tabgen rollback' (0 disables backups)
+func (s *Storage) SetBackups(n int) {
+	s.keepBackups = n
+}
+
+// writeCompletion writes a completion script, first rotating the script it
+// replaces into the backups when they are enabled
+func (s *Storage) writeCompletion(path, content string) error {
+	if s.keepBackups > 0 {
+		if err := s.rotateBackups(path, content); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(content), 0644)
+}
+
+// backupPath returns the path of the i'th newest backup of a completion
+// script. Backups live under backups/, mirroring completions/, so shells never
+// load them: <file>.bak is the newest, then <file>.bak.1, <file>.bak.2, ...
+func (s *Storage) backupPath(path string, i int) string {
+	rel, err := filepath.Rel(filepath.Join(s.baseDir, "completions"), path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	backup := filepath.Join(s.baseDir, "backups", rel) + ".bak"
+	if i > 0 {
+		backup += fmt.Sprintf(".%d", i)
+	}
+	return backup
+}
+
+// rotateBackups makes the script at path the newest backup, dropping the
+// oldest beyond the configured count. Nothing happens if there is no script
+// or it already holds content, so unchanged regenerations don't push out
+// older backups.
+func (s *Storage) rotateBackups(path, content string) error {
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if string(current) == content {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.backupPath(path, 0)), 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(s.backupPath(path, s.keepBackups-1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := s.keepBackups - 2; i >= 0; i-- {
+		if err := os.Rename(s.backupPath(path, i), s.backupPath(path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return os.WriteFile(s.backupPath(path, 0), current, 0644)
+}
+
+// RestoreCompletions replaces each of a tool's completion scripts that has a
+// backup with its newest backup, and returns the restored paths. Older
+// backups move up, so repeated calls step further back.
+func (s *Storage) RestoreCompletions(name string) ([]string, error) {
+	var restored []string
+	for _, path := range s.completionFiles(name) {
+		if _, err := os.Stat(s.backupPath(path, 0)); err != nil {
+			continue
+		}
+		if err := os.Rename(s.backupPath(path, 0), path); err != nil {
+			return restored, err
+		}
+		for i := 1; ; i++ {
+			if err := os.Rename(s.backupPath(path, i), s.backupPath(path, i-1)); err != nil {
+				if os.IsNotExist(err) {
+					break
+				}
+				return restored, err
+			}
+		}
+		restored = append(restored, path)
+	}
+	if len(restored) > 0 {
+		if err := s.RefreshBundles(); err != nil {
+			return restored, err
+		}
+		if err := s.WriteNushellIndex(); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
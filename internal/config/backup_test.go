@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestBackups_RotateAndKeepLimit(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	storage.SetBackups(2)
+
+	for _, content := range []string{"v1", "v2", "v3", "v4"} {
+		if err := storage.SaveBashCompletion("mytool", content); err != nil {
+			t.Fatalf("SaveBashCompletion failed: %v", err)
+		}
+	}
+
+	bashDir, _ := storage.CompletionPaths()
+	path := filepath.Join(bashDir, "mytool")
+	if got := readFile(t, storage.backupPath(path, 0)); got != "v3" {
+		t.Errorf("newest backup = %q, want v3", got)
+	}
+	if got := readFile(t, storage.backupPath(path, 1)); got != "v2" {
+		t.Errorf("older backup = %q, want v2", got)
+	}
+	if _, err := os.Stat(storage.backupPath(path, 2)); !os.IsNotExist(err) {
+		t.Error("expected only 2 backups to be kept")
+	}
+}
+
+func TestBackups_SkipUnchanged(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	storage.SetBackups(2)
+
+	for _, content := range []string{"v1", "v2", "v2"} {
+		if err := storage.SaveZshCompletion("mytool", content); err != nil {
+			t.Fatalf("SaveZshCompletion failed: %v", err)
+		}
+	}
+
+	_, zshDir := storage.CompletionPaths()
+	path := filepath.Join(zshDir, "_mytool")
+	if got := readFile(t, storage.backupPath(path, 0)); got != "v1" {
+		t.Errorf("newest backup = %q, want v1", got)
+	}
+	if _, err := os.Stat(storage.backupPath(path, 1)); !os.IsNotExist(err) {
+		t.Error("unchanged save should not create a backup")
+	}
+}
+
+func TestBackups_Disabled(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, content := range []string{"v1", "v2"} {
+		if err := storage.SaveBashCompletion("mytool", content); err != nil {
+			t.Fatalf("SaveBashCompletion failed: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(storage.BaseDir(), "backups")); !os.IsNotExist(err) {
+		t.Error("expected no backups directory when backups are disabled")
+	}
+}
+
+func TestRestoreCompletions(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	storage.SetBackups(3)
+
+	for _, content := range []string{"v1", "v2", "v3"} {
+		if err := storage.SaveBashCompletion("mytool", content); err != nil {
+			t.Fatalf("SaveBashCompletion failed: %v", err)
+		}
+	}
+
+	bashDir, _ := storage.CompletionPaths()
+	path := filepath.Join(bashDir, "mytool")
+	for _, want := range []string{"v2", "v1"} {
+		restored, err := storage.RestoreCompletions("mytool")
+		if err != nil {
+			t.Fatalf("RestoreCompletions failed: %v", err)
+		}
+		if len(restored) != 1 || restored[0] != path {
+			t.Fatalf("restored = %v, want [%s]", restored, path)
+		}
+		if got := readFile(t, path); got != want {
+			t.Errorf("after rollback script = %q, want %q", got, want)
+		}
+	}
+
+	restored, err := storage.RestoreCompletions("mytool")
+	if err != nil {
+		t.Fatalf("RestoreCompletions failed: %v", err)
+	}
+	if len(restored) != 0 {
+		t.Errorf("expected nothing left to restore, got %v", restored)
+	}
+}
+
+func TestRemoveCompletions_BacksUp(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	storage.SetBackups(1)
+
+	if err := storage.SaveBashCompletion("mytool", "v1"); err != nil {
+		t.Fatalf("SaveBashCompletion failed: %v", err)
+	}
+	if err := storage.RemoveCompletions("mytool"); err != nil {
+		t.Fatalf("RemoveCompletions failed: %v", err)
+	}
+
+	bashDir, _ := storage.CompletionPaths()
+	path := filepath.Join(bashDir, "mytool")
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected script to be removed")
+	}
+	if _, err := storage.RestoreCompletions("mytool"); err != nil {
+		t.Fatalf("RestoreCompletions failed: %v", err)
+	}
+	if got := readFile(t, path); got != "v1" {
+		t.Errorf("restored script = %q, want v1", got)
+	}
+}
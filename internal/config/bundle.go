@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BashBundlePath returns the path to the file holding every bash completion
+// script, sourced by the bash hook instead of the per-tool files when present
+func (s *Storage) BashBundlePath() string {
+	return filepath.Join(s.baseDir, "completions", "tabgen.bash")
+}
+
+// ZshBundlePath returns the path to the file defining every zsh completion
+// function, sourced by the zsh hook after compinit when present
+func (s *Storage) ZshBundlePath() string {
+	return filepath.Join(s.baseDir, "completions", "tabgen.zsh")
+}
+
+// WriteBundles rebuilds the bash and zsh bundles from the per-tool scripts
+// currently in the completion directories
+func (s *Storage) WriteBundles() error {
+	bashDir, zshDir := s.CompletionPaths()
+	bash, err := bashBundle(bashDir)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.BashBundlePath(), []byte(bash), 0644); err != nil {
+		return err
+	}
+	zsh, err := zshBundle(zshDir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.ZshBundlePath(), []byte(zsh), 0644)
+}
+
+// RefreshBundles rebuilds the bundles if either exists, so they never go
+// stale once 'tabgen generate --bundle' has created them
+func (s *Storage) RefreshBundles() error {
+	for _, path := range []string{s.BashBundlePath(), s.ZshBundlePath()} {
+		if _, err := os.Stat(path); err == nil {
+			return s.WriteBundles()
+		}
+	}
+	return nil
+}
+
+// bashBundle concatenates the bash scripts in dir
+func bashBundle(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by TabGen: every bash completion script in one file\n")
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("\n")
+		sb.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// zshBundle turns the autoloadable "#compdef" files in dir into function
+// definitions registered with compdef. Each file's body becomes the body of a
+// function named after the file, which is what autoload would have done.
+func zshBundle(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by TabGen: every zsh completion function in one file\n")
+	sb.WriteString("# Source it after compinit\n")
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), "_") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return "", err
+		}
+		first, body, _ := strings.Cut(string(data), "\n")
+		commands, ok := strings.CutPrefix(first, "#compdef ")
+		if !ok || strings.TrimSpace(commands) == "" {
+			continue
+		}
+		sb.WriteString("\n" + e.Name() + "() {\n")
+		sb.WriteString(body)
+		if body != "" && !strings.HasSuffix(body, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+		sb.WriteString("compdef " + e.Name() + " " + strings.TrimSpace(commands) + "\n")
+	}
+	return sb.String(), nil
+}
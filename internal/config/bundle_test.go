@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteBundles(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for _, name := range []string{"alpha", "beta"} {
+		if err := storage.SaveBashCompletion(name, "complete -F _tabgen_"+name+" "+name+"\n"); err != nil {
+			t.Fatalf("SaveBashCompletion failed: %v", err)
+		}
+		if err := storage.SaveZshCompletion(name, "#compdef "+name+"\n_"+name+"() {\n}\n_"+name+" \"$@\"\n"); err != nil {
+			t.Fatalf("SaveZshCompletion failed: %v", err)
+		}
+	}
+
+	if err := storage.WriteBundles(); err != nil {
+		t.Fatalf("WriteBundles failed: %v", err)
+	}
+
+	bash := readFile(t, storage.BashBundlePath())
+	for _, want := range []string{"complete -F _tabgen_alpha alpha\n", "complete -F _tabgen_beta beta\n"} {
+		if !strings.Contains(bash, want) {
+			t.Errorf("bash bundle missing %q:\n%s", want, bash)
+		}
+	}
+
+	zsh := readFile(t, storage.ZshBundlePath())
+	want := "\n_alpha() {\n_alpha() {\n}\n_alpha \"$@\"\n}\ncompdef _alpha alpha\n"
+	if !strings.Contains(zsh, want) {
+		t.Errorf("zsh bundle missing %q:\n%s", want, zsh)
+	}
+	if strings.Contains(zsh, "#compdef") {
+		t.Errorf("zsh bundle should not keep #compdef lines:\n%s", zsh)
+	}
+}
+
+func TestRefreshBundles(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := storage.SaveBashCompletion("alpha", "# alpha\n"); err != nil {
+		t.Fatalf("SaveBashCompletion failed: %v", err)
+	}
+
+	// Without a bundle there is nothing to refresh
+	if err := storage.RefreshBundles(); err != nil {
+		t.Fatalf("RefreshBundles failed: %v", err)
+	}
+	if _, err := os.Stat(storage.BashBundlePath()); !os.IsNotExist(err) {
+		t.Fatal("expected no bundle before WriteBundles")
+	}
+
+	if err := storage.WriteBundles(); err != nil {
+		t.Fatalf("WriteBundles failed: %v", err)
+	}
+	if err := storage.RemoveCompletions("alpha"); err != nil {
+		t.Fatalf("RemoveCompletions failed: %v", err)
+	}
+	if bash := readFile(t, storage.BashBundlePath()); strings.Contains(bash, "# alpha") {
+		t.Errorf("expected removed tool to leave the bundle:\n%s", bash)
+	}
+}
@@ -1,27 +1,33 @@
 package config
 
 import (
+	"compress/gzip"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/jvalentini/tabgen/internal/types"
 )
 
 // Storage handles reading and writing TabGen data files
 type Storage struct {
-	baseDir string
+	baseDir       string
+	compressTools bool // Write tools/<name>.json.gz instead of .json
+	keepBackups   int  // Previous completion scripts kept per file under backups/ (0 = none)
 }
 
+// DataDir is the base directory commands pass to New ("" means ~/.tabgen).
+// It starts as $TABGEN_DATA_DIR, and main overrides it with --data-dir.
+var DataDir = os.Getenv("TABGEN_DATA_DIR")
+
 // New creates a new Storage instance
 func New(baseDir string) (*Storage, error) {
-	// Expand ~ to home directory
-	if baseDir == "" || baseDir == "~/.tabgen" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-		baseDir = filepath.Join(home, ".tabgen")
+	baseDir, err := expandBaseDir(baseDir)
+	if err != nil {
+		return nil, err
 	}
 
 	// Ensure directories exist
@@ -30,6 +36,10 @@ func New(baseDir string) (*Storage, error) {
 		filepath.Join(baseDir, "tools"),
 		filepath.Join(baseDir, "completions", "bash"),
 		filepath.Join(baseDir, "completions", "zsh"),
+		filepath.Join(baseDir, "completions", "elvish"),
+		filepath.Join(baseDir, "completions", "tcsh"),
+		filepath.Join(baseDir, "completions", "xonsh"),
+		filepath.Join(baseDir, "completions", "nushell"),
 	}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
@@ -40,6 +50,31 @@ func New(baseDir string) (*Storage, error) {
 	return &Storage{baseDir: baseDir}, nil
 }
 
+// Open returns a Storage for an existing data directory without creating
+// anything in it, for reading another user's data as root
+func Open(baseDir string) (*Storage, error) {
+	baseDir, err := expandBaseDir(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(baseDir); err != nil {
+		return nil, err
+	}
+	return &Storage{baseDir: baseDir}, nil
+}
+
+// expandBaseDir resolves "" and "~/.tabgen" to the home directory's .tabgen
+func expandBaseDir(baseDir string) (string, error) {
+	if baseDir == "" || baseDir == "~/.tabgen" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		baseDir = filepath.Join(home, ".tabgen")
+	}
+	return baseDir, nil
+}
+
 // BaseDir returns the base directory path
 func (s *Storage) BaseDir() string {
 	return s.baseDir
@@ -76,10 +111,26 @@ func (s *Storage) SaveCatalog(catalog *types.Catalog) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-// LoadTool loads a parsed tool from disk
-func (s *Storage) LoadTool(name string) (*types.Tool, error) {
+// SetCompression controls whether SaveTool gzips tool JSON
+func (s *Storage) SetCompression(enabled bool) {
+	s.compressTools = enabled
+}
+
+// toolPath returns the path of a tool's JSON file, compressed or not
+func (s *Storage) toolPath(name string, compressed bool) string {
 	path := filepath.Join(s.baseDir, "tools", name+".json")
-	data, err := os.ReadFile(path)
+	if compressed {
+		path += ".gz"
+	}
+	return path
+}
+
+// LoadTool loads a parsed tool from disk, reading either .json or .json.gz
+func (s *Storage) LoadTool(name string) (*types.Tool, error) {
+	data, err := os.ReadFile(s.toolPath(name, false))
+	if os.IsNotExist(err) {
+		data, err = readGzip(s.toolPath(name, true))
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -93,31 +144,231 @@ func (s *Storage) LoadTool(name string) (*types.Tool, error) {
 
 // SaveTool saves a parsed tool to disk
 func (s *Storage) SaveTool(tool *types.Tool) error {
-	path := filepath.Join(s.baseDir, "tools", tool.Name+".json")
 	data, err := json.MarshalIndent(tool, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+
+	path := s.toolPath(tool.Name, s.compressTools)
+	if s.compressTools {
+		err = writeGzip(path, data)
+	} else {
+		err = os.WriteFile(path, data, 0644)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Remove the copy in the other format so LoadTool never reads stale data
+	stale := s.toolPath(tool.Name, !s.compressTools)
+	if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
 }
 
 // ToolExists checks if a tool has been parsed
 func (s *Storage) ToolExists(name string) bool {
-	path := filepath.Join(s.baseDir, "tools", name+".json")
-	_, err := os.Stat(path)
-	return err == nil
+	return s.ToolFile(name) != ""
+}
+
+// ToolFile returns the path of a tool's stored JSON, .json or .json.gz,
+// whichever exists, or "" if neither does
+func (s *Storage) ToolFile(name string) string {
+	for _, compressed := range []bool{false, true} {
+		path := s.toolPath(name, compressed)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// RemoveTool deletes a tool's stored JSON in either format
+func (s *Storage) RemoveTool(name string) error {
+	for _, compressed := range []bool{false, true} {
+		if err := os.Remove(s.toolPath(name, compressed)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTool removes everything stored for a tool: its JSON in either format,
+// its saved raw output, and its completion scripts for every shell. With
+// backups enabled, the scripts are kept as backups (see RemoveCompletions).
+func (s *Storage) DeleteTool(name string) error {
+	if err := s.RemoveTool(name); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.RawPath(), name+".txt")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return s.RemoveCompletions(name)
+}
+
+// ListTools returns the names of tools with stored JSON in either format
+func (s *Storage) ListTools() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.baseDir, "tools"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(strings.TrimSuffix(entry.Name(), ".gz"), ".json")
+		if ok && !entry.IsDir() && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// readGzip reads and decompresses a gzip file
+func readGzip(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+// writeGzip compresses data into a gzip file
+func writeGzip(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// RawPath returns the directory holding raw help output (see parser.ParserConfig.RawDir)
+func (s *Storage) RawPath() string {
+	return filepath.Join(s.baseDir, "raw")
+}
+
+// ListRaw returns the names of tools with saved raw help output
+func (s *Storage) ListRaw() ([]string, error) {
+	entries, err := os.ReadDir(s.RawPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".txt"); ok && !entry.IsDir() {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// LoadRaw loads a tool's saved raw help output
+func (s *Storage) LoadRaw(name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(s.RawPath(), name+".txt"))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
 }
 
 // SaveBashCompletion saves a bash completion script
 func (s *Storage) SaveBashCompletion(name, content string) error {
 	path := filepath.Join(s.baseDir, "completions", "bash", name)
-	return os.WriteFile(path, []byte(content), 0644)
+	return s.writeCompletion(path, content)
 }
 
 // SaveZshCompletion saves a zsh completion script
 func (s *Storage) SaveZshCompletion(name, content string) error {
 	path := filepath.Join(s.baseDir, "completions", "zsh", "_"+name)
-	return os.WriteFile(path, []byte(content), 0644)
+	return s.writeCompletion(path, content)
+}
+
+// SaveElvishCompletion saves an elvish completion script
+func (s *Storage) SaveElvishCompletion(name, content string) error {
+	path := filepath.Join(s.ElvishCompletionPath(), name+".elv")
+	return s.writeCompletion(path, content)
+}
+
+// SaveTcshCompletion saves a tcsh completion script
+func (s *Storage) SaveTcshCompletion(name, content string) error {
+	path := filepath.Join(s.TcshCompletionPath(), name)
+	return s.writeCompletion(path, content)
+}
+
+// SaveXonshCompletion saves a xonsh completion script
+func (s *Storage) SaveXonshCompletion(name, content string) error {
+	path := filepath.Join(s.XonshCompletionPath(), name+".xsh")
+	return s.writeCompletion(path, content)
+}
+
+// SaveNushellCompletion saves a nushell completion script and adds it to the
+// index sourced from config.nu
+func (s *Storage) SaveNushellCompletion(name, content string) error {
+	path := filepath.Join(s.NushellCompletionPath(), name+".nu")
+	if err := s.writeCompletion(path, content); err != nil {
+		return err
+	}
+	return s.WriteNushellIndex()
+}
+
+// RemoveCompletions deletes a tool's bash, zsh, elvish, tcsh, xonsh, and
+// nushell completion scripts. With backups enabled, each is kept as the
+// newest backup so 'tabgen rollback' can bring it back.
+func (s *Storage) RemoveCompletions(name string) error {
+	for _, path := range s.completionFiles(name) {
+		if s.keepBackups > 0 {
+			if err := s.rotateBackups(path, ""); err != nil {
+				return err
+			}
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := s.RefreshBundles(); err != nil {
+		return err
+	}
+	return s.WriteNushellIndex()
+}
+
+// completionFiles returns the paths of a tool's completion scripts for every shell
+func (s *Storage) completionFiles(name string) []string {
+	bashDir, zshDir := s.CompletionPaths()
+	return []string{
+		filepath.Join(bashDir, name),
+		filepath.Join(zshDir, "_"+name),
+		filepath.Join(s.ElvishCompletionPath(), name+".elv"),
+		filepath.Join(s.TcshCompletionPath(), name),
+		filepath.Join(s.XonshCompletionPath(), name+".xsh"),
+		filepath.Join(s.NushellCompletionPath(), name+".nu"),
+	}
 }
 
 // CompletionPaths returns the paths to completion directories
@@ -126,6 +377,62 @@ func (s *Storage) CompletionPaths() (bash, zsh string) {
 		filepath.Join(s.baseDir, "completions", "zsh")
 }
 
+// ElvishCompletionPath returns the path to the elvish completion directory
+func (s *Storage) ElvishCompletionPath() string {
+	return filepath.Join(s.baseDir, "completions", "elvish")
+}
+
+// TcshCompletionPath returns the path to the tcsh completion directory
+func (s *Storage) TcshCompletionPath() string {
+	return filepath.Join(s.baseDir, "completions", "tcsh")
+}
+
+// XonshCompletionPath returns the path to the xonsh completion directory
+func (s *Storage) XonshCompletionPath() string {
+	return filepath.Join(s.baseDir, "completions", "xonsh")
+}
+
+// NushellCompletionPath returns the path to the nushell completion directory
+func (s *Storage) NushellCompletionPath() string {
+	return filepath.Join(s.baseDir, "completions", "nushell")
+}
+
+// NushellIndexPath returns the path to the file that sources every nushell
+// completion script
+func (s *Storage) NushellIndexPath() string {
+	return filepath.Join(s.baseDir, "completions", "nushell.nu")
+}
+
+// WriteNushellIndex rewrites the nushell index to source the scripts
+// currently in the nushell completion directory
+func (s *Storage) WriteNushellIndex() error {
+	return writeNushellIndex(s.NushellCompletionPath(), s.NushellIndexPath())
+}
+
+// nushellIndexMu serializes index rewrites so concurrent saves can't leave a
+// stale listing behind
+var nushellIndexMu sync.Mutex
+
+// writeNushellIndex writes indexPath with a source line for each .nu script in
+// dir. Nushell's source only takes constant paths, so config.nu can't glob
+// the directory itself.
+func writeNushellIndex(dir, indexPath string) error {
+	nushellIndexMu.Lock()
+	defer nushellIndexMu.Unlock()
+
+	scripts, err := filepath.Glob(filepath.Join(dir, "*.nu"))
+	if err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("# Generated by TabGen\n")
+	for _, script := range scripts {
+		sb.WriteString("source \"" + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(script) + "\"\n")
+	}
+	return os.WriteFile(indexPath, []byte(sb.String()), 0644)
+}
+
 // LoadConfig loads the configuration
 func (s *Storage) LoadConfig() (*types.Config, error) {
 	path := filepath.Join(s.baseDir, "config.json")
@@ -154,3 +461,95 @@ func (s *Storage) SaveConfig(cfg *types.Config) error {
 	}
 	return os.WriteFile(path, data, 0644)
 }
+
+// CompletionWriter saves generated completion scripts
+type CompletionWriter interface {
+	SaveBashCompletion(name, content string) error
+	SaveZshCompletion(name, content string) error
+	SaveElvishCompletion(name, content string) error
+	SaveTcshCompletion(name, content string) error
+	SaveXonshCompletion(name, content string) error
+	SaveNushellCompletion(name, content string) error
+	CompletionPaths() (bash, zsh string)
+	ElvishCompletionPath() string
+	TcshCompletionPath() string
+	XonshCompletionPath() string
+	NushellCompletionPath() string
+}
+
+// OutputDir writes completion scripts to DIR/bash, DIR/zsh, DIR/elvish, DIR/tcsh, DIR/xonsh, and DIR/nushell, outside the data directory
+type OutputDir struct {
+	dir string
+}
+
+// NewOutputDir creates an OutputDir, ensuring its per-shell directories exist
+func NewOutputDir(dir string) (*OutputDir, error) {
+	o := &OutputDir{dir: dir}
+	bashDir, zshDir := o.CompletionPaths()
+	for _, d := range []string{bashDir, zshDir, o.ElvishCompletionPath(), o.TcshCompletionPath(), o.XonshCompletionPath(), o.NushellCompletionPath()} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return o, nil
+}
+
+// SaveBashCompletion saves a bash completion script
+func (o *OutputDir) SaveBashCompletion(name, content string) error {
+	bashDir, _ := o.CompletionPaths()
+	return os.WriteFile(filepath.Join(bashDir, name), []byte(content), 0644)
+}
+
+// SaveZshCompletion saves a zsh completion script
+func (o *OutputDir) SaveZshCompletion(name, content string) error {
+	_, zshDir := o.CompletionPaths()
+	return os.WriteFile(filepath.Join(zshDir, "_"+name), []byte(content), 0644)
+}
+
+// CompletionPaths returns the paths to completion directories
+func (o *OutputDir) CompletionPaths() (bash, zsh string) {
+	return filepath.Join(o.dir, "bash"), filepath.Join(o.dir, "zsh")
+}
+
+// SaveElvishCompletion saves an elvish completion script
+func (o *OutputDir) SaveElvishCompletion(name, content string) error {
+	return os.WriteFile(filepath.Join(o.ElvishCompletionPath(), name+".elv"), []byte(content), 0644)
+}
+
+// ElvishCompletionPath returns the path to the elvish completion directory
+func (o *OutputDir) ElvishCompletionPath() string {
+	return filepath.Join(o.dir, "elvish")
+}
+
+// SaveTcshCompletion saves a tcsh completion script
+func (o *OutputDir) SaveTcshCompletion(name, content string) error {
+	return os.WriteFile(filepath.Join(o.TcshCompletionPath(), name), []byte(content), 0644)
+}
+
+// TcshCompletionPath returns the path to the tcsh completion directory
+func (o *OutputDir) TcshCompletionPath() string {
+	return filepath.Join(o.dir, "tcsh")
+}
+
+// SaveXonshCompletion saves a xonsh completion script
+func (o *OutputDir) SaveXonshCompletion(name, content string) error {
+	return os.WriteFile(filepath.Join(o.XonshCompletionPath(), name+".xsh"), []byte(content), 0644)
+}
+
+// XonshCompletionPath returns the path to the xonsh completion directory
+func (o *OutputDir) XonshCompletionPath() string {
+	return filepath.Join(o.dir, "xonsh")
+}
+
+// SaveNushellCompletion saves a nushell completion script and adds it to DIR/nushell.nu
+func (o *OutputDir) SaveNushellCompletion(name, content string) error {
+	if err := os.WriteFile(filepath.Join(o.NushellCompletionPath(), name+".nu"), []byte(content), 0644); err != nil {
+		return err
+	}
+	return writeNushellIndex(o.NushellCompletionPath(), filepath.Join(o.dir, "nushell.nu"))
+}
+
+// NushellCompletionPath returns the path to the nushell completion directory
+func (o *OutputDir) NushellCompletionPath() string {
+	return filepath.Join(o.dir, "nushell")
+}
@@ -0,0 +1,378 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestSaveTool_Compressed(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	tool := &types.Tool{
+		Name:        "mytool",
+		GlobalFlags: []types.Flag{{Name: "--verbose"}},
+	}
+
+	// Plain JSON first, then switch to gzip
+	if err := storage.SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool failed: %v", err)
+	}
+	storage.SetCompression(true)
+	if err := storage.SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool (compressed) failed: %v", err)
+	}
+
+	toolsDir := filepath.Join(storage.BaseDir(), "tools")
+	if _, err := os.Stat(filepath.Join(toolsDir, "mytool.json.gz")); err != nil {
+		t.Errorf("expected mytool.json.gz: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(toolsDir, "mytool.json")); !os.IsNotExist(err) {
+		t.Error("expected stale mytool.json to be removed")
+	}
+
+	if !storage.ToolExists("mytool") {
+		t.Error("ToolExists should find compressed tool")
+	}
+	if got := storage.ToolFile("mytool"); got != filepath.Join(toolsDir, "mytool.json.gz") {
+		t.Errorf("ToolFile = %q, want the compressed file", got)
+	}
+
+	loaded, err := storage.LoadTool("mytool")
+	if err != nil {
+		t.Fatalf("LoadTool failed: %v", err)
+	}
+	if len(loaded.GlobalFlags) != 1 || loaded.GlobalFlags[0].Name != "--verbose" {
+		t.Errorf("unexpected flags after round trip: %+v", loaded.GlobalFlags)
+	}
+}
+
+func TestLoadTool_Missing(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if _, err := storage.LoadTool("nope"); !os.IsNotExist(err) {
+		t.Errorf("expected not-exist error, got %v", err)
+	}
+	if storage.ToolExists("nope") {
+		t.Error("ToolExists should be false for missing tool")
+	}
+	if got := storage.ToolFile("nope"); got != "" {
+		t.Errorf("ToolFile = %q for missing tool, want empty", got)
+	}
+}
+
+func TestRemoveToolAndCompletions(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := storage.SaveTool(&types.Tool{Name: "mytool"}); err != nil {
+		t.Fatalf("SaveTool failed: %v", err)
+	}
+	if err := storage.SaveBashCompletion("mytool", "# bash"); err != nil {
+		t.Fatalf("SaveBashCompletion failed: %v", err)
+	}
+	if err := storage.SaveZshCompletion("mytool", "# zsh"); err != nil {
+		t.Fatalf("SaveZshCompletion failed: %v", err)
+	}
+
+	if err := storage.RemoveTool("mytool"); err != nil {
+		t.Fatalf("RemoveTool failed: %v", err)
+	}
+	if err := storage.RemoveCompletions("mytool"); err != nil {
+		t.Fatalf("RemoveCompletions failed: %v", err)
+	}
+
+	if storage.ToolExists("mytool") {
+		t.Error("tool JSON should be removed")
+	}
+	bashDir, zshDir := storage.CompletionPaths()
+	for _, path := range []string{filepath.Join(bashDir, "mytool"), filepath.Join(zshDir, "_mytool")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed", path)
+		}
+	}
+
+	// Removing again is not an error
+	if err := storage.RemoveCompletions("mytool"); err != nil {
+		t.Errorf("RemoveCompletions on missing files failed: %v", err)
+	}
+}
+
+func TestOutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	out, err := NewOutputDir(dir)
+	if err != nil {
+		t.Fatalf("NewOutputDir failed: %v", err)
+	}
+
+	if err := out.SaveBashCompletion("mytool", "bash script"); err != nil {
+		t.Fatalf("SaveBashCompletion failed: %v", err)
+	}
+	if err := out.SaveZshCompletion("mytool", "zsh script"); err != nil {
+		t.Fatalf("SaveZshCompletion failed: %v", err)
+	}
+	if err := out.SaveTcshCompletion("mytool", "tcsh script"); err != nil {
+		t.Fatalf("SaveTcshCompletion failed: %v", err)
+	}
+	if err := out.SaveXonshCompletion("mytool", "xonsh script"); err != nil {
+		t.Fatalf("SaveXonshCompletion failed: %v", err)
+	}
+	if err := out.SaveNushellCompletion("mytool", "nushell script"); err != nil {
+		t.Fatalf("SaveNushellCompletion failed: %v", err)
+	}
+
+	for path, want := range map[string]string{
+		filepath.Join(dir, "bash", "mytool"):       "bash script",
+		filepath.Join(dir, "zsh", "_mytool"):       "zsh script",
+		filepath.Join(dir, "tcsh", "mytool"):       "tcsh script",
+		filepath.Join(dir, "xonsh", "mytool.xsh"):  "xonsh script",
+		filepath.Join(dir, "nushell", "mytool.nu"): "nushell script",
+		filepath.Join(dir, "nushell.nu"):           "# Generated by TabGen\nsource \"" + filepath.Join(dir, "nushell", "mytool.nu") + "\"\n",
+	} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("expected %s: %v", path, err)
+		}
+		if string(data) != want {
+			t.Errorf("%s = %q, want %q", path, data, want)
+		}
+	}
+}
+
+func TestListRaw(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// No raw directory yet
+	names, err := storage.ListRaw()
+	if err != nil || len(names) != 0 {
+		t.Fatalf("ListRaw() = %v, %v; want empty", names, err)
+	}
+
+	if err := os.MkdirAll(storage.RawPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storage.RawPath(), "mytool.txt"), []byte("help"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err = storage.ListRaw()
+	if err != nil || len(names) != 1 || names[0] != "mytool" {
+		t.Fatalf("ListRaw() = %v, %v; want [mytool]", names, err)
+	}
+	text, err := storage.LoadRaw("mytool")
+	if err != nil || text != "help" {
+		t.Errorf("LoadRaw() = %q, %v; want help", text, err)
+	}
+}
+
+func TestNushellIndex(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, name := range []string{"git", "docker"} {
+		if err := storage.SaveNushellCompletion(name, "# "+name); err != nil {
+			t.Fatalf("SaveNushellCompletion failed: %v", err)
+		}
+	}
+
+	readIndex := func() string {
+		data, err := os.ReadFile(storage.NushellIndexPath())
+		if err != nil {
+			t.Fatalf("reading index: %v", err)
+		}
+		return string(data)
+	}
+
+	dir := storage.NushellCompletionPath()
+	want := "# Generated by TabGen\n" +
+		"source \"" + filepath.Join(dir, "docker.nu") + "\"\n" +
+		"source \"" + filepath.Join(dir, "git.nu") + "\"\n"
+	if got := readIndex(); got != want {
+		t.Errorf("index = %q, want %q", got, want)
+	}
+
+	if err := storage.RemoveCompletions("git"); err != nil {
+		t.Fatalf("RemoveCompletions failed: %v", err)
+	}
+	if got := readIndex(); strings.Contains(got, "git.nu") {
+		t.Errorf("index still sources removed script: %q", got)
+	}
+}
+
+func TestListTools(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := storage.SaveTool(&types.Tool{Name: "git"}); err != nil {
+		t.Fatalf("SaveTool failed: %v", err)
+	}
+	storage.SetCompression(true)
+	if err := storage.SaveTool(&types.Tool{Name: "docker"}); err != nil {
+		t.Fatalf("SaveTool failed: %v", err)
+	}
+	// A leftover copy in the other format and unrelated files are ignored
+	for _, file := range []string{"git.json.gz", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(storage.BaseDir(), "tools", file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names, err := storage.ListTools()
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "docker" || names[1] != "git" {
+		t.Errorf("ListTools() = %v, want [docker git]", names)
+	}
+}
+
+func TestDeleteTool(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	if err := storage.SaveTool(&types.Tool{Name: "mytool"}); err != nil {
+		t.Fatalf("SaveTool failed: %v", err)
+	}
+	if err := storage.SaveBashCompletion("mytool", "bash"); err != nil {
+		t.Fatalf("SaveBashCompletion failed: %v", err)
+	}
+	if err := storage.SaveZshCompletion("mytool", "zsh"); err != nil {
+		t.Fatalf("SaveZshCompletion failed: %v", err)
+	}
+	if err := os.MkdirAll(storage.RawPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	rawPath := filepath.Join(storage.RawPath(), "mytool.txt")
+	if err := os.WriteFile(rawPath, []byte("help"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := storage.DeleteTool("mytool"); err != nil {
+		t.Fatalf("DeleteTool failed: %v", err)
+	}
+	if storage.ToolExists("mytool") {
+		t.Error("expected tool JSON to be deleted")
+	}
+	bashDir, zshDir := storage.CompletionPaths()
+	for _, path := range []string{rawPath, filepath.Join(bashDir, "mytool"), filepath.Join(zshDir, "_mytool")} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be deleted", path)
+		}
+	}
+
+	// Nothing left to delete is not an error
+	if err := storage.DeleteTool("mytool"); err != nil {
+		t.Errorf("DeleteTool on a missing tool failed: %v", err)
+	}
+}
+
+func TestNew_SuppliedDataDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dataDir := filepath.Join(t.TempDir(), "instance")
+
+	storage, err := New(dataDir)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if storage.BaseDir() != dataDir {
+		t.Errorf("BaseDir() = %s, want %s", storage.BaseDir(), dataDir)
+	}
+
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{"git": {Name: "git"}}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+	cfg := types.DefaultConfig()
+	if err := storage.SaveConfig(&cfg); err != nil {
+		t.Fatalf("SaveConfig failed: %v", err)
+	}
+	if err := storage.SaveBashCompletion("git", "bash"); err != nil {
+		t.Fatalf("SaveBashCompletion failed: %v", err)
+	}
+
+	for _, rel := range []string{"catalog.json", "config.json", filepath.Join("completions", "bash", "git")} {
+		if _, err := os.Stat(filepath.Join(dataDir, rel)); err != nil {
+			t.Errorf("expected %s in the supplied directory: %v", rel, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(home, ".tabgen")); !os.IsNotExist(err) {
+		t.Error("expected nothing written to ~/.tabgen")
+	}
+}
+
+func TestCatalog_GeneratedAt(t *testing.T) {
+	storage, err := New(t.TempDir())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	generatedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	catalog := &types.Catalog{Tools: map[string]types.CatalogEntry{
+		"git": {Name: "git", Generated: true, GeneratedAt: generatedAt},
+		"jq":  {Name: "jq"},
+	}}
+	if err := storage.SaveCatalog(catalog); err != nil {
+		t.Fatalf("SaveCatalog failed: %v", err)
+	}
+
+	loaded, err := storage.LoadCatalog()
+	if err != nil {
+		t.Fatalf("LoadCatalog failed: %v", err)
+	}
+	if got := loaded.Tools["git"].GeneratedAt; !got.Equal(generatedAt) {
+		t.Errorf("GeneratedAt = %v, want %v", got, generatedAt)
+	}
+	if got := loaded.Tools["jq"].GeneratedAt; !got.IsZero() {
+		t.Errorf("expected no timestamp for an ungenerated tool, got %v", got)
+	}
+
+	data, err := os.ReadFile(filepath.Join(storage.BaseDir(), "catalog.json"))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Count(string(data), `"generated_at"`) != 1 {
+		t.Errorf("expected generated_at only on the generated tool:\n%s", data)
+	}
+}
+
+func TestOpen_CreatesNothing(t *testing.T) {
+	if _, err := Open(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error opening a missing data directory")
+	}
+
+	dir := t.TempDir()
+	storage, err := Open(dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if bash, _ := storage.CompletionPaths(); bash != filepath.Join(dir, "completions", "bash") {
+		t.Errorf("unexpected bash completion path %s", bash)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected Open to leave the directory empty, found %d entries", len(entries))
+	}
+}
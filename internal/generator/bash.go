@@ -2,19 +2,28 @@ package generator
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/jvalentini/tabgen/internal/types"
 )
 
 // Bash generates bash completion scripts
-type Bash struct{}
+type Bash struct {
+	dynamic map[string]string // "tool:kind" -> candidate command; nil disables dynamic completion
+}
 
 // NewBash creates a new Bash generator
 func NewBash() *Bash {
 	return &Bash{}
 }
 
+// SetDynamicCompletions enables dynamic value completion using the given
+// "tool:kind" -> shell command table (see DefaultDynamicCompletions)
+func (b *Bash) SetDynamicCompletions(dynamic map[string]string) {
+	b.dynamic = dynamic
+}
+
 // GenerateWithLimits creates a bash completion script with bounds checking
 func (b *Bash) GenerateWithLimits(tool *types.Tool) GenerateResult {
 	// Apply truncation if needed
@@ -35,28 +44,23 @@ func (b *Bash) GenerateWithLimits(tool *types.Tool) GenerateResult {
 
 // Generate creates a bash completion script for a tool
 func (b *Bash) Generate(tool *types.Tool) string {
+	tool = prepareTool(tool)
 	var sb strings.Builder
 
 	funcName := bashFuncName(tool.Name)
 
-	fmt.Fprintf(&sb, "# Bash completion for %s\n", tool.Name)
-	sb.WriteString("# Generated by TabGen\n\n")
+	writeHeader(&sb, "Bash", tool)
+	sb.WriteString("\n")
 
 	fmt.Fprintf(&sb, "%s() {\n", funcName)
 	sb.WriteString("    local cur prev words cword\n")
-	sb.WriteString("    _init_completion || return\n\n")
-
-	// Build list of subcommands (including aliases)
-	if len(tool.Subcommands) > 0 {
-		var cmds []string
-		for _, cmd := range tool.Subcommands {
-			cmds = append(cmds, escapeShellString(cmd.Name))
-			for _, alias := range cmd.Aliases {
-				cmds = append(cmds, escapeShellString(alias))
-			}
-		}
-		fmt.Fprintf(&sb, "    local commands=\"%s\"\n", strings.Join(cmds, " "))
+	if hasLongFlagValues(tool) {
+		// Keep "--flag=value" in one word so the value can be completed after "="
+		sb.WriteString("    _init_completion -n = || return\n\n")
+	} else {
+		sb.WriteString("    _init_completion || return\n\n")
 	}
+	writeBashPassthroughGuard(&sb)
 
 	// Build list of global flags
 	if len(tool.GlobalFlags) > 0 {
@@ -64,40 +68,23 @@ func (b *Bash) Generate(tool *types.Tool) string {
 		fmt.Fprintf(&sb, "    local flags=\"%s\"\n", strings.Join(flags, " "))
 	}
 
-	// Generate flag argument value completions
-	b.generateFlagValueCompletions(&sb, tool.GlobalFlags, tool.Subcommands)
+	// Walk the subcommand tree to the deepest command named, whose flags'
+	// values are completed along with the global ones
+	b.generateDynamicCompletions(&sb, tool)
+	if len(tool.Subcommands) > 0 {
+		sb.WriteString("\n")
+		b.generateDispatcher(&sb, tool)
+	}
+	b.generateFlagValueCompletions(&sb, tool)
+	b.generateInlineValueCompletions(&sb, tool)
 
 	sb.WriteString("\n")
 
-	// Handle subcommand-specific completions
 	if len(tool.Subcommands) > 0 {
-		sb.WriteString("    # Find the current subcommand\n")
-		sb.WriteString("    local cmd=\"\"\n")
-		sb.WriteString("    local subcmd=\"\"\n")
-		sb.WriteString("    for ((i=1; i < cword; i++)); do\n")
-		sb.WriteString("        case \"${words[i]}\" in\n")
-		sb.WriteString("            -*) ;;\n")
-		sb.WriteString("            *)\n")
-		sb.WriteString("                if [[ -z \"$cmd\" ]]; then\n")
-		sb.WriteString("                    cmd=\"${words[i]}\"\n")
-		sb.WriteString("                else\n")
-		sb.WriteString("                    subcmd=\"${words[i]}\"\n")
-		sb.WriteString("                    break\n")
-		sb.WriteString("                fi\n")
-		sb.WriteString("                ;;\n")
-		sb.WriteString("        esac\n")
-		sb.WriteString("    done\n\n")
-
-		// Generate case statements for each subcommand
-		sb.WriteString("    case \"$cmd\" in\n")
-		for _, cmd := range tool.Subcommands {
-			b.generateSubcommandCase(&sb, cmd, 2)
-		}
-		sb.WriteString("    esac\n\n")
-
-		sb.WriteString("    # Top level: complete subcommands or flags\n")
-		sb.WriteString("    if [[ -z \"$cmd\" ]]; then\n")
-		sb.WriteString("        COMPREPLY=($(compgen -W \"$commands $flags\" -- \"$cur\"))\n")
+		// A leaf command's other words are positional arguments, left to the
+		// default (file) completion unless a flag is being typed
+		sb.WriteString("    if [[ -n \"${subcommands[$path]}\" || \"$cur\" == [-+]* ]]; then\n")
+		sb.WriteString("        COMPREPLY=($(compgen -W \"${subcommands[$path]} ${command_flags[$path]} $flags\" -- \"$cur\"))\n")
 		sb.WriteString("    fi\n")
 	} else if len(tool.GlobalFlags) > 0 {
 		// No subcommands, just flags
@@ -115,64 +102,122 @@ func (b *Bash) Generate(tool *types.Tool) string {
 	return sb.String()
 }
 
-// generateSubcommandCase generates a case entry for a subcommand
-func (b *Bash) generateSubcommandCase(sb *strings.Builder, cmd types.Command, indent int) {
-	prefix := strings.Repeat("    ", indent)
-
-	// Build pattern matching name and aliases (e.g., "build|b")
-	fmt.Fprintf(sb, "%s%s)\n", prefix, buildCasePattern(cmd.Name, cmd.Aliases))
-
-	// If this command has nested subcommands, handle them
-	if len(cmd.Subcommands) > 0 {
-		// Include aliases in the subcommand list
-		var subcmds []string
-		for _, sub := range cmd.Subcommands {
-			subcmds = append(subcmds, escapeShellString(sub.Name))
-			for _, alias := range sub.Aliases {
-				subcmds = append(subcmds, escapeShellString(alias))
+// writeBashPassthroughGuard stops flag completion once "--" has been typed.
+// Words after it belong to a passed-through command (as in "docker run IMAGE
+// -- cmd"), so the first is completed as a command or file, the rest as files.
+func writeBashPassthroughGuard(sb *strings.Builder) {
+	sb.WriteString("    # Words after \"--\" are passed through: complete commands, then files\n")
+	sb.WriteString("    local i\n")
+	sb.WriteString("    for ((i=1; i < cword; i++)); do\n")
+	sb.WriteString("        if [[ \"${words[i]}\" == \"--\" ]]; then\n")
+	sb.WriteString("            if ((i == cword - 1)); then\n")
+	sb.WriteString("                COMPREPLY=($(compgen -c -f -- \"$cur\"))\n")
+	sb.WriteString("            else\n")
+	sb.WriteString("                COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+	sb.WriteString("            fi\n")
+	sb.WriteString("            return\n")
+	sb.WriteString("        fi\n")
+	sb.WriteString("    done\n\n")
+}
+
+// generateDispatcher emits lookup tables keyed by subcommand path ("tool",
+// "tool remote", "tool remote add", aliases included) and the code that walks
+// the words typed so far down those tables into $path, so every level of
+// nesting completes its own subcommands, flags, and flag values
+func (b *Bash) generateDispatcher(sb *strings.Builder, tool *types.Tool) {
+	subcommands := make(map[string][]string)
+	commandFlags := make(map[string][]string)
+	var walk func(path string, cmds []types.Command)
+	walk = func(path string, cmds []types.Command) {
+		for _, cmd := range cmds {
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				subcommands[path] = append(subcommands[path], escapeShellString(compgenWord(name)))
 			}
 		}
-
-		fmt.Fprintf(sb, "%s    case \"$subcmd\" in\n", prefix)
-		for _, sub := range cmd.Subcommands {
-			if len(sub.Flags) > 0 {
-				subFlags := collectFlags(sub.Flags)
-				fmt.Fprintf(sb, "%s        %s)\n", prefix, buildCasePattern(sub.Name, sub.Aliases))
-				fmt.Fprintf(sb, "%s            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", prefix, strings.Join(subFlags, " "))
-				fmt.Fprintf(sb, "%s            return\n", prefix)
-				fmt.Fprintf(sb, "%s            ;;\n", prefix)
+		for _, cmd := range cmds {
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				cmdPath := path + " " + name
+				subcommands[cmdPath] = nil
+				if len(cmd.Flags) > 0 {
+					commandFlags[cmdPath] = collectFlags(cmd.Flags)
+				}
+				walk(cmdPath, cmd.Subcommands)
 			}
 		}
-		fmt.Fprintf(sb, "%s    esac\n", prefix)
-
-		// Complete nested subcommands
-		if len(cmd.Flags) > 0 {
-			cmdFlags := collectFlags(cmd.Flags)
-			fmt.Fprintf(sb, "%s    COMPREPLY=($(compgen -W \"%s %s\" -- \"$cur\"))\n", prefix, strings.Join(subcmds, " "), strings.Join(cmdFlags, " "))
-		} else {
-			fmt.Fprintf(sb, "%s    COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", prefix, strings.Join(subcmds, " "))
+	}
+	walk(tool.Name, tool.Subcommands)
+
+	sb.WriteString("    # Subcommands and flags of each command path\n")
+	sb.WriteString("    local -A subcommands=(\n")
+	for _, path := range sortedKeys(subcommands) {
+		fmt.Fprintf(sb, "        [\"%s\"]=\"%s\"\n", escapeShellString(path), strings.Join(subcommands[path], " "))
+	}
+	sb.WriteString("    )\n")
+	sb.WriteString("    local -A command_flags=(\n")
+	for _, path := range sortedKeys(commandFlags) {
+		fmt.Fprintf(sb, "        [\"%s\"]=\"%s\"\n", escapeShellString(path), strings.Join(commandFlags[path], " "))
+	}
+	sb.WriteString("    )\n\n")
+
+	sb.WriteString("    # Find the deepest command path, skipping flags and their values\n")
+	fmt.Fprintf(sb, "    local path=\"%s\" key skip=\"\"\n", escapeShellString(tool.Name))
+	sb.WriteString("    for ((i=1; i < cword; i++)); do\n")
+	sb.WriteString("        if [[ -n \"$skip\" ]]; then\n")
+	sb.WriteString("            skip=\"\"\n")
+	sb.WriteString("            continue\n")
+	sb.WriteString("        fi\n")
+	sb.WriteString("        case \"${words[i]}\" in\n")
+	if argFlags := argTakingFlags(tool); len(argFlags) > 0 {
+		fmt.Fprintf(sb, "            %s) skip=1 ;;\n", strings.Join(argFlags, "|"))
+	}
+	sb.WriteString("            -*) ;;\n")
+	sb.WriteString("            *)\n")
+	sb.WriteString("                key=\"$path ${words[i]}\"\n")
+	sb.WriteString("                if [[ -n \"${subcommands[$key]+set}\" ]]; then\n")
+	sb.WriteString("                    path=\"$key\"\n")
+	sb.WriteString("                fi\n")
+	sb.WriteString("                ;;\n")
+	sb.WriteString("        esac\n")
+	sb.WriteString("    done\n")
+}
+
+// argTakingFlags returns escaped case patterns for every flag (global and
+// nested) whose value is the next word, so the subcommand scan can skip it
+func argTakingFlags(tool *types.Tool) []string {
+	seen := make(map[string]bool)
+	var result []string
+	add := func(flags []types.Flag) {
+		for _, flag := range flags {
+			if flag.Arg == "" || flag.OptionalArg {
+				continue
+			}
+			for _, name := range flag.Names() {
+				if !seen[name] {
+					seen[name] = true
+					result = append(result, escapeCasePattern(name))
+				}
+			}
 		}
-	} else if len(cmd.Flags) > 0 {
-		// Just flags for this command
-		cmdFlags := collectFlags(cmd.Flags)
-		fmt.Fprintf(sb, "%s    COMPREPLY=($(compgen -W \"%s $flags\" -- \"$cur\"))\n", prefix, strings.Join(cmdFlags, " "))
-	} else {
-		fmt.Fprintf(sb, "%s    COMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))\n", prefix)
 	}
 
-	fmt.Fprintf(sb, "%s    return\n", prefix)
-	fmt.Fprintf(sb, "%s    ;;\n", prefix)
+	add(tool.GlobalFlags)
+	var walk func(cmds []types.Command)
+	walk = func(cmds []types.Command) {
+		for _, cmd := range cmds {
+			add(cmd.Flags)
+			walk(cmd.Subcommands)
+		}
+	}
+	walk(tool.Subcommands)
+	return result
 }
 
 // collectFlags extracts flag names from a slice of flags
 func collectFlags(flags []types.Flag) []string {
 	result := make([]string, 0, len(flags)*2)
 	for _, flag := range flags {
-		if flag.Name != "" {
-			result = append(result, escapeShellString(flag.Name))
-		}
-		if flag.Short != "" {
-			result = append(result, escapeShellString(flag.Short))
+		for _, name := range flag.Names() {
+			result = append(result, escapeShellString(compgenWord(name)))
 		}
 	}
 	return result
@@ -189,7 +234,33 @@ func escapeShellString(s string) string {
 	return replacer.Replace(s)
 }
 
-// escapeCasePattern escapes characters special in bash case patterns
+// compgenWord quotes a word for a "compgen -W" list, which is split and
+// expanded again at completion time. Words made only of characters that are
+// safe there are left as they are; others are single-quoted so spaces,
+// quotes, and "$" survive as part of the word.
+func compgenWord(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./:=@%+,", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// compgenWords joins words into a "compgen -W" list for a double-quoted string
+func compgenWords(words []string) string {
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = compgenWord(w)
+	}
+	return escapeShellString(strings.Join(quoted, " "))
+}
+
+// escapeCasePattern escapes characters special in bash case patterns, and
+// the quotes and shell metacharacters that would break the case statement
+// around them (less has a -" flag)
 func escapeCasePattern(s string) string {
 	replacer := strings.NewReplacer(
 		`\`, `\\`,
@@ -197,21 +268,22 @@ func escapeCasePattern(s string) string {
 		`?`, `\?`,
 		`[`, `\[`,
 		`]`, `\]`,
+		`(`, `\(`,
 		`)`, `\)`,
+		`|`, `\|`,
+		`"`, `\"`,
+		`'`, `\'`,
+		"`", "\\`",
+		`$`, `\$`,
+		` `, `\ `,
+		`;`, `\;`,
+		`&`, `\&`,
+		`<`, `\<`,
+		`>`, `\>`,
 	)
 	return replacer.Replace(s)
 }
 
-// buildCasePattern creates a case pattern from name and aliases with proper escaping
-func buildCasePattern(name string, aliases []string) string {
-	parts := make([]string, 0, 1+len(aliases))
-	parts = append(parts, escapeCasePattern(name))
-	for _, alias := range aliases {
-		parts = append(parts, escapeCasePattern(alias))
-	}
-	return strings.Join(parts, "|")
-}
-
 // bashFuncName creates a valid bash function name from a tool name
 func bashFuncName(name string) string {
 	// Replace non-alphanumeric chars with underscore
@@ -224,70 +296,166 @@ func bashFuncName(name string) string {
 	return "_tabgen_" + clean
 }
 
-// generateFlagValueCompletions generates case statements for flag argument values
-func (b *Bash) generateFlagValueCompletions(sb *strings.Builder, globalFlags []types.Flag, subcommands []types.Command) {
-	// Collect all flags with argument values
-	flagValues := make(map[string][]string)
+// generateFlagValueCompletions generates case statements completing the value
+// after a flag. A command's flags are only matched at that command's path, so
+// subcommands may give the same flag different values; global flags are
+// matched everywhere, after the command's own.
+func (b *Bash) generateFlagValueCompletions(sb *strings.Builder, tool *types.Tool) {
+	// Optional values must be attached (--flag=value), so the next word is not one
+	nextWord := func(name string, flag types.Flag) bool { return !flag.OptionalArg }
+	scoped := scopedFlagValues(tool, nextWord)
+	global := flagValues(tool.GlobalFlags, nextWord)
+	if len(scoped) == 0 && len(global) == 0 {
+		return
+	}
 
-	for _, flag := range globalFlags {
-		if len(flag.ArgumentValues) > 0 {
-			if flag.Name != "" {
-				flagValues[flag.Name] = flag.ArgumentValues
-			}
-			if flag.Short != "" {
-				flagValues[flag.Short] = flag.ArgumentValues
-			}
+	sb.WriteString("\n    # Handle flag argument value completions\n")
+	if len(scoped) > 0 {
+		sb.WriteString("    case \"$path\" in\n")
+		for _, path := range sortedKeys(scoped) {
+			fmt.Fprintf(sb, "        \"%s\")\n", escapeShellString(path))
+			writeValueCase(sb, "            ", "$prev", "$cur", scoped[path])
+			sb.WriteString("            ;;\n")
 		}
+		sb.WriteString("    esac\n")
 	}
+	if len(global) > 0 {
+		writeValueCase(sb, "    ", "$prev", "$cur", global)
+	}
+}
 
-	// Also collect from subcommands
-	var collectFromCommands func([]types.Command)
-	collectFromCommands = func(cmds []types.Command) {
+// generateInlineValueCompletions generates completion of values attached with "=",
+// e.g. "--format=js" completes to "--format=json", scoped like
+// generateFlagValueCompletions. Requires "=" to be excluded from word splitting
+// (_init_completion -n =).
+func (b *Bash) generateInlineValueCompletions(sb *strings.Builder, tool *types.Tool) {
+	long := func(name string, flag types.Flag) bool { return strings.HasPrefix(name, "--") }
+	scoped := scopedFlagValues(tool, long)
+	global := flagValues(tool.GlobalFlags, long)
+	if len(scoped) == 0 && len(global) == 0 {
+		return
+	}
+
+	sb.WriteString("\n    # Handle --flag=value completions\n")
+	sb.WriteString("    if [[ \"$cur\" == --*=* ]]; then\n")
+	sb.WriteString("        local flag=\"${cur%%=*}\" value=\"${cur#*=}\"\n")
+	if len(scoped) > 0 {
+		sb.WriteString("        case \"$path\" in\n")
+		for _, path := range sortedKeys(scoped) {
+			fmt.Fprintf(sb, "            \"%s\")\n", escapeShellString(path))
+			writeValueCase(sb, "                ", "$flag", "$value", scoped[path])
+			sb.WriteString("                ;;\n")
+		}
+		sb.WriteString("        esac\n")
+	}
+	if len(global) > 0 {
+		writeValueCase(sb, "        ", "$flag", "$value", global)
+	}
+	sb.WriteString("    fi\n")
+}
+
+// writeValueCase writes a case statement on word that completes current from
+// the values of the matching flag. Flags with the same values share an arm.
+func writeValueCase(sb *strings.Builder, indent, word, current string, values map[string][]string) {
+	groups := make(map[string][]string)
+	for _, name := range sortedKeys(values) {
+		key := compgenWords(values[name])
+		groups[key] = append(groups[key], escapeCasePattern(name))
+	}
+
+	fmt.Fprintf(sb, "%scase \"%s\" in\n", indent, word)
+	for _, key := range sortedKeys(groups) {
+		fmt.Fprintf(sb, "%s    %s)\n", indent, strings.Join(groups[key], "|"))
+		// One value per line, so values with spaces stay whole
+		fmt.Fprintf(sb, "%s        mapfile -t COMPREPLY < <(compgen -W \"%s\" -- \"%s\")\n", indent, key, current)
+		fmt.Fprintf(sb, "%s        return\n", indent)
+		fmt.Fprintf(sb, "%s        ;;\n", indent)
+	}
+	fmt.Fprintf(sb, "%sesac\n", indent)
+}
+
+// scopedFlagValues maps each command path, named as generateDispatcher's
+// tables are, to the values of its flags that pass keep. Paths without any
+// are left out.
+func scopedFlagValues(tool *types.Tool, keep func(name string, flag types.Flag) bool) map[string]map[string][]string {
+	scoped := make(map[string]map[string][]string)
+	var walk func(path string, cmds []types.Command)
+	walk = func(path string, cmds []types.Command) {
 		for _, cmd := range cmds {
-			for _, flag := range cmd.Flags {
-				if len(flag.ArgumentValues) > 0 {
-					if flag.Name != "" {
-						flagValues[flag.Name] = flag.ArgumentValues
-					}
-					if flag.Short != "" {
-						flagValues[flag.Short] = flag.ArgumentValues
-					}
+			values := flagValues(cmd.Flags, keep)
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				cmdPath := path + " " + name
+				if len(values) > 0 {
+					scoped[cmdPath] = values
 				}
+				walk(cmdPath, cmd.Subcommands)
 			}
-			if len(cmd.Subcommands) > 0 {
-				collectFromCommands(cmd.Subcommands)
+		}
+	}
+	walk(tool.Name, tool.Subcommands)
+	return scoped
+}
+
+// flagValues maps the names (long and short) of flags with argument values to
+// those values, for names that pass keep
+func flagValues(flags []types.Flag, keep func(name string, flag types.Flag) bool) map[string][]string {
+	values := make(map[string][]string)
+	for _, flag := range flags {
+		if len(flag.ArgumentValues) == 0 {
+			continue
+		}
+		for _, name := range flag.Names() {
+			if keep(name, flag) {
+				values[name] = flag.ArgumentValues
 			}
 		}
 	}
-	collectFromCommands(subcommands)
+	return values
+}
+
+// hasLongFlagValues reports whether any long flag of the tool has argument
+// values, which may be completed after "--flag="
+func hasLongFlagValues(tool *types.Tool) bool {
+	long := func(name string, flag types.Flag) bool { return strings.HasPrefix(name, "--") }
+	return len(flagValues(tool.GlobalFlags, long)) > 0 || len(scopedFlagValues(tool, long)) > 0
+}
 
-	if len(flagValues) == 0 {
+// generateDynamicCompletions generates case statements that complete flag values
+// by running a command at completion time (e.g. listing git branches)
+func (b *Bash) generateDynamicCompletions(sb *strings.Builder, tool *types.Tool) {
+	if b.dynamic == nil {
 		return
 	}
 
-	sb.WriteString("\n    # Handle flag argument value completions\n")
-	sb.WriteString("    case \"$prev\" in\n")
+	// Group flag names by the command that completes them
+	patterns := make(map[string][]string)
+	var commands []string
+	for _, flag := range dynamicFlags(tool) {
+		command, ok := b.dynamic[dynamicKey(tool.Name, flag.Dynamic)]
+		if !ok {
+			continue
+		}
+		if _, seen := patterns[command]; !seen {
+			commands = append(commands, command)
+		}
+		for _, name := range flag.Names() {
+			if !slices.Contains(patterns[command], escapeCasePattern(name)) {
+				patterns[command] = append(patterns[command], escapeCasePattern(name))
+			}
+		}
+	}
 
-	// Group flags by their values to reduce duplication
-	valueGroups := make(map[string][]string)
-	for flag, values := range flagValues {
-		key := strings.Join(values, " ")
-		valueGroups[key] = append(valueGroups[key], flag)
+	if len(commands) == 0 {
+		return
 	}
 
-	for values, flags := range valueGroups {
-		// Escape each flag name for case pattern
-		escapedFlags := make([]string, len(flags))
-		for i, f := range flags {
-			escapedFlags[i] = escapeCasePattern(f)
-		}
-		pattern := strings.Join(escapedFlags, "|")
-		fmt.Fprintf(sb, "        %s)\n", pattern)
-		// Escape values for double-quoted string
-		fmt.Fprintf(sb, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", escapeShellString(values))
+	sb.WriteString("\n    # Handle dynamic flag value completions\n")
+	sb.WriteString("    case \"$prev\" in\n")
+	for _, command := range commands {
+		fmt.Fprintf(sb, "        %s)\n", strings.Join(patterns[command], "|"))
+		fmt.Fprintf(sb, "            COMPREPLY=($(compgen -W \"$(%s)\" -- \"$cur\"))\n", command)
 		sb.WriteString("            return\n")
 		sb.WriteString("            ;;\n")
 	}
-
 	sb.WriteString("    esac\n")
 }
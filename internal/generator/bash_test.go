@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -26,7 +27,7 @@ func TestBash_Generate_SimpleTool_NoSubcommands(t *testing.T) {
 	}
 
 	// No subcommands/flags, should fall back to file completion
-	if !strings.Contains(output, "compgen -f") {
+	if !strings.Contains(output, "    COMPREPLY=($(compgen -f -- \"$cur\"))\n}") {
 		t.Error("expected file completion fallback for tool with no subcommands/flags")
 	}
 }
@@ -130,7 +131,7 @@ func TestBash_GenerateFlagValueCompletions(t *testing.T) {
 		},
 	}
 
-	b.generateFlagValueCompletions(&sb, globalFlags, subcommands)
+	b.generateFlagValueCompletions(&sb, &types.Tool{Name: "tool", GlobalFlags: globalFlags, Subcommands: subcommands})
 
 	output := sb.String()
 
@@ -150,6 +151,77 @@ func TestBash_GenerateFlagValueCompletions(t *testing.T) {
 	}
 }
 
+func TestBash_GenerateFlagValueCompletions_Quoting(t *testing.T) {
+	b := NewBash()
+	var sb strings.Builder
+	flags := []types.Flag{
+		{Name: "--format", Arg: "FMT", ArgumentValues: []string{"plain", "my value", "co$t", "it's"}},
+	}
+	b.generateFlagValueCompletions(&sb, &types.Tool{Name: "tool", GlobalFlags: flags})
+	output := sb.String()
+
+	want := `mapfile -t COMPREPLY < <(compgen -W "plain 'my value' 'co\$t' 'it'\\''s'" -- "$cur")`
+	if !strings.Contains(output, want) {
+		t.Fatalf("expected quoted values %s, got:\n%s", want, output)
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	words := strings.TrimSuffix(strings.TrimPrefix(want, "mapfile -t COMPREPLY < <("), `)`)
+	out, err := exec.Command(bash, "-c", strings.Replace(words, `"$cur"`, `""`, 1)).Output()
+	if err != nil {
+		t.Fatalf("bash failed: %v", err)
+	}
+	if got := strings.Split(strings.TrimSpace(string(out)), "\n"); strings.Join(got, "|") != "plain|my value|co$t|it's" {
+		t.Errorf("compgen words = %q", got)
+	}
+}
+
+func TestBash_Generate_QuoteFlagsParse(t *testing.T) {
+	// less has "-\"" and "-#" flags that take a value
+	tool := &types.Tool{
+		Name:        "less",
+		Subcommands: []types.Command{{Name: "h"}},
+		GlobalFlags: []types.Flag{
+			{Name: `-"`, Arg: "cc"},
+			{Name: "-#", Arg: "N"},
+			{Name: "-'", Arg: "X"},
+		},
+	}
+	script := NewBash().Generate(tool)
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	cmd := exec.Command(bash, "-n")
+	cmd.Stdin = strings.NewReader(script)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated script doesn't parse: %v\n%s\n%s", err, out, script)
+	}
+}
+
+func TestCompgenWord(t *testing.T) {
+	tests := map[string]string{
+		"json":     "json",
+		"--output": "--output",
+		"a=b,c":    "a=b,c",
+		"-?":       "'-?'",
+		"my value": "'my value'",
+		"$HOME":    "'$HOME'",
+		"it's":     `'it'\''s'`,
+		"":         "''",
+		"`whoami`": "'`whoami`'",
+	}
+	for in, want := range tests {
+		if got := compgenWord(in); got != want {
+			t.Errorf("compgenWord(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
 func TestBash_GenerateFlagValueCompletions_Empty(t *testing.T) {
 	b := NewBash()
 	var sb strings.Builder
@@ -159,7 +231,7 @@ func TestBash_GenerateFlagValueCompletions_Empty(t *testing.T) {
 		{Name: "--verbose"},
 	}
 
-	b.generateFlagValueCompletions(&sb, globalFlags, nil)
+	b.generateFlagValueCompletions(&sb, &types.Tool{Name: "tool", GlobalFlags: globalFlags})
 
 	output := sb.String()
 
@@ -301,6 +373,10 @@ func TestEscapeCasePattern(t *testing.T) {
 		{"paren)", `paren\)`},
 		{`back\slash`, `back\\slash`},
 		{"*?[])", `\*\?\[\]\)`},
+		{`-"`, `-\"`},
+		{"-'", `-\'`},
+		{"a|b (c)", `a\|b\ \(c\)`},
+		{"$x;`y`&<>", "\\$x\\;\\`y\\`\\&\\<\\>"},
 	}
 
 	for _, tt := range tests {
@@ -311,26 +387,6 @@ func TestEscapeCasePattern(t *testing.T) {
 	}
 }
 
-func TestBuildCasePattern(t *testing.T) {
-	tests := []struct {
-		name     string
-		aliases  []string
-		expected string
-	}{
-		{"simple", nil, "simple"},
-		{"cmd", []string{"c"}, "cmd|c"},
-		{"build", []string{"b", "bld"}, "build|b|bld"},
-		{"cmd*", []string{"c?"}, `cmd\*|c\?`},
-	}
-
-	for _, tt := range tests {
-		result := buildCasePattern(tt.name, tt.aliases)
-		if result != tt.expected {
-			t.Errorf("buildCasePattern(%q, %v) = %q, want %q", tt.name, tt.aliases, result, tt.expected)
-		}
-	}
-}
-
 func TestBashGenerateWithSpecialChars(t *testing.T) {
 	gen := NewBash()
 
@@ -358,3 +414,280 @@ func TestBashGenerateWithSpecialChars(t *testing.T) {
 		t.Error("flag with $ should be escaped")
 	}
 }
+
+func TestBash_Generate_DynamicCompletions(t *testing.T) {
+	tool := &types.Tool{
+		Name: "git",
+		Subcommands: []types.Command{
+			{Name: "push", Flags: []types.Flag{{Name: "--repo", Arg: "REMOTE", Dynamic: "remote"}}},
+		},
+		GlobalFlags: []types.Flag{{Name: "--branch", Short: "-b", Arg: "BRANCH", Dynamic: "branch"}},
+	}
+
+	b := NewBash()
+	if script := b.Generate(tool); strings.Contains(script, "git branch --format") {
+		t.Error("dynamic commands should not be emitted unless enabled")
+	}
+
+	b.SetDynamicCompletions(DefaultDynamicCompletions)
+	script := b.Generate(tool)
+	for _, want := range []string{
+		"--branch|-b)",
+		`COMPREPLY=($(compgen -W "$(git branch --format='%(refname:short)' 2>/dev/null)" -- "$cur"))`,
+		"--repo)",
+		"git remote 2>/dev/null",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q", want)
+		}
+	}
+
+	// Unknown tools have no commands in the table
+	tool.Name = "othertool"
+	if script := b.Generate(tool); strings.Contains(script, "dynamic flag value") {
+		t.Error("no dynamic completions expected for tools without table entries")
+	}
+}
+
+func TestBash_Generate_InlineFlagValues(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		GlobalFlags: []types.Flag{
+			{Name: "--format", Short: "-f", Arg: "FMT", ArgumentValues: []string{"json", "yaml"}},
+			{Name: "--verbose"},
+		},
+	}
+
+	script := NewBash().Generate(tool)
+	for _, want := range []string{
+		"_init_completion -n = || return",
+		`if [[ "$cur" == --*=* ]]; then`,
+		`local flag="${cur%%=*}" value="${cur#*=}"`,
+		`--format)`,
+		`mapfile -t COMPREPLY < <(compgen -W "json yaml" -- "$value")`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q", want)
+		}
+	}
+	if strings.Contains(script, `            -f)`) {
+		t.Error("short flags should not get --flag=value handling")
+	}
+
+	// Without long flag values, word splitting is left alone
+	tool.GlobalFlags = []types.Flag{{Name: "--verbose"}}
+	script = NewBash().Generate(tool)
+	if !strings.Contains(script, "    _init_completion || return") || strings.Contains(script, "--*=*") {
+		t.Error("expected no --flag=value handling without flag values")
+	}
+}
+
+func TestBash_Generate_OptionalFlagValue(t *testing.T) {
+	b := NewBash()
+	tool := &types.Tool{
+		Name: "ls",
+		GlobalFlags: []types.Flag{
+			{Name: "--color", Arg: "WHEN", OptionalArg: true, ArgumentValues: []string{"always", "never"}},
+		},
+	}
+
+	script := b.Generate(tool)
+
+	// The value can only be attached, so it completes after "=" but not as the next word
+	if strings.Contains(script, "case \"$prev\" in") {
+		t.Error("optional value should not be completed from the previous word")
+	}
+	if !strings.Contains(script, "--color)") || !strings.Contains(script, "always never") {
+		t.Error("expected --color=value completion")
+	}
+}
+
+func TestBash_Generate_ShortAliases(t *testing.T) {
+	b := NewBash()
+	tool := &types.Tool{
+		Name: "tool",
+		GlobalFlags: []types.Flag{
+			{Name: "-h", ShortAliases: []string{"-?"}, Description: "Show help"},
+		},
+	}
+
+	script := b.Generate(tool)
+
+	// "?" is a glob character, so the alias is quoted for compgen
+	if !strings.Contains(script, `"-h '-?'"`) {
+		t.Errorf("expected every short alias in the flag list:\n%s", script)
+	}
+}
+
+func TestBash_Generate_ScopedSubcommandFlags(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "mytool",
+		GlobalFlags: []types.Flag{{Name: "--config", Short: "-c", Arg: "FILE"}, {Name: "--verbose"}},
+		Subcommands: []types.Command{
+			{Name: "remote", Flags: []types.Flag{{Name: "--quiet"}}, Subcommands: []types.Command{
+				{Name: "add", Flags: []types.Flag{{Name: "--fetch"}}},
+				{Name: "remove"},
+			}},
+			{Name: "build", Aliases: []string{"b"}, Flags: []types.Flag{{Name: "--release"}}},
+		},
+	}
+
+	script := NewBash().Generate(tool)
+
+	for _, want := range []string{
+		// Flag values don't count as a subcommand
+		"--config|-c) skip=1 ;;",
+		// Subcommands of each path, aliases included
+		`["mytool"]="remote build b"`,
+		`["mytool remote"]="add remove"`,
+		`["mytool remote add"]=""`,
+		// Each command path's own flags, aliases sharing them
+		`["mytool b"]="--release"`,
+		`["mytool build"]="--release"`,
+		`["mytool remote"]="--quiet"`,
+		`["mytool remote add"]="--fetch"`,
+		`COMPREPLY=($(compgen -W "${subcommands[$path]} ${command_flags[$path]} $flags" -- "$cur"))`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+}
+
+func TestBash_Generate_NestedDispatcher(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "aws",
+		GlobalFlags: []types.Flag{{Name: "--profile", Arg: "NAME"}},
+		Subcommands: []types.Command{
+			{Name: "s3", Subcommands: []types.Command{
+				{Name: "cp", Flags: []types.Flag{{Name: "--recursive"}, {Name: "--dryrun"}}},
+				{Name: "ls", Flags: []types.Flag{{Name: "--human-readable"}}},
+			}},
+		},
+	}
+
+	script := NewBash().Generate(tool)
+
+	for _, want := range []string{
+		`["aws s3"]="cp ls"`,
+		`["aws s3 cp"]="--recursive --dryrun"`,
+		`["aws s3 ls"]="--human-readable"`,
+		`local path="aws" key skip=""`,
+		`key="$path ${words[i]}"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+}
+
+func TestBash_Generate_PassthroughGuard(t *testing.T) {
+	b := NewBash()
+	tool := &types.Tool{
+		Name:        "kubectl",
+		GlobalFlags: []types.Flag{{Name: "--namespace"}},
+		Subcommands: []types.Command{{Name: "exec", Flags: []types.Flag{{Name: "--stdin"}}}},
+	}
+
+	output := b.Generate(tool)
+
+	guard := strings.Index(output, `if [[ "${words[i]}" == "--" ]]; then`)
+	if guard < 0 {
+		t.Fatalf("missing -- guard:\n%s", output)
+	}
+	if !strings.Contains(output, `COMPREPLY=($(compgen -c -f -- "$cur"))`) {
+		t.Error("expected command completion right after --")
+	}
+	if flags := strings.Index(output, `compgen -W "${subcommands[$path]}`); flags < guard {
+		t.Error("-- guard should run before flag completion")
+	}
+}
+
+func TestBash_Generate_TwoCharAlias(t *testing.T) {
+	tool := &types.Tool{
+		Name: "pkg",
+		Subcommands: []types.Command{
+			{Name: "install", Aliases: []string{"in"}, Description: "Install a package", Flags: []types.Flag{
+				{Name: "--force", Description: "Reinstall"},
+			}},
+		},
+	}
+
+	script := NewBash().Generate(tool)
+	for _, want := range []string{
+		`["pkg"]="install in"`,
+		`["pkg install"]="--force"`,
+		`["pkg in"]="--force"`,
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q", want)
+		}
+	}
+}
+
+func TestBash_Generate_ScopedFlagValues(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "svc",
+		GlobalFlags: []types.Flag{{Name: "--level", Arg: "LEVEL", ArgumentValues: []string{"debug", "info"}}},
+		Subcommands: []types.Command{
+			{Name: "run", Flags: []types.Flag{{Name: "--mode", Short: "-m", Arg: "MODE", ArgumentValues: []string{"fast", "slow"}}}},
+			{Name: "build", Aliases: []string{"b"}, Flags: []types.Flag{{Name: "--mode", Arg: "MODE", ArgumentValues: []string{"dev", "release"}}}},
+		},
+	}
+	script := NewBash().Generate(tool)
+
+	if !strings.Contains(script, "case \"$path\" in") || !strings.Contains(script, "\"svc run\")") {
+		t.Fatalf("expected value completions scoped by command path:\n%s", script)
+	}
+
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not available")
+	}
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"svc", "run", "--mode", ""}, "fast slow"},
+		{[]string{"svc", "run", "-m", ""}, "fast slow"},
+		{[]string{"svc", "build", "--mode", ""}, "dev release"},
+		{[]string{"svc", "b", "--mode", ""}, "dev release"},
+		{[]string{"svc", "build", "--mode=r"}, "release"},
+		{[]string{"svc", "run", "--level", ""}, "debug info"},
+		{[]string{"svc", "--level", ""}, "debug info"},
+	}
+	for _, tt := range tests {
+		if got := bashComplete(t, bash, script, tt.words); got != tt.want {
+			t.Errorf("completing %q = %q, want %q", tt.words, got, tt.want)
+		}
+	}
+
+	// --mode belongs to the subcommands, so no mode is offered at the top level
+	if got := bashComplete(t, bash, script, []string{"svc", "--mode", ""}); strings.Contains(got, "fast") || strings.Contains(got, "dev") {
+		t.Errorf("top-level --mode completed subcommand values: %q", got)
+	}
+}
+
+// bashComplete runs the completion function of script for words, the last
+// being the word under the cursor, and returns the candidates joined by spaces.
+// _init_completion is stubbed, splitting words as given.
+func bashComplete(t *testing.T, bash, script string, words []string) string {
+	t.Helper()
+	quoted := make([]string, len(words))
+	for i, w := range words {
+		quoted[i] = "'" + w + "'"
+	}
+	driver := script + `
+_init_completion() { words=("${COMP_WORDS[@]}"); cword=$COMP_CWORD; cur=${words[cword]}; prev=${words[cword-1]}; }
+COMP_WORDS=(` + strings.Join(quoted, " ") + `)
+COMP_CWORD=$((${#COMP_WORDS[@]} - 1))
+COMPREPLY=()
+` + bashFuncName(words[0]) + `
+echo "${COMPREPLY[*]}"
+`
+	out, err := exec.Command(bash, "-c", driver).CombinedOutput()
+	if err != nil {
+		t.Fatalf("bash failed: %v\n%s", err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"sort"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// DefaultDynamicCompletions maps "tool:kind" to a shell command that prints
+// one completion candidate per line. Kinds come from types.Flag.Dynamic.
+var DefaultDynamicCompletions = map[string]string{
+	"git:branch":        "git branch --format='%(refname:short)' 2>/dev/null",
+	"git:remote":        "git remote 2>/dev/null",
+	"git:tag":           "git tag 2>/dev/null",
+	"docker:container":  "docker ps -a --format '{{.Names}}' 2>/dev/null",
+	"docker:image":      "docker images --format '{{.Repository}}:{{.Tag}}' 2>/dev/null",
+	"kubectl:pod":       "kubectl get pods -o name 2>/dev/null | cut -d/ -f2",
+	"kubectl:namespace": "kubectl get namespaces -o name 2>/dev/null | cut -d/ -f2",
+	"kubectl:context":   "kubectl config get-contexts -o name 2>/dev/null",
+}
+
+// dynamicKey builds the DynamicCompletion lookup key for a tool and value kind
+func dynamicKey(tool, kind string) string {
+	return tool + ":" + kind
+}
+
+// dynamicFlags returns every flag (global and nested) that has a dynamic kind
+func dynamicFlags(tool *types.Tool) []types.Flag {
+	var result []types.Flag
+	for _, flag := range tool.GlobalFlags {
+		if flag.Dynamic != "" {
+			result = append(result, flag)
+		}
+	}
+	var walk func(cmds []types.Command)
+	walk = func(cmds []types.Command) {
+		for _, cmd := range cmds {
+			for _, flag := range cmd.Flags {
+				if flag.Dynamic != "" {
+					result = append(result, flag)
+				}
+			}
+			walk(cmd.Subcommands)
+		}
+	}
+	walk(tool.Subcommands)
+	return result
+}
+
+// dynamicKinds returns the sorted, unique dynamic kinds used by a tool's flags
+func dynamicKinds(tool *types.Tool) []string {
+	seen := make(map[string]bool)
+	var kinds []string
+	for _, flag := range dynamicFlags(tool) {
+		if !seen[flag.Dynamic] {
+			seen[flag.Dynamic] = true
+			kinds = append(kinds, flag.Dynamic)
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
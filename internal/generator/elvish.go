@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Elvish generates elvish completion scripts
+type Elvish struct {
+	dynamic map[string]string // "tool:kind" -> candidate command; nil disables dynamic completion
+}
+
+// NewElvish creates a new Elvish generator
+func NewElvish() *Elvish {
+	return &Elvish{}
+}
+
+// SetDynamicCompletions enables dynamic value completion using the given
+// "tool:kind" -> shell command table (see DefaultDynamicCompletions)
+func (e *Elvish) SetDynamicCompletions(dynamic map[string]string) {
+	e.dynamic = dynamic
+}
+
+// GenerateWithLimits creates an elvish completion script with bounds checking
+func (e *Elvish) GenerateWithLimits(tool *types.Tool) GenerateResult {
+	truncatedTool, warnings := truncateTool(tool)
+
+	script := e.Generate(truncatedTool)
+
+	script, sizeWarnings := checkOutputSize(script, tool.Name)
+	warnings = append(warnings, sizeWarnings...)
+
+	return GenerateResult{
+		Script:   script,
+		Warnings: warnings,
+	}
+}
+
+// elvishFlagArgs collects flag argument completions, keyed by flag name
+type elvishFlagArgs struct {
+	values  map[string][]string
+	dynamic map[string]string
+	files   map[string]bool
+}
+
+// Generate creates an elvish completion script for a tool.
+// The script registers an arg-completer that walks the words typed so far to
+// find the current subcommand, then offers its subcommands and flags.
+func (e *Elvish) Generate(tool *types.Tool) string {
+	tool = prepareTool(tool)
+	var sb strings.Builder
+
+	writeHeader(&sb, "Elvish", tool)
+	sb.WriteString("\n")
+	sb.WriteString("use str\n\n")
+
+	fmt.Fprintf(&sb, "set edit:completion:arg-completer[%s] = {|@words|\n", elvishQuote(tool.Name))
+
+	// Candidates per subcommand path ("" is the top level)
+	candidates := make(map[string][][2]string)
+	args := elvishFlagArgs{
+		values:  make(map[string][]string),
+		dynamic: make(map[string]string),
+		files:   make(map[string]bool),
+	}
+	candidates[""] = e.candidates(tool, tool.Subcommands, tool.GlobalFlags, &args)
+	e.collectCommands(tool, "", tool.Subcommands, candidates, &args)
+
+	sb.WriteString("    var commands = [\n")
+	for _, path := range sortedKeys(candidates) {
+		fmt.Fprintf(&sb, "        &%s=[\n", elvishQuote(path))
+		for _, c := range candidates[path] {
+			fmt.Fprintf(&sb, "            [%s %s]\n", elvishQuote(c[0]), elvishQuote(elvishDisplay(c[0], c[1])))
+		}
+		sb.WriteString("        ]\n")
+	}
+	sb.WriteString("    ]\n")
+
+	sb.WriteString("    var values = [\n")
+	for _, name := range sortedKeys(args.values) {
+		quoted := make([]string, len(args.values[name]))
+		for i, v := range args.values[name] {
+			quoted[i] = elvishQuote(v)
+		}
+		fmt.Fprintf(&sb, "        &%s=[%s]\n", elvishQuote(name), strings.Join(quoted, " "))
+	}
+	sb.WriteString("    ]\n")
+
+	sb.WriteString("    var dynamic = [\n")
+	for _, name := range sortedKeys(args.dynamic) {
+		fmt.Fprintf(&sb, "        &%s=%s\n", elvishQuote(name), elvishQuote(args.dynamic[name]))
+	}
+	sb.WriteString("    ]\n")
+
+	sb.WriteString("    var files = [\n")
+	for _, name := range sortedKeys(args.files) {
+		fmt.Fprintf(&sb, "        &%s=$true\n", elvishQuote(name))
+	}
+	sb.WriteString("    ]\n\n")
+
+	// Complete flag argument values first
+	sb.WriteString("    var cur = $words[-1]\n")
+	sb.WriteString("    var prev = ''\n")
+	sb.WriteString("    if (> (count $words) 2) {\n")
+	sb.WriteString("        set prev = $words[-2]\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    if (has-key $values $prev) {\n")
+	sb.WriteString("        all $values[$prev]\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    if (has-key $dynamic $prev) {\n")
+	sb.WriteString("        sh -c $dynamic[$prev] | from-lines\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    if (has-key $files $prev) {\n")
+	sb.WriteString("        edit:complete-filename $cur\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("    }\n\n")
+
+	// Find the deepest subcommand named so far
+	sb.WriteString("    var path = ''\n")
+	sb.WriteString("    for w $words[1..-1] {\n")
+	sb.WriteString("        var key = $w\n")
+	sb.WriteString("        if (not-eq $path '') {\n")
+	sb.WriteString("            set key = $path' '$w\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("        if (and (not (str:has-prefix $w -)) (has-key $commands $key)) {\n")
+	sb.WriteString("            set path = $key\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("    for c $commands[$path] {\n")
+	sb.WriteString("        edit:complex-candidate $c[0] &display=$c[1]\n")
+	sb.WriteString("    }\n")
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// collectCommands records candidates for every nested subcommand path
+func (e *Elvish) collectCommands(tool *types.Tool, prefix string, cmds []types.Command, candidates map[string][][2]string, args *elvishFlagArgs) {
+	for _, cmd := range cmds {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			path := strings.TrimSpace(prefix + " " + name)
+			candidates[path] = e.candidates(tool, cmd.Subcommands, cmd.Flags, args)
+			e.collectCommands(tool, path, cmd.Subcommands, candidates, args)
+		}
+	}
+}
+
+// candidates builds [name description] pairs for subcommands and flags, and records
+// how each flag's argument completes
+func (e *Elvish) candidates(tool *types.Tool, cmds []types.Command, flags []types.Flag, args *elvishFlagArgs) [][2]string {
+	var result [][2]string
+	for _, cmd := range cmds {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			result = append(result, [2]string{name, normalizeDescription(cmd.Description, MaxDescriptionLength)})
+		}
+	}
+
+	for _, flag := range flags {
+		for _, name := range flag.Names() {
+			result = append(result, [2]string{name, normalizeDescription(flag.Description, MaxDescriptionLength)})
+
+			// Optional values must be attached, so the next word is not one
+			if flag.OptionalArg {
+				continue
+			}
+			switch classifyArg(flag) {
+			case argValues:
+				args.values[name] = flag.ArgumentValues
+			case argDynamic:
+				if command, ok := e.dynamic[dynamicKey(tool.Name, flag.Dynamic)]; ok {
+					args.dynamic[name] = command
+				}
+			case argFile, argDir:
+				args.files[name] = true
+			}
+		}
+	}
+	return result
+}
+
+// elvishDisplay formats a candidate with its description for the completion menu
+func elvishDisplay(name, desc string) string {
+	if desc == "" {
+		return name
+	}
+	return name + " (" + desc + ")"
+}
+
+// elvishQuote returns s as an elvish single-quoted string
+func elvishQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// sortedKeys returns a map's keys in sorted order for deterministic output
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestNewElvish(t *testing.T) {
+	if NewElvish() == nil {
+		t.Error("NewElvish() returned nil")
+	}
+}
+
+func TestElvish_Generate_Basic(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		Subcommands: []types.Command{
+			{
+				Name:        "build",
+				Aliases:     []string{"b"},
+				Description: "Build the project",
+				Flags:       []types.Flag{{Name: "--release", Description: "Optimized build"}},
+				Subcommands: []types.Command{{Name: "docs", Description: "Build docs"}},
+			},
+		},
+		GlobalFlags: []types.Flag{
+			{Name: "--verbose", Short: "-v", Description: "Enable verbose output"},
+			{Name: "--format", Arg: "FMT", ArgumentValues: []string{"json", "yaml"}},
+			{Name: "--config", Arg: "FILE", Description: "Don't use defaults"},
+		},
+	}
+
+	script := NewElvish().Generate(tool)
+
+	for _, want := range []string{
+		"# Generated by TabGen",
+		"set edit:completion:arg-completer['mytool'] = {|@words|",
+		"&''=[",
+		"['build' 'build (Build the project)']",
+		"['b' 'b (Build the project)']",
+		"['-v' '-v (Enable verbose output)']",
+		"&'build'=[",
+		"&'b'=[",
+		"&'build docs'=[",
+		"['--release' '--release (Optimized build)']",
+		"&'--format'=['json' 'yaml']",
+		"&'--config'=$true",
+		"['--config' '--config (Don''t use defaults)']",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q", want)
+		}
+	}
+}
+
+func TestElvish_Generate_DynamicCompletions(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "git",
+		GlobalFlags: []types.Flag{{Name: "--branch", Arg: "BRANCH", Dynamic: "branch"}},
+	}
+
+	e := NewElvish()
+	if strings.Contains(e.Generate(tool), "git branch --format") {
+		t.Error("dynamic commands should not be emitted unless enabled")
+	}
+
+	e.SetDynamicCompletions(DefaultDynamicCompletions)
+	want := "&'--branch'='git branch --format=''%(refname:short)'' 2>/dev/null'"
+	if script := e.Generate(tool); !strings.Contains(script, want) {
+		t.Errorf("script missing %q", want)
+	}
+}
+
+func TestElvishQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":    "'plain'",
+		"it's":     "'it''s'",
+		"":         "''",
+		"$var (x)": "'$var (x)'",
+	}
+	for in, want := range tests {
+		if got := elvishQuote(in); got != want {
+			t.Errorf("elvishQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Generator produces a completion script for one shell
+type Generator interface {
+	// Generate creates a completion script for a tool
+	Generate(tool *types.Tool) string
+	// GenerateWithLimits creates a completion script with bounds checking
+	GenerateWithLimits(tool *types.Tool) GenerateResult
+}
+
+var (
+	_ Generator = (*Bash)(nil)
+	_ Generator = (*Zsh)(nil)
+	_ Generator = (*Elvish)(nil)
+	_ Generator = (*Tcsh)(nil)
+	_ Generator = (*Xonsh)(nil)
+	_ Generator = (*Nushell)(nil)
+)
+
+// writeHeader writes the comment lines that open every generated script: the
+// shell and tool, the tool's summary when known, and a TabGen marker
+func writeHeader(sb *strings.Builder, shell string, tool *types.Tool) {
+	fmt.Fprintf(sb, "# %s completion for %s\n", shell, tool.Name)
+	if desc := normalizeDescription(tool.Description, 0); desc != "" {
+		fmt.Fprintf(sb, "# %s\n", desc)
+	}
+	sb.WriteString("# Generated by TabGen\n")
+}
+
+// argKind classifies how a flag's argument value should be completed
+type argKind int
+
+const (
+	argNone    argKind = iota // Flag takes no argument
+	argValues                 // Fixed set of values (Flag.ArgumentValues)
+	argDynamic                // Values listed at completion time (Flag.Dynamic)
+	argFile                   // A file path
+	argDir                    // A directory path
+	argOther                  // Free-form value with no completion
+)
+
+// fileArgNames and dirArgNames are normalized argument names that take paths
+var (
+	fileArgNames = []string{"file", "filename", "path", "files"}
+	dirArgNames  = []string{"dir", "directory", "dirname", "folder"}
+)
+
+// classifyArg determines how a flag's argument should be completed
+func classifyArg(flag types.Flag) argKind {
+	switch {
+	case len(flag.ArgumentValues) > 0:
+		return argValues
+	case flag.Dynamic != "":
+		return argDynamic
+	case flag.Arg == "":
+		return argNone
+	}
+
+	name := strings.ToLower(strings.Trim(flag.Arg, "<>[]."))
+	for _, n := range fileArgNames {
+		if name == n {
+			return argFile
+		}
+	}
+	for _, n := range dirArgNames {
+		if name == n {
+			return argDir
+		}
+	}
+	return argOther
+}
+
+// prepareTool returns the copy of tool that scripts are generated from:
+// hidden flags are left out, and each negatable flag is followed by its
+// "--no-" form, so scripts offer both spellings while the stored tool keeps
+// one flag. A "--no-" form the command already lists isn't repeated. Names
+// that Sanitize would reject are left out too, since scripts embed names
+// unquoted and tools saved before a rule was added may still have them.
+func prepareTool(tool *types.Tool) *types.Tool {
+	prepared := *tool
+	prepared.GlobalFlags = prepareFlags(tool.GlobalFlags)
+	prepared.Subcommands = prepareCommands(tool.Subcommands)
+	return &prepared
+}
+
+// prepareCommands prepares the flags of commands and their subcommands
+func prepareCommands(commands []types.Command) []types.Command {
+	if commands == nil {
+		return nil
+	}
+	prepared := make([]types.Command, 0, len(commands))
+	for _, cmd := range commands {
+		if !types.ValidCommandName(cmd.Name) {
+			continue
+		}
+		cmd.Aliases = slices.DeleteFunc(slices.Clone(cmd.Aliases), func(alias string) bool {
+			return !types.ValidCommandName(alias)
+		})
+		cmd.Flags = prepareFlags(cmd.Flags)
+		cmd.Subcommands = prepareCommands(cmd.Subcommands)
+		prepared = append(prepared, cmd)
+	}
+	return prepared
+}
+
+// prepareFlags drops hidden flags and unsafe names, and adds a plain
+// "--no-<name>" flag after each negatable one
+func prepareFlags(flags []types.Flag) []types.Flag {
+	listed := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		if !flag.Hidden {
+			listed[flag.Name] = true
+		}
+	}
+
+	var prepared []types.Flag
+	for _, flag := range flags {
+		if flag.Hidden || !types.ValidFlagName(flag.Name) {
+			continue
+		}
+		if flag.Short != "" && !types.ValidFlagName(flag.Short) {
+			flag.Short = ""
+		}
+		flag.ShortAliases = slices.DeleteFunc(slices.Clone(flag.ShortAliases), func(alias string) bool {
+			return !types.ValidFlagName(alias)
+		})
+		negated := flag.NegatedName()
+		flag.Negatable = false
+		prepared = append(prepared, flag)
+		if negated != "" && !listed[negated] {
+			listed[negated] = true
+			prepared = append(prepared, types.Flag{Name: negated, Description: "Negate " + flag.Name})
+		}
+	}
+	return prepared
+}
+
+// UnhideFlags returns a copy of tool with no flag marked Hidden, so scripts
+// generated from it complete hidden flags too
+func UnhideFlags(tool *types.Tool) *types.Tool {
+	unhidden := *tool
+	unhidden.GlobalFlags = unhideFlags(tool.GlobalFlags)
+	unhidden.Subcommands = unhideCommands(tool.Subcommands)
+	return &unhidden
+}
+
+// unhideCommands clears Hidden on the flags of commands and their subcommands
+func unhideCommands(commands []types.Command) []types.Command {
+	if commands == nil {
+		return nil
+	}
+	unhidden := make([]types.Command, len(commands))
+	for i, cmd := range commands {
+		cmd.Flags = unhideFlags(cmd.Flags)
+		cmd.Subcommands = unhideCommands(cmd.Subcommands)
+		unhidden[i] = cmd
+	}
+	return unhidden
+}
+
+// unhideFlags returns a copy of flags with Hidden cleared
+func unhideFlags(flags []types.Flag) []types.Flag {
+	if flags == nil {
+		return nil
+	}
+	unhidden := make([]types.Flag, len(flags))
+	for i, flag := range flags {
+		flag.Hidden = false
+		unhidden[i] = flag
+	}
+	return unhidden
+}
@@ -0,0 +1,123 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestClassifyArg(t *testing.T) {
+	tests := []struct {
+		name string
+		flag types.Flag
+		want argKind
+	}{
+		{"boolean flag", types.Flag{Name: "--verbose"}, argNone},
+		{"values", types.Flag{Name: "--format", Arg: "FMT", ArgumentValues: []string{"json"}}, argValues},
+		{"dynamic", types.Flag{Name: "--branch", Arg: "BRANCH", Dynamic: "branch"}, argDynamic},
+		{"file", types.Flag{Name: "--config", Arg: "<file>"}, argFile},
+		{"path", types.Flag{Name: "--out", Arg: "PATH"}, argFile},
+		{"directory", types.Flag{Name: "-C", Arg: "DIR"}, argDir},
+		{"other", types.Flag{Name: "--count", Arg: "N"}, argOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyArg(tt.flag); got != tt.want {
+				t.Errorf("classifyArg(%+v) = %d, want %d", tt.flag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteHeader(t *testing.T) {
+	var sb strings.Builder
+	writeHeader(&sb, "Bash", &types.Tool{Name: "svc", Description: "manage\n  services"})
+	want := "# Bash completion for svc\n# manage services\n# Generated by TabGen\n"
+	if sb.String() != want {
+		t.Errorf("writeHeader() = %q, want %q", sb.String(), want)
+	}
+
+	sb.Reset()
+	writeHeader(&sb, "Bash", &types.Tool{Name: "svc"})
+	if strings.Count(sb.String(), "\n") != 2 {
+		t.Errorf("expected no summary line without a description, got %q", sb.String())
+	}
+}
+
+func TestPrepareTool_Negatable(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "build",
+		GlobalFlags: []types.Flag{{Name: "--cache", Short: "-c", Description: "Use the cache", Negatable: true}},
+		Subcommands: []types.Command{{
+			Name: "run",
+			Flags: []types.Flag{
+				{Name: "--color", Negatable: true},
+				{Name: "--no-color", Description: "Disable color"},
+			},
+		}},
+	}
+
+	expanded := prepareTool(tool)
+	if names := flagNames(expanded.GlobalFlags); names != "--cache --no-cache" {
+		t.Errorf("global flags = %s, want --cache --no-cache", names)
+	}
+	if negated := expanded.GlobalFlags[1]; negated.Short != "" || negated.Negatable || negated.Description != "Negate --cache" {
+		t.Errorf("negated flag = %+v", negated)
+	}
+	if names := flagNames(expanded.Subcommands[0].Flags); names != "--color --no-color" {
+		t.Errorf("run flags = %s, want the listed --no-color only once", names)
+	}
+	if !tool.GlobalFlags[0].Negatable || len(tool.GlobalFlags) != 1 {
+		t.Errorf("input tool was modified: %+v", tool.GlobalFlags)
+	}
+
+	for name, gen := range map[string]Generator{
+		"bash": NewBash(), "zsh": NewZsh(), "elvish": NewElvish(),
+		"tcsh": NewTcsh(), "xonsh": NewXonsh(), "nushell": NewNushell(),
+	} {
+		if script := gen.Generate(tool); !strings.Contains(script, "no-cache") || strings.Contains(script, "[no-]") {
+			t.Errorf("%s script should offer --no-cache:\n%s", name, script)
+		}
+	}
+}
+
+// flagNames joins the names of flags with spaces
+func flagNames(flags []types.Flag) string {
+	names := make([]string, len(flags))
+	for i, flag := range flags {
+		names[i] = flag.Name
+	}
+	return strings.Join(names, " ")
+}
+
+func TestPrepareTool_Hidden(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "svc",
+		GlobalFlags: []types.Flag{{Name: "--verbose"}, {Name: "--trace", Hidden: true}},
+		Subcommands: []types.Command{{
+			Name:  "run",
+			Flags: []types.Flag{{Name: "--debug", Hidden: true, Negatable: true}},
+		}},
+	}
+
+	prepared := prepareTool(tool)
+	if names := flagNames(prepared.GlobalFlags); names != "--verbose" {
+		t.Errorf("global flags = %s, want --verbose", names)
+	}
+	if names := flagNames(prepared.Subcommands[0].Flags); names != "" {
+		t.Errorf("run flags = %s, want none", names)
+	}
+	if script := NewBash().Generate(tool); strings.Contains(script, "--trace") || strings.Contains(script, "debug") {
+		t.Errorf("hidden flags completed:\n%s", script)
+	}
+
+	unhidden := UnhideFlags(tool)
+	if !tool.GlobalFlags[1].Hidden {
+		t.Error("UnhideFlags modified its input")
+	}
+	if names := flagNames(prepareTool(unhidden).Subcommands[0].Flags); names != "--debug --no-debug" {
+		t.Errorf("unhidden run flags = %s, want --debug --no-debug", names)
+	}
+}
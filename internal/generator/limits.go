@@ -2,6 +2,7 @@ package generator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/jvalentini/tabgen/internal/types"
 )
@@ -19,6 +20,9 @@ const (
 
 	// MaxTotalItems is the maximum total items (subcommands + flags) in a tool
 	MaxTotalItems = 2000
+
+	// MaxDescriptionLength is the maximum length of a description in runes
+	MaxDescriptionLength = 100
 )
 
 // GenerateResult holds the generated script and any warnings
@@ -27,6 +31,19 @@ type GenerateResult struct {
 	Warnings []string // Any truncation or limit warnings
 }
 
+// normalizeDescription collapses runs of whitespace in a description and, if
+// it is longer than maxLen runes, truncates it with an ellipsis. A maxLen of
+// zero or less disables truncation. Only generated scripts are affected; the
+// stored tool keeps the full text.
+func normalizeDescription(s string, maxLen int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	runes := []rune(s)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return s
+	}
+	return strings.TrimRight(string(runes[:maxLen-1]), " ") + "…"
+}
+
 // countItems recursively counts all subcommands and flags in a tool
 func countItems(tool *types.Tool) (subcommands int, flags int) {
 	flags = len(tool.GlobalFlags)
@@ -180,3 +180,51 @@ func TestGenerateWithLimits(t *testing.T) {
 		t.Errorf("expected no warnings for normal tool, got: %v", zshResult.Warnings)
 	}
 }
+
+func TestNormalizeDescription(t *testing.T) {
+	tests := []struct {
+		name   string
+		desc   string
+		maxLen int
+		want   string
+	}{
+		{"collapses whitespace", "  Show   the\n\tstatus  ", 100, "Show the status"},
+		{"short enough", "Show status", 11, "Show status"},
+		{"truncated with ellipsis", "Show the working tree status", 12, "Show the wo…"},
+		{"no trailing space before ellipsis", "Show the working tree status", 10, "Show the…"},
+		{"counts runes", "Zeigt den Status für äöü", 20, "Zeigt den Status fü…"},
+		{"zero disables truncation", strings.Repeat("word ", 50), 0, strings.TrimSpace(strings.Repeat("word ", 50))},
+		{"empty", "", 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeDescription(tt.desc, tt.maxLen); got != tt.want {
+				t.Errorf("normalizeDescription(%q, %d) = %q, want %q", tt.desc, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerators_TruncateLongDescriptions(t *testing.T) {
+	long := strings.Repeat("very long description ", 20)
+	tool := &types.Tool{
+		Name:        "mytool",
+		Subcommands: []types.Command{{Name: "run", Description: long}},
+		GlobalFlags: []types.Flag{{Name: "--verbose", Description: long}},
+	}
+
+	for name, gen := range map[string]Generator{"zsh": NewZsh(), "elvish": NewElvish(), "xonsh": NewXonsh(), "nushell": NewNushell()} {
+		script := gen.Generate(tool)
+		if strings.Contains(script, strings.TrimSpace(long)) {
+			t.Errorf("%s: description was not truncated", name)
+		}
+		if !strings.Contains(script, "…") {
+			t.Errorf("%s: expected an ellipsis in truncated descriptions", name)
+		}
+	}
+
+	if tool.Subcommands[0].Description != long || tool.GlobalFlags[0].Description != long {
+		t.Error("generating should not modify the tool's descriptions")
+	}
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Nushell generates nushell completion scripts: an `export extern` signature
+// for the tool and each subcommand path, with flags declared as typed
+// parameters. Subcommands and flag values complete through custom completers
+// attached to those parameters.
+type Nushell struct {
+	dynamic map[string]string // "tool:kind" -> candidate command; nil disables dynamic completion
+}
+
+// NewNushell creates a new Nushell generator
+func NewNushell() *Nushell {
+	return &Nushell{}
+}
+
+// SetDynamicCompletions enables dynamic value completion using the given
+// "tool:kind" -> shell command table (see DefaultDynamicCompletions)
+func (n *Nushell) SetDynamicCompletions(dynamic map[string]string) {
+	n.dynamic = dynamic
+}
+
+// GenerateWithLimits creates a nushell completion script with bounds checking
+func (n *Nushell) GenerateWithLimits(tool *types.Tool) GenerateResult {
+	truncatedTool, warnings := truncateTool(tool)
+
+	script := n.Generate(truncatedTool)
+
+	script, sizeWarnings := checkOutputSize(script, tool.Name)
+	warnings = append(warnings, sizeWarnings...)
+
+	return GenerateResult{
+		Script:   script,
+		Warnings: warnings,
+	}
+}
+
+// Generate creates a nushell completion script for a tool
+func (n *Nushell) Generate(tool *types.Tool) string {
+	tool = prepareTool(tool)
+	var sb strings.Builder
+
+	writeHeader(&sb, "Nushell", tool)
+
+	n.writeExtern(&sb, tool, tool.Name, tool.Description, tool.Subcommands, tool.GlobalFlags)
+	n.writeCommandExterns(&sb, tool, tool.Name, tool.Subcommands)
+
+	return sb.String()
+}
+
+// writeCommandExterns writes an extern for every subcommand (and alias) path
+// below prefix. Global flags are declared on each, since most tools accept
+// them after the subcommand too.
+func (n *Nushell) writeCommandExterns(sb *strings.Builder, tool *types.Tool, prefix string, cmds []types.Command) {
+	for _, cmd := range cmds {
+		flags := append(append([]types.Flag{}, cmd.Flags...), tool.GlobalFlags...)
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			path := prefix + " " + name
+			n.writeExtern(sb, tool, path, cmd.Description, cmd.Subcommands, flags)
+			n.writeCommandExterns(sb, tool, path, cmd.Subcommands)
+		}
+	}
+}
+
+// writeExtern writes the completers for one command path followed by its
+// extern signature
+func (n *Nushell) writeExtern(sb *strings.Builder, tool *types.Tool, path, desc string, cmds []types.Command, flags []types.Flag) {
+	var params []string
+
+	if len(cmds) > 0 {
+		completer := nuCompleterName(path, "commands")
+		fmt.Fprintf(sb, "\ndef %s [] {\n    [\n", nuQuote(completer))
+		for _, cmd := range cmds {
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				fmt.Fprintf(sb, "        { value: %s, description: %s }\n", nuQuote(name), nuQuote(normalizeDescription(cmd.Description, MaxDescriptionLength)))
+			}
+		}
+		sb.WriteString("    ]\n}\n")
+		params = append(params, fmt.Sprintf("command?: string@%s", nuQuote(completer)))
+	}
+
+	usedLong := make(map[string]bool)
+	usedShort := make(map[string]bool)
+	for _, flag := range flags {
+		long, short := nuFlagNames(flag)
+		if (long == "" && short == "") || usedLong[long] || (long == "" && usedShort[short]) {
+			continue
+		}
+		if usedShort[short] {
+			short = ""
+		}
+		usedLong[long] = long != ""
+		usedShort[short] = short != ""
+
+		name := long
+		if name == "" {
+			name = short
+		}
+		param := name
+		if long != "" && short != "" {
+			param += "(" + short + ")"
+		}
+		if argType := n.argType(sb, tool, path, flag, name); argType != "" {
+			param += ": " + argType
+		}
+		if d := normalizeDescription(flag.Description, MaxDescriptionLength); d != "" {
+			param += "  # " + d
+		}
+		params = append(params, param)
+	}
+	params = append(params, "...args: string")
+
+	sb.WriteString("\n")
+	if d := normalizeDescription(desc, MaxDescriptionLength); d != "" {
+		fmt.Fprintf(sb, "# %s\n", d)
+	}
+	fmt.Fprintf(sb, "export extern %s [\n", nuQuote(path))
+	for _, param := range params {
+		fmt.Fprintf(sb, "    %s\n", param)
+	}
+	sb.WriteString("]\n")
+}
+
+// argType returns the parameter type for a flag's argument ("" for a switch),
+// writing a completer for its values when it has any. Optional arguments must
+// be attached with "=", so such flags are declared as switches.
+func (n *Nushell) argType(sb *strings.Builder, tool *types.Tool, path string, flag types.Flag, name string) string {
+	if flag.OptionalArg {
+		return ""
+	}
+	switch classifyArg(flag) {
+	case argNone:
+		return ""
+	case argValues:
+		completer := nuCompleterName(path, name)
+		quoted := make([]string, len(flag.ArgumentValues))
+		for i, v := range flag.ArgumentValues {
+			quoted[i] = nuQuote(v)
+		}
+		fmt.Fprintf(sb, "\ndef %s [] {\n    [%s]\n}\n", nuQuote(completer), strings.Join(quoted, " "))
+		return "string@" + nuQuote(completer)
+	case argDynamic:
+		command, ok := n.dynamic[dynamicKey(tool.Name, flag.Dynamic)]
+		if !ok {
+			return "string"
+		}
+		completer := nuCompleterName(path, name)
+		fmt.Fprintf(sb, "\ndef %s [] {\n    ^sh -c %s | lines\n}\n", nuQuote(completer), nuQuote(command))
+		return "string@" + nuQuote(completer)
+	case argFile:
+		return "path"
+	case argDir:
+		return "directory"
+	default:
+		return "string"
+	}
+}
+
+// nuFlagNames returns the long ("--name") and short ("-n") forms of a flag
+// that nushell can declare. Single-dash long flags ("-verbose") and extra
+// short aliases have no nushell equivalent and are left out; --help and -h
+// are declared by nushell itself.
+func nuFlagNames(flag types.Flag) (long, short string) {
+	for _, name := range []string{flag.Name, flag.Short} {
+		switch {
+		case strings.HasPrefix(name, "--") && name != "--help" && nuFlagWord(name[2:]):
+			if long == "" {
+				long = name
+			}
+		case len(name) == 2 && name[0] == '-' && name != "-h" && nuFlagWord(name[1:]):
+			if short == "" {
+				short = name
+			}
+		}
+	}
+	return long, short
+}
+
+// nuFlagWord reports whether s is usable as a nushell flag name
+func nuFlagWord(s string) bool {
+	if s == "" || s[0] == '-' {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// nuCompleterName names the custom completer for a command path's subcommands
+// or one of its flags
+func nuCompleterName(path, what string) string {
+	return "nu-complete " + path + " " + what
+}
+
+// nuQuote returns s as a nushell double-quoted string
+func nuQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
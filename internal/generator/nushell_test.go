@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestNewNushell(t *testing.T) {
+	if NewNushell() == nil {
+		t.Error("NewNushell() returned nil")
+	}
+}
+
+func TestNushell_Generate_Basic(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		Subcommands: []types.Command{
+			{
+				Name:        "build",
+				Aliases:     []string{"b"},
+				Description: "Build the \"project\"",
+				Flags:       []types.Flag{{Name: "--release", Short: "-r"}},
+				Subcommands: []types.Command{{Name: "docs"}},
+			},
+		},
+		GlobalFlags: []types.Flag{
+			{Name: "--verbose", Short: "-v", Description: "Verbose\n  output"},
+			{Name: "--format", Arg: "FMT", ArgumentValues: []string{"json", "yaml"}},
+			{Name: "--config", Arg: "FILE"},
+			{Name: "--out", Arg: "DIR"},
+			{Name: "--name", Arg: "NAME"},
+		},
+	}
+
+	script := NewNushell().Generate(tool)
+
+	for _, want := range []string{
+		"# Generated by TabGen",
+		"export extern \"mytool\" [\n    command?: string@\"nu-complete mytool commands\"\n",
+		"{ value: \"build\", description: \"Build the \\\"project\\\"\" }",
+		"{ value: \"b\", description: \"Build the \\\"project\\\"\" }",
+		"    --verbose(-v)  # Verbose output\n",
+		"def \"nu-complete mytool --format\" [] {\n    [\"json\" \"yaml\"]\n}",
+		"    --format: string@\"nu-complete mytool --format\"\n",
+		"    --config: path\n",
+		"    --out: directory\n",
+		"    --name: string\n",
+		"    ...args: string\n",
+		"# Build the \"project\"\nexport extern \"mytool build\" [",
+		"export extern \"mytool b\" [",
+		"export extern \"mytool build docs\" [",
+		"    --release(-r)\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+}
+
+func TestNushell_Generate_FlagNames(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		GlobalFlags: []types.Flag{
+			{Name: "--help", Short: "-h"},
+			{Name: "--human", Short: "-h"},
+			{Name: "-x"},
+			{Name: "-verbose"},
+			{Name: "--all", Short: "-a"},
+			{Name: "--also", Short: "-a"},
+			{Name: "--all"},
+			{Name: "--color", Arg: "WHEN", OptionalArg: true},
+		},
+	}
+
+	script := NewNushell().Generate(tool)
+
+	for _, want := range []string{"    --human\n", "    -x\n", "    --all(-a)\n", "    --also\n", "    --color\n"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+	for _, unwanted := range []string{"--help", "(-h)", "-verbose"} {
+		if strings.Contains(script, unwanted) {
+			t.Errorf("script should not declare %q\n%s", unwanted, script)
+		}
+	}
+	if strings.Count(script, "--all") != 1 {
+		t.Errorf("duplicate flag should be declared once\n%s", script)
+	}
+}
+
+func TestNushell_Generate_DynamicCompletions(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "git",
+		GlobalFlags: []types.Flag{{Name: "--branch", Arg: "BRANCH", Dynamic: "branch"}},
+	}
+
+	n := NewNushell()
+	if script := n.Generate(tool); !strings.Contains(script, "    --branch: string\n") {
+		t.Errorf("dynamic values should be off by default:\n%s", script)
+	}
+
+	n.SetDynamicCompletions(DefaultDynamicCompletions)
+	script := n.Generate(tool)
+	if !strings.Contains(script, "--branch: string@\"nu-complete git --branch\"") || !strings.Contains(script, "^sh -c ") {
+		t.Errorf("expected a dynamic completer for --branch:\n%s", script)
+	}
+}
@@ -0,0 +1,187 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Tcsh generates tcsh/csh completion scripts using the complete builtin.
+// complete rules match words by position or by the previous word, so flags
+// can't be scoped to a subcommand, only one level of nested subcommands is
+// completed, and descriptions and dynamic values are not shown.
+type Tcsh struct{}
+
+// NewTcsh creates a new Tcsh generator
+func NewTcsh() *Tcsh {
+	return &Tcsh{}
+}
+
+// GenerateWithLimits creates a tcsh completion script with bounds checking
+func (t *Tcsh) GenerateWithLimits(tool *types.Tool) GenerateResult {
+	truncatedTool, warnings := truncateTool(tool)
+
+	script := t.Generate(truncatedTool)
+
+	script, sizeWarnings := checkOutputSize(script, tool.Name)
+	warnings = append(warnings, sizeWarnings...)
+
+	return GenerateResult{
+		Script:   script,
+		Warnings: warnings,
+	}
+}
+
+// Generate creates a tcsh completion script for a tool
+func (t *Tcsh) Generate(tool *types.Tool) string {
+	tool = prepareTool(tool)
+	var rules []string
+
+	// Flag arguments: complete the word after the flag
+	flags := tcshFlags(tool)
+	for _, flag := range flags {
+		if flag.OptionalArg {
+			continue
+		}
+		for _, name := range flag.Names() {
+			if !isTcshWord(name) {
+				continue
+			}
+			switch classifyArg(flag) {
+			case argValues:
+				if words := tcshWords(flag.ArgumentValues); words != "" {
+					rules = append(rules, fmt.Sprintf("'n/%s/(%s)/'", name, words))
+				}
+			case argFile:
+				rules = append(rules, fmt.Sprintf("'n/%s/f/'", name))
+			case argDir:
+				rules = append(rules, fmt.Sprintf("'n/%s/d/'", name))
+			}
+		}
+	}
+
+	// Flags: long forms before short so "--" isn't caught by the "-" rule
+	var long, short []string
+	for _, flag := range flags {
+		if strings.HasPrefix(flag.Name, "--") {
+			long = append(long, strings.TrimPrefix(flag.Name, "--"))
+		} else if strings.HasPrefix(flag.Name, "-") {
+			short = append(short, strings.TrimPrefix(flag.Name, "-"))
+		}
+		for _, alias := range append([]string{flag.Short}, flag.ShortAliases...) {
+			if strings.HasPrefix(alias, "-") {
+				short = append(short, strings.TrimPrefix(alias, "-"))
+			}
+		}
+	}
+	if words := tcshWords(long); words != "" {
+		rules = append(rules, fmt.Sprintf("'c/--/(%s)/'", words))
+	}
+	if words := tcshWords(short); words != "" {
+		rules = append(rules, fmt.Sprintf("'c/-/(%s)/'", words))
+	}
+
+	// Subcommands in the first position, nested subcommands after their parent
+	if len(tool.Subcommands) > 0 {
+		var names []string
+		for _, cmd := range tool.Subcommands {
+			names = append(names, cmd.Name)
+			names = append(names, cmd.Aliases...)
+		}
+		rules = append(rules, fmt.Sprintf("'p/1/(%s)/'", tcshWords(names)))
+
+		for _, cmd := range tool.Subcommands {
+			if len(cmd.Subcommands) == 0 {
+				continue
+			}
+			var nested []string
+			for _, sub := range cmd.Subcommands {
+				nested = append(nested, sub.Name)
+				nested = append(nested, sub.Aliases...)
+			}
+			words := tcshWords(nested)
+			if words == "" {
+				continue
+			}
+			for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+				if isTcshWord(name) {
+					rules = append(rules, fmt.Sprintf("'n/%s/(%s)/'", name, words))
+				}
+			}
+		}
+	}
+
+	var sb strings.Builder
+	writeHeader(&sb, "tcsh", tool)
+	sb.WriteString("\n")
+	if len(rules) == 0 {
+		fmt.Fprintf(&sb, "complete %s 'p/*/f/'\n", tool.Name)
+		return sb.String()
+	}
+	fmt.Fprintf(&sb, "complete %s \\\n", tool.Name)
+	for i, rule := range rules {
+		if i < len(rules)-1 {
+			fmt.Fprintf(&sb, "    %s \\\n", rule)
+		} else {
+			fmt.Fprintf(&sb, "    %s\n", rule)
+		}
+	}
+	return sb.String()
+}
+
+// tcshFlags returns the global flags followed by every subcommand's flags,
+// deduplicated by name, since tcsh can't tell which subcommand is active
+func tcshFlags(tool *types.Tool) []types.Flag {
+	seen := make(map[string]bool)
+	var result []types.Flag
+	add := func(flags []types.Flag) {
+		for _, flag := range flags {
+			key := flag.Name + " " + flag.Short
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, flag)
+			}
+		}
+	}
+
+	add(tool.GlobalFlags)
+	var walk func(cmds []types.Command)
+	walk = func(cmds []types.Command) {
+		for _, cmd := range cmds {
+			add(cmd.Flags)
+			walk(cmd.Subcommands)
+		}
+	}
+	walk(tool.Subcommands)
+	return result
+}
+
+// tcshWords joins the words that are safe inside a complete word list,
+// dropping duplicates
+func tcshWords(words []string) string {
+	seen := make(map[string]bool)
+	var kept []string
+	for _, w := range words {
+		if w == "" || seen[w] || !isTcshWord(w) {
+			continue
+		}
+		seen[w] = true
+		kept = append(kept, w)
+	}
+	return strings.Join(kept, " ")
+}
+
+// isTcshWord reports whether w can appear in a complete rule unquoted. The
+// rule delimiter "/" and shell metacharacters are rejected.
+func isTcshWord(w string) bool {
+	for _, r := range w {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case strings.ContainsRune("-_.:=+,@%", r):
+		default:
+			return false
+		}
+	}
+	return w != ""
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestNewTcsh(t *testing.T) {
+	if NewTcsh() == nil {
+		t.Error("NewTcsh() returned nil")
+	}
+}
+
+func TestTcsh_Generate_Basic(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		Subcommands: []types.Command{
+			{
+				Name:        "build",
+				Aliases:     []string{"b"},
+				Flags:       []types.Flag{{Name: "--release"}},
+				Subcommands: []types.Command{{Name: "docs"}, {Name: "site"}},
+			},
+			{Name: "test"},
+		},
+		GlobalFlags: []types.Flag{
+			{Name: "--verbose", Short: "-v"},
+			{Name: "--format", Arg: "FMT", ArgumentValues: []string{"json", "yaml"}},
+			{Name: "--config", Arg: "FILE"},
+			{Name: "--color", Arg: "WHEN", OptionalArg: true, ArgumentValues: []string{"always", "never"}},
+		},
+	}
+
+	script := NewTcsh().Generate(tool)
+
+	for _, want := range []string{
+		"# Generated by TabGen",
+		"complete mytool \\",
+		"'p/1/(build b test)/'",
+		"'n/build/(docs site)/'",
+		"'n/b/(docs site)/'",
+		"'c/--/(verbose format config color release)/'",
+		"'c/-/(v)/'",
+		"'n/--format/(json yaml)/'",
+		"'n/--config/f/'",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+
+	// Optional values are attached with "=", so they aren't the next word
+	if strings.Contains(script, "n/--color/") {
+		t.Error("optional --color value should not be completed as the next word")
+	}
+
+	// The long flag rule must come first or "-" would match "--"
+	if strings.Index(script, "'c/--/") > strings.Index(script, "'c/-/") {
+		t.Error("expected c/--/ before c/-/")
+	}
+	if !strings.HasSuffix(script, "/'\n") {
+		t.Errorf("last rule should not have a line continuation:\n%s", script)
+	}
+}
+
+func TestTcsh_Generate_NoRules(t *testing.T) {
+	script := NewTcsh().Generate(&types.Tool{Name: "plain"})
+	if !strings.Contains(script, "complete plain 'p/*/f/'") {
+		t.Errorf("expected file completion fallback, got:\n%s", script)
+	}
+}
+
+func TestIsTcshWord(t *testing.T) {
+	for word, want := range map[string]bool{
+		"build":     true,
+		"--dry-run": true,
+		"a/b":       false,
+		"$(x)":      false,
+		"two words": false,
+		"":          false,
+	} {
+		if got := isTcshWord(word); got != want {
+			t.Errorf("isTcshWord(%q) = %v, want %v", word, got, want)
+		}
+	}
+}
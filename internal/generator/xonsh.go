@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Xonsh generates xonsh completion scripts: a contextual completer registered
+// with add_one_completer. It offers subcommands and flags with descriptions
+// and flag values; file and directory arguments are left to xonsh's own path
+// completer.
+type Xonsh struct {
+	dynamic map[string]string // "tool:kind" -> candidate command; nil disables dynamic completion
+}
+
+// NewXonsh creates a new Xonsh generator
+func NewXonsh() *Xonsh {
+	return &Xonsh{}
+}
+
+// SetDynamicCompletions enables dynamic value completion using the given
+// "tool:kind" -> shell command table (see DefaultDynamicCompletions)
+func (x *Xonsh) SetDynamicCompletions(dynamic map[string]string) {
+	x.dynamic = dynamic
+}
+
+// GenerateWithLimits creates a xonsh completion script with bounds checking
+func (x *Xonsh) GenerateWithLimits(tool *types.Tool) GenerateResult {
+	truncatedTool, warnings := truncateTool(tool)
+
+	script := x.Generate(truncatedTool)
+
+	script, sizeWarnings := checkOutputSize(script, tool.Name)
+	warnings = append(warnings, sizeWarnings...)
+
+	return GenerateResult{
+		Script:   script,
+		Warnings: warnings,
+	}
+}
+
+// Generate creates a xonsh completion script for a tool.
+// Like the elvish script, the completer walks the words typed so far to find
+// the current subcommand, then offers its subcommands and flags.
+func (x *Xonsh) Generate(tool *types.Tool) string {
+	tool = prepareTool(tool)
+	var sb strings.Builder
+	ident := xonshIdent(tool.Name)
+
+	// Candidates per subcommand path ("" is the top level), collected with
+	// the elvish helpers since both shells complete from the same tables
+	e := &Elvish{dynamic: x.dynamic}
+	candidates := make(map[string][][2]string)
+	args := elvishFlagArgs{
+		values:  make(map[string][]string),
+		dynamic: make(map[string]string),
+		files:   make(map[string]bool),
+	}
+	candidates[""] = e.candidates(tool, tool.Subcommands, tool.GlobalFlags, &args)
+	e.collectCommands(tool, "", tool.Subcommands, candidates, &args)
+
+	writeHeader(&sb, "Xonsh", tool)
+	sb.WriteString("\n")
+	sb.WriteString("import subprocess\n\n")
+	sb.WriteString("from xonsh.completers.completer import add_one_completer\n")
+	sb.WriteString("from xonsh.completers.tools import RichCompletion, contextual_command_completer_for\n\n")
+
+	fmt.Fprintf(&sb, "_tabgen_%s_commands = {\n", ident)
+	for _, path := range sortedKeys(candidates) {
+		fmt.Fprintf(&sb, "    %s: [\n", pyQuote(path))
+		for _, c := range candidates[path] {
+			fmt.Fprintf(&sb, "        (%s, %s),\n", pyQuote(c[0]), pyQuote(c[1]))
+		}
+		sb.WriteString("    ],\n")
+	}
+	sb.WriteString("}\n")
+
+	fmt.Fprintf(&sb, "_tabgen_%s_values = {\n", ident)
+	for _, name := range sortedKeys(args.values) {
+		quoted := make([]string, len(args.values[name]))
+		for i, v := range args.values[name] {
+			quoted[i] = pyQuote(v)
+		}
+		fmt.Fprintf(&sb, "    %s: [%s],\n", pyQuote(name), strings.Join(quoted, ", "))
+	}
+	sb.WriteString("}\n")
+
+	fmt.Fprintf(&sb, "_tabgen_%s_dynamic = {\n", ident)
+	for _, name := range sortedKeys(args.dynamic) {
+		fmt.Fprintf(&sb, "    %s: %s,\n", pyQuote(name), pyQuote(args.dynamic[name]))
+	}
+	sb.WriteString("}\n")
+
+	fmt.Fprintf(&sb, "_tabgen_%s_files = {\n", ident)
+	for _, name := range sortedKeys(args.files) {
+		fmt.Fprintf(&sb, "    %s,\n", pyQuote(name))
+	}
+	sb.WriteString("}\n\n\n")
+
+	fmt.Fprintf(&sb, "@contextual_command_completer_for(%s)\n", pyQuote(tool.Name))
+	fmt.Fprintf(&sb, "def _tabgen_complete_%s(command):\n", ident)
+	sb.WriteString("    words = [arg.value for arg in command.args[:command.arg_index]]\n")
+	sb.WriteString("    prefix = command.prefix\n")
+	sb.WriteString("    prev = words[-1] if len(words) > 1 else \"\"\n\n")
+
+	// Complete flag argument values first; returning None lets xonsh's path
+	// completer handle file arguments
+	fmt.Fprintf(&sb, "    if prev in _tabgen_%s_values:\n", ident)
+	fmt.Fprintf(&sb, "        return {v for v in _tabgen_%s_values[prev] if v.startswith(prefix)}\n", ident)
+	fmt.Fprintf(&sb, "    if prev in _tabgen_%s_dynamic:\n", ident)
+	fmt.Fprintf(&sb, "        out = subprocess.run([\"sh\", \"-c\", _tabgen_%s_dynamic[prev]], capture_output=True, text=True).stdout\n", ident)
+	sb.WriteString("        return {v for v in out.splitlines() if v.startswith(prefix)}\n")
+	fmt.Fprintf(&sb, "    if prev in _tabgen_%s_files:\n", ident)
+	sb.WriteString("        return None\n\n")
+
+	// Find the deepest subcommand named so far
+	sb.WriteString("    path = \"\"\n")
+	sb.WriteString("    for w in words[1:]:\n")
+	sb.WriteString("        key = (path + \" \" + w).strip()\n")
+	fmt.Fprintf(&sb, "        if not w.startswith(\"-\") and key in _tabgen_%s_commands:\n", ident)
+	sb.WriteString("            path = key\n")
+	sb.WriteString("    return {\n")
+	sb.WriteString("        RichCompletion(name, description=desc)\n")
+	fmt.Fprintf(&sb, "        for name, desc in _tabgen_%s_commands[path]\n", ident)
+	sb.WriteString("        if name.startswith(prefix)\n")
+	sb.WriteString("    }\n\n\n")
+
+	fmt.Fprintf(&sb, "add_one_completer(%s, _tabgen_complete_%s, \"start\")\n", pyQuote("tabgen_"+ident), ident)
+
+	return sb.String()
+}
+
+// xonshIdent converts a tool name into a Python identifier fragment
+func xonshIdent(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	return sb.String()
+}
+
+// pyQuote returns s as a Python string literal. Go's quoting escapes are a
+// subset of Python's, so strconv.Quote output is valid Python.
+func pyQuote(s string) string {
+	return strconv.Quote(s)
+}
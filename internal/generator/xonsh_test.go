@@ -0,0 +1,68 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestNewXonsh(t *testing.T) {
+	if NewXonsh() == nil {
+		t.Error("NewXonsh() returned nil")
+	}
+}
+
+func TestXonsh_Generate_Basic(t *testing.T) {
+	tool := &types.Tool{
+		Name: "my-tool",
+		Subcommands: []types.Command{
+			{
+				Name:        "build",
+				Description: "Build the \"project\"",
+				Flags:       []types.Flag{{Name: "--release"}},
+				Subcommands: []types.Command{{Name: "docs"}},
+			},
+		},
+		GlobalFlags: []types.Flag{
+			{Name: "--verbose", Short: "-v", Description: "Verbose\n  output"},
+			{Name: "--format", Arg: "FMT", ArgumentValues: []string{"json", "yaml"}},
+			{Name: "--config", Arg: "FILE"},
+		},
+	}
+
+	script := NewXonsh().Generate(tool)
+
+	for _, want := range []string{
+		"# Generated by TabGen",
+		"@contextual_command_completer_for(\"my-tool\")",
+		"def _tabgen_complete_my_tool(command):",
+		"(\"build\", \"Build the \\\"project\\\"\"),",
+		"(\"--verbose\", \"Verbose output\"),",
+		"\"build docs\": [",
+		"\"--format\": [\"json\", \"yaml\"],",
+		"_tabgen_my_tool_files = {\n    \"--config\",\n}",
+		"add_one_completer(\"tabgen_my_tool\", _tabgen_complete_my_tool, \"start\")",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q\n%s", want, script)
+		}
+	}
+}
+
+func TestXonsh_Generate_DynamicCompletions(t *testing.T) {
+	tool := &types.Tool{
+		Name:        "git",
+		GlobalFlags: []types.Flag{{Name: "--branch", Arg: "BRANCH", Dynamic: "branch"}},
+	}
+
+	x := NewXonsh()
+	if strings.Contains(x.Generate(tool), "\"--branch\": \"") {
+		t.Error("dynamic values should be off by default")
+	}
+
+	x.SetDynamicCompletions(DefaultDynamicCompletions)
+	if !strings.Contains(x.Generate(tool), "\"--branch\": \"") {
+		t.Errorf("expected a dynamic command for --branch:\n%s", x.Generate(tool))
+	}
+}
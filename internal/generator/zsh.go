@@ -2,19 +2,35 @@ package generator
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/jvalentini/tabgen/internal/types"
 )
 
 // Zsh generates zsh completion scripts
-type Zsh struct{}
+type Zsh struct {
+	dynamic map[string]string // "tool:kind" -> candidate command; nil disables dynamic completion
+	compact bool              // Omit flag and command descriptions
+}
 
 // NewZsh creates a new Zsh generator
 func NewZsh() *Zsh {
 	return &Zsh{}
 }
 
+// SetDynamicCompletions enables dynamic value completion using the given
+// "tool:kind" -> shell command table (see DefaultDynamicCompletions)
+func (z *Zsh) SetDynamicCompletions(dynamic map[string]string) {
+	z.dynamic = dynamic
+}
+
+// SetCompact makes the script list bare flags and commands without their
+// descriptions, for a quieter menu and a smaller script
+func (z *Zsh) SetCompact(compact bool) {
+	z.compact = compact
+}
+
 // GenerateWithLimits creates a zsh completion script with bounds checking
 func (z *Zsh) GenerateWithLimits(tool *types.Tool) GenerateResult {
 	// Apply truncation if needed
@@ -35,11 +51,12 @@ func (z *Zsh) GenerateWithLimits(tool *types.Tool) GenerateResult {
 
 // Generate creates a zsh completion script for a tool
 func (z *Zsh) Generate(tool *types.Tool) string {
+	tool = prepareTool(tool)
 	var sb strings.Builder
 
 	fmt.Fprintf(&sb, "#compdef %s\n", tool.Name)
-	fmt.Fprintf(&sb, "# Zsh completion for %s\n", tool.Name)
-	sb.WriteString("# Generated by TabGen\n\n")
+	writeHeader(&sb, "Zsh", tool)
+	sb.WriteString("\n")
 
 	funcName := zshFuncName(tool.Name)
 
@@ -47,15 +64,27 @@ func (z *Zsh) Generate(tool *types.Tool) string {
 	sb.WriteString("    local curcontext=\"$curcontext\" state line\n")
 	sb.WriteString("    typeset -A opt_args\n\n")
 
+	// Words after "--" are passed through to another command (as in
+	// "docker run IMAGE -- cmd"), so stop offering this tool's flags
+	sb.WriteString("    # Words after \"--\" are passed through: complete commands, then files\n")
+	sb.WriteString("    local dashdash=${words[1,CURRENT-1][(I)--]}\n")
+	sb.WriteString("    if (( dashdash > 1 )); then\n")
+	sb.WriteString("        if (( CURRENT == dashdash + 1 )); then\n")
+	sb.WriteString("            _alternative 'commands:command:_command_names -e' 'files:file:_files'\n")
+	sb.WriteString("        else\n")
+	sb.WriteString("            _files\n")
+	sb.WriteString("        fi\n")
+	sb.WriteString("        return\n")
+	sb.WriteString("    fi\n\n")
+
+	z.generateDynamicFunctions(&sb, tool)
+
 	// Build arguments spec
 	sb.WriteString("    _arguments -C \\\n")
 
 	// Global flags
-	for _, flag := range tool.GlobalFlags {
-		spec := z.formatFlagSpec(flag)
-		if spec != "" {
-			fmt.Fprintf(&sb, "        %s \\\n", spec)
-		}
+	for _, spec := range z.formatFlagSpecs(tool.GlobalFlags) {
+		fmt.Fprintf(&sb, "        %s \\\n", spec)
 	}
 
 	// Subcommands
@@ -67,15 +96,7 @@ func (z *Zsh) Generate(tool *types.Tool) string {
 		sb.WriteString("        commands)\n")
 		sb.WriteString("            local commands=(\n")
 		for _, cmd := range tool.Subcommands {
-			desc := escapeZshDesc(cmd.Description)
-			if desc == "" {
-				desc = cmd.Name
-			}
-			fmt.Fprintf(&sb, "                '%s:%s'\n", cmd.Name, desc)
-			// Include aliases as completable names
-			for _, alias := range cmd.Aliases {
-				fmt.Fprintf(&sb, "                '%s:%s (alias for %s)'\n", alias, desc, cmd.Name)
-			}
+			z.writeCommandEntries(&sb, "                ", cmd)
 		}
 		sb.WriteString("            )\n")
 		sb.WriteString("            _describe 'command' commands\n")
@@ -84,7 +105,7 @@ func (z *Zsh) Generate(tool *types.Tool) string {
 		sb.WriteString("        args)\n")
 		sb.WriteString("            case $words[1] in\n")
 		for _, cmd := range tool.Subcommands {
-			z.generateZshSubcommandCase(&sb, cmd, true)
+			z.generateZshSubcommandCase(&sb, cmd, tool.GlobalFlags, true)
 		}
 		sb.WriteString("                *)\n")
 		sb.WriteString("                    _files\n")
@@ -104,10 +125,14 @@ func (z *Zsh) Generate(tool *types.Tool) string {
 	return sb.String()
 }
 
-// generateZshSubcommandCase generates a case entry for a subcommand
-func (z *Zsh) generateZshSubcommandCase(sb *strings.Builder, cmd types.Command, includeAliases bool) {
+// generateZshSubcommandCase generates a case entry for a subcommand. The
+// tool's global flags are completed alongside each subcommand's own, since
+// "*::arg:->args" leaves the top-level specs behind.
+func (z *Zsh) generateZshSubcommandCase(sb *strings.Builder, cmd types.Command, global []types.Flag, includeAliases bool) {
+	flags := withGlobalFlags(cmd.Flags, global)
+
 	// Skip if no flags and no nested subcommands
-	if len(cmd.Flags) == 0 && len(cmd.Subcommands) == 0 {
+	if len(flags) == 0 && len(cmd.Subcommands) == 0 {
 		return
 	}
 
@@ -122,7 +147,7 @@ func (z *Zsh) generateZshSubcommandCase(sb *strings.Builder, cmd types.Command,
 		// Has nested subcommands
 		sb.WriteString("                    case $words[2] in\n")
 		for _, sub := range cmd.Subcommands {
-			if len(sub.Flags) > 0 {
+			if subFlags := withGlobalFlags(sub.Flags, global); len(subFlags) > 0 {
 				// Build pattern matching name and aliases
 				subPattern := sub.Name
 				if len(sub.Aliases) > 0 {
@@ -130,11 +155,8 @@ func (z *Zsh) generateZshSubcommandCase(sb *strings.Builder, cmd types.Command,
 				}
 				fmt.Fprintf(sb, "                        %s)\n", subPattern)
 				sb.WriteString("                            _arguments \\\n")
-				for _, flag := range sub.Flags {
-					spec := z.formatFlagSpec(flag)
-					if spec != "" {
-						fmt.Fprintf(sb, "                                %s \\\n", spec)
-					}
+				for _, spec := range z.formatFlagSpecs(subFlags) {
+					fmt.Fprintf(sb, "                                %s \\\n", spec)
 				}
 				sb.WriteString("                                '*:file:_files'\n")
 				sb.WriteString("                            ;;\n")
@@ -144,34 +166,75 @@ func (z *Zsh) generateZshSubcommandCase(sb *strings.Builder, cmd types.Command,
 		// Complete nested subcommands
 		sb.WriteString("                            local subcommands=(\n")
 		for _, sub := range cmd.Subcommands {
-			desc := escapeZshDesc(sub.Description)
-			if desc == "" {
-				desc = sub.Name
-			}
-			fmt.Fprintf(sb, "                                '%s:%s'\n", sub.Name, desc)
-			// Include aliases
-			for _, alias := range sub.Aliases {
-				fmt.Fprintf(sb, "                                '%s:%s (alias for %s)'\n", alias, desc, sub.Name)
-			}
+			z.writeCommandEntries(sb, "                                ", sub)
 		}
 		sb.WriteString("                            )\n")
-		sb.WriteString("                            _describe 'subcommand' subcommands\n")
+		if len(flags) == 0 {
+			sb.WriteString("                            _describe 'subcommand' subcommands\n")
+		} else {
+			// The command's own and global flags, then its subcommands
+			sb.WriteString("                            _arguments \\\n")
+			for _, spec := range z.formatFlagSpecs(flags) {
+				fmt.Fprintf(sb, "                                %s \\\n", spec)
+			}
+			sb.WriteString("                                '1:subcommand:{_describe subcommand subcommands}' \\\n")
+			sb.WriteString("                                '*:file:_files'\n")
+		}
 		sb.WriteString("                            ;;\n")
 		sb.WriteString("                    esac\n")
 	} else {
 		// Just flags
 		sb.WriteString("                    _arguments \\\n")
-		for _, flag := range cmd.Flags {
-			spec := z.formatFlagSpec(flag)
-			if spec != "" {
-				fmt.Fprintf(sb, "                        %s \\\n", spec)
-			}
+		for _, spec := range z.formatFlagSpecs(flags) {
+			fmt.Fprintf(sb, "                        %s \\\n", spec)
 		}
 		sb.WriteString("                        '*:file:_files'\n")
 	}
 	sb.WriteString("                    ;;\n")
 }
 
+// withGlobalFlags returns a command's flags followed by the global flags it
+// doesn't redefine under any of the same forms
+func withGlobalFlags(flags, global []types.Flag) []types.Flag {
+	if len(global) == 0 {
+		return flags
+	}
+	own := make(map[string]bool)
+	for _, flag := range flags {
+		for _, form := range zshFlagForms(flag) {
+			own[form] = true
+		}
+	}
+	merged := slices.Clone(flags)
+	for _, flag := range global {
+		if !slices.ContainsFunc(zshFlagForms(flag), func(form string) bool { return own[form] }) {
+			merged = append(merged, flag)
+		}
+	}
+	return merged
+}
+
+// writeCommandEntries writes the _describe entries for a command and its
+// aliases, as "name:description" unless the script is compact
+func (z *Zsh) writeCommandEntries(sb *strings.Builder, indent string, cmd types.Command) {
+	if z.compact {
+		for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+			fmt.Fprintf(sb, "%s'%s'\n", indent, name)
+		}
+		return
+	}
+
+	desc := escapeZshDesc(normalizeDescription(cmd.Description, MaxDescriptionLength))
+	if desc == "" {
+		desc = cmd.Name
+	}
+	fmt.Fprintf(sb, "%s'%s:%s'\n", indent, cmd.Name, desc)
+	// Include aliases as completable names
+	for _, alias := range cmd.Aliases {
+		fmt.Fprintf(sb, "%s'%s:%s (alias for %s)'\n", indent, alias, desc, cmd.Name)
+	}
+}
+
 // escapeZshDesc escapes special characters in descriptions
 func escapeZshDesc(desc string) string {
 	desc = strings.ReplaceAll(desc, "'", "'\\''")
@@ -179,78 +242,207 @@ func escapeZshDesc(desc string) string {
 	return desc
 }
 
+// zshValueWord quotes a value for a "(...)" action list inside a
+// single-quoted spec. The list is split into words and expanded when
+// completing, so characters other than the safe ones are backslash-escaped,
+// as compgenWord quotes them for bash; then the spec's own quoting applies.
+func zshValueWord(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./:=@%+,", r)) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return escapeZshDesc(sb.String())
+}
+
+// formatFlagSpecs creates _arguments specs for a set of sibling flags,
+// adding exclusion lists for flags that share an ExclusiveGroup
+func (z *Zsh) formatFlagSpecs(flags []types.Flag) []string {
+	groups := make(map[string][]string)
+	for _, flag := range flags {
+		if flag.ExclusiveGroup == "" {
+			continue
+		}
+		groups[flag.ExclusiveGroup] = append(groups[flag.ExclusiveGroup], zshFlagForms(flag)...)
+	}
+
+	specs := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		if spec := z.formatFlagSpecExcluding(flag, groups[flag.ExclusiveGroup]); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
 // formatFlagSpec creates a zsh _arguments spec for a flag
 func (z *Zsh) formatFlagSpec(flag types.Flag) string {
+	return z.formatFlagSpecExcluding(flag, nil)
+}
+
+// formatFlagSpecExcluding creates a zsh _arguments spec for a flag that is
+// mutually exclusive with the named flags
+func (z *Zsh) formatFlagSpecExcluding(flag types.Flag, exclusive []string) string {
 	if flag.Name == "" && flag.Short == "" {
 		return ""
 	}
 
-	desc := flag.Description
-	if desc == "" {
-		desc = flag.Name
+	// "[description]", left out of compact scripts
+	desc := ""
+	if !z.compact {
+		desc = normalizeDescription(flag.Description, MaxDescriptionLength)
+		if desc == "" {
+			desc = flag.Name
+		}
+		// Escape special chars
+		desc = strings.ReplaceAll(desc, "'", "'\\''")
+		desc = strings.ReplaceAll(desc, "[", "\\[")
+		desc = strings.ReplaceAll(desc, "]", "\\]")
+		desc = "[" + desc + "]"
 	}
-	// Escape special chars
-	desc = strings.ReplaceAll(desc, "'", "'\\''")
-	desc = strings.ReplaceAll(desc, "[", "\\[")
-	desc = strings.ReplaceAll(desc, "]", "\\]")
 
 	// Build argument completion part
 	argCompletion := z.formatArgCompletion(flag)
 
+	// An optional value can only be attached: --flag=value
+	name := flag.Name
+	if flag.OptionalArg && argCompletion != "" && strings.HasPrefix(name, "--") {
+		name += "=-"
+	}
+
 	var spec string
+	forms := zshFlagForms(flag)
 
-	// Handle both short and long forms
-	if flag.Short != "" && flag.Name != "" {
-		// Both short and long
-		if argCompletion != "" {
-			spec = fmt.Sprintf("'(%s %s)'{%s,%s}'[%s]%s",
-				flag.Short, flag.Name, flag.Short, flag.Name, desc, argCompletion)
-		} else {
-			spec = fmt.Sprintf("'(%s %s)'{%s,%s}'[%s]'",
-				flag.Short, flag.Name, flag.Short, flag.Name, desc)
+	// Exclusion list: the flag's own forms plus any exclusive siblings. A
+	// repeatable flag doesn't exclude itself, and "*" lets it be completed again.
+	var exclusions []string
+	if len(forms) > 1 && !flag.Repeatable {
+		exclusions = append(exclusions, forms...)
+	}
+	for _, name := range exclusive {
+		if !slices.Contains(exclusions, name) {
+			exclusions = append(exclusions, name)
+		}
+	}
+	exclusionList := strings.Join(exclusions, " ")
+	prefix := ""
+	if exclusionList != "" {
+		prefix = "(" + exclusionList + ")"
+	}
+	if flag.Repeatable {
+		prefix += "*"
+	}
+
+	// The long form takes the optional value suffix when there is an argument
+	if argCompletion != "" {
+		for i, form := range forms {
+			if form == flag.Name {
+				forms[i] = name
+			}
+		}
+	}
+
+	if len(forms) > 1 {
+		// Several forms share one spec via brace expansion
+		words := make([]string, len(forms))
+		for i, form := range forms {
+			words[i] = zshBraceWord(form)
 		}
-	} else if flag.Name != "" {
-		// Long only
 		if argCompletion != "" {
-			spec = fmt.Sprintf("'%s[%s]%s", flag.Name, desc, argCompletion)
+			spec = fmt.Sprintf("'%s'{%s}'%s%s",
+				prefix, strings.Join(words, ","), desc, argCompletion)
 		} else {
-			spec = fmt.Sprintf("'%s[%s]'", flag.Name, desc)
+			spec = fmt.Sprintf("'%s'{%s}'%s'",
+				prefix, strings.Join(words, ","), desc)
 		}
 	} else {
-		// Short only
+		// A single form
 		if argCompletion != "" {
-			spec = fmt.Sprintf("'%s[%s]%s", flag.Short, desc, argCompletion)
+			spec = fmt.Sprintf("'%s%s%s%s", prefix, forms[0], desc, argCompletion)
 		} else {
-			spec = fmt.Sprintf("'%s[%s]'", flag.Short, desc)
+			spec = fmt.Sprintf("'%s%s%s'", prefix, forms[0], desc)
 		}
 	}
 
 	return spec
 }
 
+// zshFlagForms returns a flag's spellings: Short, Name, then ShortAliases
+func zshFlagForms(flag types.Flag) []string {
+	var forms []string
+	if flag.Short != "" {
+		forms = append(forms, flag.Short)
+	}
+	if flag.Name != "" {
+		forms = append(forms, flag.Name)
+	}
+	return append(forms, flag.ShortAliases...)
+}
+
+// zshBraceWord quotes a flag form for an unquoted brace list, where glob
+// characters such as "?" would otherwise be expanded
+func zshBraceWord(form string) string {
+	if strings.ContainsAny(form, "?*[]#~^") {
+		return "'" + form + "'"
+	}
+	return form
+}
+
 // formatArgCompletion builds the argument completion portion of a zsh spec
 func (z *Zsh) formatArgCompletion(flag types.Flag) string {
 	if flag.Arg == "" && len(flag.ArgumentValues) == 0 {
 		return ""
 	}
 
-	argName := flag.Arg
+	// The name is the message zsh shows; a ":" in it would end the field
+	argName := escapeZshDesc(flag.Arg)
 	if argName == "" {
 		argName = "value"
 	}
+	// A leading "::" marks the argument as optional
+	if flag.OptionalArg {
+		argName = ":" + argName
+	}
 
-	if len(flag.ArgumentValues) > 0 {
+	switch classifyArg(flag) {
+	case argValues:
 		// Use specific values: :arg:(val1 val2 val3)'
-		values := strings.Join(flag.ArgumentValues, " ")
-		return fmt.Sprintf(":%s:(%s)'", argName, values)
+		words := make([]string, len(flag.ArgumentValues))
+		for i, value := range flag.ArgumentValues {
+			words[i] = zshValueWord(value)
+		}
+		return fmt.Sprintf(":%s:(%s)'", argName, strings.Join(words, " "))
+	case argDynamic:
+		if z.dynamic != nil {
+			// Completed by a helper defined at the top of the completion function
+			return fmt.Sprintf(":%s:%s'", argName, zshDynamicFuncName(flag.Dynamic))
+		}
+	case argFile:
+		return fmt.Sprintf(":%s:_files'", argName)
+	case argDir:
+		return fmt.Sprintf(":%s:_files -/'", argName)
 	}
 
-	// No specific values, use generic arg placeholder: :arg:'
+	// Opaque value: an empty action shows the name as a message and completes nothing
 	return fmt.Sprintf(":%s:'", argName)
 }
 
-// zshFuncName creates a valid zsh function name from a tool name
+// zshFuncName returns the completion function for a tool. zsh autoloads the
+// file "_<name>" as the function "_<name>", so the script must define and call
+// that same function: the first completion then replaces the autoload stub with
+// the real function instead of redefining a helper on every completion. Names
+// zsh can't use as a function name fall back to a sanitized helper.
 func zshFuncName(name string) string {
+	safe := name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || strings.ContainsRune("_-.+", r))
+	}) < 0
+	if safe {
+		return "_" + name
+	}
+
 	// Replace non-alphanumeric chars with underscore
 	clean := strings.Map(func(r rune) rune {
 		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
@@ -260,3 +452,29 @@ func zshFuncName(name string) string {
 	}, name)
 	return "_tabgen_" + clean
 }
+
+// generateDynamicFunctions defines a helper per dynamic value kind used by the tool.
+// Helpers are (re)defined each time the completion function runs, so the generic
+// names always refer to the current tool's commands.
+func (z *Zsh) generateDynamicFunctions(sb *strings.Builder, tool *types.Tool) {
+	if z.dynamic == nil {
+		return
+	}
+
+	for _, kind := range dynamicKinds(tool) {
+		fmt.Fprintf(sb, "    %s() {\n", zshDynamicFuncName(kind))
+		if command, ok := z.dynamic[dynamicKey(tool.Name, kind)]; ok {
+			sb.WriteString("        local -a values\n")
+			fmt.Fprintf(sb, "        values=(${(f)\"$(%s)\"})\n", command)
+			sb.WriteString("        compadd -a values\n")
+		} else {
+			fmt.Fprintf(sb, "        _message '%s'\n", kind)
+		}
+		sb.WriteString("    }\n\n")
+	}
+}
+
+// zshDynamicFuncName names the helper that completes a dynamic value kind
+func zshDynamicFuncName(kind string) string {
+	return "_tabgen_dynamic_" + kind
+}
@@ -53,6 +53,28 @@ func TestEscapeZshDesc(t *testing.T) {
 	}
 }
 
+func TestZshValueWord(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"json", "json"},
+		{"a b", `a\ b`},
+		{"$x", `\$x`},
+		{"`id`", "\\`id\\`"},
+		{"(x)", `\(x\)`},
+		{`a\b`, `a\\b`},
+		{"host:port", `host\:port`},
+		{"it's", `it\'\''s`},
+	}
+
+	for _, tt := range tests {
+		if got := zshValueWord(tt.input); got != tt.want {
+			t.Errorf("zshValueWord(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
 func TestZsh_Generate_SubcommandEmptyDescription(t *testing.T) {
 	z := NewZsh()
 	tool := &types.Tool{
@@ -87,7 +109,7 @@ func TestZsh_Generate_Basic(t *testing.T) {
 	if !strings.Contains(output, "#compdef mytool") {
 		t.Error("expected #compdef header")
 	}
-	if !strings.Contains(output, "_tabgen_mytool") {
+	if !strings.Contains(output, "_mytool() {") {
 		t.Error("expected function name")
 	}
 	if !strings.Contains(output, "init") {
@@ -95,6 +117,57 @@ func TestZsh_Generate_Basic(t *testing.T) {
 	}
 }
 
+func TestZsh_Generate_GlobalFlagsInSubcommands(t *testing.T) {
+	z := NewZsh()
+	tool := &types.Tool{
+		Name: "mytool",
+		GlobalFlags: []types.Flag{
+			{Name: "--verbose", Short: "-v", Description: "Enable verbose"},
+			{Name: "--output", Description: "Output format"},
+		},
+		Subcommands: []types.Command{
+			{Name: "init", Description: "Initialize project"},
+			{Name: "build", Flags: []types.Flag{{Name: "--output", Short: "-o", Description: "Build directory"}}},
+			{
+				Name:        "remote",
+				Flags:       []types.Flag{{Name: "--force", Description: "Force it"}},
+				Subcommands: []types.Command{{Name: "add"}},
+			},
+		},
+	}
+
+	output := z.Generate(tool)
+
+	block := func(pattern string) string {
+		start := strings.Index(output, pattern)
+		if start < 0 {
+			t.Fatalf("no %q block in:\n%s", pattern, output)
+		}
+		rest := output[start:]
+		return rest[:strings.Index(rest, ";;")]
+	}
+
+	verbose := "'(-v --verbose)'{-v,--verbose}'[Enable verbose]'"
+	for _, pattern := range []string{"init)", "build)", "add)"} {
+		if !strings.Contains(block(pattern), verbose) {
+			t.Errorf("expected --verbose in the %s block:\n%s", pattern, block(pattern))
+		}
+	}
+	// remote has children: its fallback branch completes flags and subcommands
+	remote := block("*)\n                            local subcommands=(")
+	for _, want := range []string{verbose, "'--force[Force it]'", "'1:subcommand:{_describe subcommand subcommands}'"} {
+		if !strings.Contains(remote, want) {
+			t.Errorf("expected %s in the remote block:\n%s", want, remote)
+		}
+	}
+
+	// build's own --output replaces the global one
+	build := block("build)")
+	if !strings.Contains(build, "Build directory") || strings.Contains(build, "Output format") {
+		t.Errorf("expected only build's own --output:\n%s", build)
+	}
+}
+
 func TestZsh_FormatFlagSpec_WithArgumentValues(t *testing.T) {
 	z := NewZsh()
 
@@ -124,6 +197,15 @@ func TestZsh_FormatFlagSpec_WithArgumentValues(t *testing.T) {
 			},
 			wantPart: ":format:(text binary)",
 		},
+		{
+			name: "values with spaces and shell characters",
+			flag: types.Flag{
+				Name:           "--fmt",
+				Arg:            "FMT",
+				ArgumentValues: []string{"json", "a b", "$x"},
+			},
+			wantPart: `:FMT:(json a\ b \$x)'`,
+		},
 		{
 			name: "flag without argument values",
 			flag: types.Flag{
@@ -131,7 +213,16 @@ func TestZsh_FormatFlagSpec_WithArgumentValues(t *testing.T) {
 				Arg:         "file",
 				Description: "Config file",
 			},
-			wantPart: ":file:'",
+			wantPart: ":file:_files'",
+		},
+		{
+			name: "flag with opaque value",
+			flag: types.Flag{
+				Name:        "--jobs",
+				Arg:         "N",
+				Description: "Parallel jobs",
+			},
+			wantPart: "--jobs[Parallel jobs]:N:'",
 		},
 		{
 			name: "boolean flag",
@@ -153,6 +244,29 @@ func TestZsh_FormatFlagSpec_WithArgumentValues(t *testing.T) {
 	}
 }
 
+func TestZsh_FormatFlagSpecs_ExclusiveGroup(t *testing.T) {
+	z := NewZsh()
+	flags := []types.Flag{
+		{Name: "--json", Description: "Output format", ExclusiveGroup: "--json|--yaml"},
+		{Name: "--yaml", Description: "Output format", ExclusiveGroup: "--json|--yaml"},
+		{Name: "--verbose", Short: "-v", Description: "Be verbose"},
+	}
+
+	specs := z.formatFlagSpecs(flags)
+	if len(specs) != 3 {
+		t.Fatalf("expected 3 specs, got %d", len(specs))
+	}
+	if specs[0] != "'(--json --yaml)--json[Output format]'" {
+		t.Errorf("unexpected --json spec: %s", specs[0])
+	}
+	if specs[1] != "'(--json --yaml)--yaml[Output format]'" {
+		t.Errorf("unexpected --yaml spec: %s", specs[1])
+	}
+	if specs[2] != "'(-v --verbose)'{-v,--verbose}'[Be verbose]'" {
+		t.Errorf("ungrouped flag spec changed: %s", specs[2])
+	}
+}
+
 func TestZsh_FormatArgCompletion(t *testing.T) {
 	z := NewZsh()
 
@@ -172,9 +286,34 @@ func TestZsh_FormatArgCompletion(t *testing.T) {
 			want: ":value:(a b c)'",
 		},
 		{
-			name: "arg only",
+			name: "file arg",
 			flag: types.Flag{Arg: "file"},
-			want: ":file:'",
+			want: ":file:_files'",
+		},
+		{
+			name: "bracketed path arg",
+			flag: types.Flag{Arg: "<PATH>"},
+			want: ":<PATH>:_files'",
+		},
+		{
+			name: "directory arg",
+			flag: types.Flag{Arg: "DIR"},
+			want: ":DIR:_files -/'",
+		},
+		{
+			name: "opaque value",
+			flag: types.Flag{Arg: "N"},
+			want: ":N:'",
+		},
+		{
+			name: "colon in arg name",
+			flag: types.Flag{Arg: "host:port"},
+			want: ":host\\:port:'",
+		},
+		{
+			name: "optional",
+			flag: types.Flag{Arg: "WHEN", OptionalArg: true},
+			want: "::WHEN:'",
 		},
 		{
 			name: "empty",
@@ -193,6 +332,47 @@ func TestZsh_FormatArgCompletion(t *testing.T) {
 	}
 }
 
+func TestZsh_FormatFlagSpec_OptionalValue(t *testing.T) {
+	z := NewZsh()
+
+	long := z.formatFlagSpec(types.Flag{
+		Name: "--color", Arg: "WHEN", OptionalArg: true,
+		ArgumentValues: []string{"always", "never", "auto"}, Description: "colorize output",
+	})
+	if want := "'--color=-[colorize output]::WHEN:(always never auto)'"; long != want {
+		t.Errorf("got %s, want %s", long, want)
+	}
+
+	both := z.formatFlagSpec(types.Flag{Name: "--color", Short: "-c", Arg: "WHEN", OptionalArg: true})
+	if want := "'(-c --color)'{-c,--color=-}'[--color]::WHEN:'"; both != want {
+		t.Errorf("got %s, want %s", both, want)
+	}
+}
+
+func TestZsh_FormatFlagSpec_ShortAliases(t *testing.T) {
+	z := NewZsh()
+
+	spec := z.formatFlagSpec(types.Flag{Name: "-h", ShortAliases: []string{"-?"}, Description: "Show help"})
+	want := `'(-h -?)'{-h,'-?'}'[Show help]'`
+	if spec != want {
+		t.Errorf("got %s, want %s", spec, want)
+	}
+}
+
+func TestZsh_FormatFlagSpec_Repeatable(t *testing.T) {
+	z := NewZsh()
+
+	both := z.formatFlagSpec(types.Flag{Name: "--include", Short: "-I", Arg: "DIR", Repeatable: true, Description: "Add a directory"})
+	if !strings.HasPrefix(both, `'*'{-I,--include}'[Add a directory]`) {
+		t.Errorf("expected a repeatable spec without self-exclusion, got %s", both)
+	}
+
+	long := z.formatFlagSpec(types.Flag{Name: "--tag", Repeatable: true, Description: "Add a tag"})
+	if long != `'*--tag[Add a tag]'` {
+		t.Errorf("got %s", long)
+	}
+}
+
 func TestZsh_Generate_WithArgumentValues(t *testing.T) {
 	z := NewZsh()
 	tool := &types.Tool{
@@ -221,10 +401,13 @@ func TestZsh_ZshFuncName(t *testing.T) {
 		input string
 		want  string
 	}{
-		{"mytool", "_tabgen_mytool"},
-		{"my-tool", "_tabgen_my_tool"},
-		{"my.tool", "_tabgen_my_tool"},
-		{"123tool", "_tabgen_123tool"},
+		{"mytool", "_mytool"},
+		{"my-tool", "_my-tool"},
+		{"my.tool", "_my.tool"},
+		{"g++", "_g++"},
+		{"123tool", "_123tool"},
+		{"my tool", "_tabgen_my_tool"},
+		{"tool$", "_tabgen_tool_"},
 	}
 
 	for _, tt := range tests {
@@ -236,3 +419,195 @@ func TestZsh_ZshFuncName(t *testing.T) {
 		})
 	}
 }
+
+func TestZsh_Generate_DefinesCompdefEntryPoint(t *testing.T) {
+	z := NewZsh()
+	for _, name := range []string{"mytool", "my-tool", "python3.12"} {
+		t.Run(name, func(t *testing.T) {
+			tool := &types.Tool{
+				Name:        name,
+				GlobalFlags: []types.Flag{{Name: "--verbose"}},
+			}
+			output := z.Generate(tool)
+
+			// zsh autoloads the file _<name> (as SaveZshCompletion names it) as
+			// the function _<name> and calls it for each command on the
+			// #compdef line, so that function must be defined and invoked
+			firstLine, _, _ := strings.Cut(output, "\n")
+			commands := strings.Fields(strings.TrimPrefix(firstLine, "#compdef "))
+			if !strings.HasPrefix(firstLine, "#compdef ") || len(commands) != 1 || commands[0] != name {
+				t.Fatalf("first line = %q, want #compdef %s", firstLine, name)
+			}
+			entry := "_" + commands[0]
+			if !strings.Contains(output, "\n"+entry+"() {\n") {
+				t.Errorf("script does not define %s:\n%s", entry, output)
+			}
+			if !strings.HasSuffix(output, "\n"+entry+" \"$@\"\n") {
+				t.Errorf("script does not end by calling %s:\n%s", entry, output)
+			}
+		})
+	}
+}
+
+func TestZsh_Generate_DynamicCompletions(t *testing.T) {
+	tool := &types.Tool{
+		Name: "kubectl",
+		GlobalFlags: []types.Flag{
+			{Name: "--namespace", Short: "-n", Arg: "NAMESPACE", Dynamic: "namespace"},
+			{Name: "--branch", Arg: "BRANCH", Dynamic: "branch"},
+		},
+	}
+
+	z := NewZsh()
+	if script := z.Generate(tool); strings.Contains(script, "_tabgen_dynamic_") {
+		t.Error("dynamic helpers should not be emitted unless enabled")
+	}
+
+	z.SetDynamicCompletions(DefaultDynamicCompletions)
+	script := z.Generate(tool)
+	for _, want := range []string{
+		"_tabgen_dynamic_namespace() {",
+		`values=(${(f)"$(kubectl get namespaces -o name 2>/dev/null | cut -d/ -f2)"})`,
+		":NAMESPACE:_tabgen_dynamic_namespace'",
+		"_message 'branch'", // kind without a command for this tool
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script missing %q", want)
+		}
+	}
+}
+
+func TestZsh_Generate_PassthroughGuard(t *testing.T) {
+	z := NewZsh()
+	tool := &types.Tool{
+		Name:        "kubectl",
+		GlobalFlags: []types.Flag{{Name: "--namespace"}},
+		Subcommands: []types.Command{{Name: "exec", Flags: []types.Flag{{Name: "--stdin"}}}},
+	}
+
+	output := z.Generate(tool)
+
+	guard := strings.Index(output, "local dashdash=${words[1,CURRENT-1][(I)--]}")
+	if guard < 0 {
+		t.Fatalf("missing -- guard:\n%s", output)
+	}
+	if !strings.Contains(output, "_command_names -e") {
+		t.Error("expected command completion right after --")
+	}
+	if args := strings.Index(output, "_arguments -C"); args < guard {
+		t.Error("-- guard should run before _arguments")
+	}
+}
+
+func TestZsh_Generate_CompletionStyle(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		GlobalFlags: []types.Flag{
+			{Name: "--verbose", Short: "-v", Description: "Enable verbose output"},
+			{Name: "--format", Arg: "FORMAT", ArgumentValues: []string{"json", "yaml"}, Description: "Output format"},
+		},
+		Subcommands: []types.Command{
+			{Name: "deploy", Aliases: []string{"d"}, Description: "Deploy the app", Subcommands: []types.Command{
+				{Name: "rollback", Description: "Undo the last deploy"},
+			}},
+		},
+	}
+
+	verbose := NewZsh().Generate(tool)
+	for _, want := range []string{
+		"'(-v --verbose)'{-v,--verbose}'[Enable verbose output]'",
+		"'--format[Output format]:FORMAT:(json yaml)'",
+		"'deploy:Deploy the app'",
+		"'d:Deploy the app (alias for deploy)'",
+		"'rollback:Undo the last deploy'",
+	} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("verbose output missing %q", want)
+		}
+	}
+
+	z := NewZsh()
+	z.SetCompact(true)
+	compact := z.Generate(tool)
+	for _, want := range []string{
+		"'(-v --verbose)'{-v,--verbose}''",
+		"'--format:FORMAT:(json yaml)'",
+		"                'deploy'\n",
+		"                'd'\n",
+		"'rollback'\n",
+	} {
+		if !strings.Contains(compact, want) {
+			t.Errorf("compact output missing %q", want)
+		}
+	}
+	for _, desc := range []string{"Enable verbose output", "Output format", "Deploy the app", "Undo the last deploy"} {
+		if strings.Contains(compact, desc) {
+			t.Errorf("compact output should not contain description %q", desc)
+		}
+	}
+	if len(compact) >= len(verbose) {
+		t.Errorf("compact script (%d bytes) should be smaller than verbose (%d bytes)", len(compact), len(verbose))
+	}
+}
+
+func TestZsh_Generate_TwoCharAlias(t *testing.T) {
+	tool := &types.Tool{
+		Name: "pkg",
+		Subcommands: []types.Command{
+			{Name: "install", Aliases: []string{"in"}, Description: "Install a package", Flags: []types.Flag{
+				{Name: "--force", Description: "Reinstall"},
+			}},
+		},
+	}
+
+	verbose := NewZsh().Generate(tool)
+	for _, want := range []string{
+		"'install:Install a package'",
+		"'in:Install a package (alias for install)'",
+		"                install|in)\n",
+	} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("verbose output missing %q", want)
+		}
+	}
+
+	z := NewZsh()
+	z.SetCompact(true)
+	compact := z.Generate(tool)
+	for _, want := range []string{"'install'\n", "'in'\n"} {
+		if !strings.Contains(compact, want) {
+			t.Errorf("compact output missing %q", want)
+		}
+	}
+}
+
+func TestZsh_Generate_HostileNames(t *testing.T) {
+	// Names from help text or an imported spec end up in case patterns and
+	// single-quoted specs, so ones that would break out are left out
+	tool := &types.Tool{
+		Name: "evil",
+		GlobalFlags: []types.Flag{
+			{Name: "--it's", Description: "Quote"},
+			{Name: "--ok", Short: "-`", Description: "Fine"},
+		},
+		Subcommands: []types.Command{
+			{Name: "$(touch pwned)", Flags: []types.Flag{{Name: "--x"}}},
+			{Name: `say"hi`, Flags: []types.Flag{{Name: "--y"}}},
+			{Name: "run", Aliases: []string{"r;ls"}, Flags: []types.Flag{{Name: "--z"}}, Subcommands: []types.Command{
+				{Name: "st*p", Flags: []types.Flag{{Name: "--w"}}},
+			}},
+		},
+	}
+
+	script := NewZsh().Generate(tool)
+	for _, bad := range []string{"$(touch", `say"hi`, "it's", "-`", "r;ls", "st*p"} {
+		if strings.Contains(script, bad) {
+			t.Errorf("expected %q left out of the script:\n%s", bad, script)
+		}
+	}
+	for _, want := range []string{"--ok", "                run)\n"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("expected %q kept in the script:\n%s", want, script)
+		}
+	}
+}
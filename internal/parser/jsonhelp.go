@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// rawJSONHelpLabel heads JSON help output in raw output files
+const rawJSONHelpLabel = "json help"
+
+// jsonHelpCommand is the permissive schema for JSON help output. Every key is
+// optional and matched case-insensitively; synonyms such as "options" for
+// "flags" and "help" for "description" are accepted. The top-level object
+// describes the tool itself:
+//
+//	{"description": "...", "flags": [...], "commands": [{"name": "install",
+//	  "aliases": ["in"], "description": "...", "flags": [...], "commands": [...]}]}
+type jsonHelpCommand struct {
+	Name        string            `json:"name"`
+	Aliases     []string          `json:"aliases"`
+	Description string            `json:"description"`
+	Help        string            `json:"help"`
+	Summary     string            `json:"summary"`
+	Flags       []jsonHelpFlag    `json:"flags"`
+	Options     []jsonHelpFlag    `json:"options"`
+	Commands    []jsonHelpCommand `json:"commands"`
+	Subcommands []jsonHelpCommand `json:"subcommands"`
+}
+
+// jsonHelpFlag describes one flag. Its spellings may be given as "name",
+// "long", "short", "names" and "aliases", with or without leading dashes.
+type jsonHelpFlag struct {
+	Name        string   `json:"name"`
+	Long        string   `json:"long"`
+	Short       string   `json:"short"`
+	Names       []string `json:"names"`
+	Aliases     []string `json:"aliases"`
+	Description string   `json:"description"`
+	Help        string   `json:"help"`
+	Arg         string   `json:"arg"`
+	Metavar     string   `json:"metavar"`
+	TakesValue  bool     `json:"takes_value"`
+	Choices     []string `json:"choices"`
+	Values      []string `json:"values"`
+	Required    bool     `json:"required"`
+	Repeatable  bool     `json:"repeatable"`
+	Hidden      bool     `json:"hidden"`
+}
+
+// runJSONHelp runs the tool with each of JSONHelpCmds in turn and returns the
+// first output that parses as JSON help, along with the parsed tool
+func (p *Parser) runJSONHelp(path string) (string, *types.Tool) {
+	for _, arg := range p.config.JSONHelpCmds {
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.HelpTimeout)
+		// Stdout only: a warning on stderr would break the JSON
+		output, _ := RetryTransient(p.config.HelpRetries, p.config.HelpRetryDelay, func() ([]byte, error) {
+			return HelpCommand(ctx, path, arg).Output()
+		})
+		cancel()
+
+		tool, err := parseJSONHelp(string(output), p.config.MaxDepth)
+		if err != nil {
+			config.Logf("%s %s: %v", path, arg, err)
+			continue
+		}
+		config.Logf("Using JSON help from: %s %s", path, arg)
+		return string(output), tool
+	}
+	return "", nil
+}
+
+// applyJSONHelp copies what JSON help described into tool
+func applyJSONHelp(tool, parsed *types.Tool) {
+	tool.Source = "json"
+	tool.Description = parsed.Description
+	tool.GlobalFlags = parsed.GlobalFlags
+	tool.Subcommands = parsed.Subcommands
+}
+
+// parseJSONHelp maps JSON help output into a tool, keeping commands up to
+// maxDepth levels deep like --help parsing does
+func parseJSONHelp(output string, maxDepth int) (*types.Tool, error) {
+	trimmed := strings.TrimSpace(output)
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil, errors.New("not JSON")
+	}
+	var root jsonHelpCommand
+	if err := json.Unmarshal([]byte(trimmed), &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON help: %w", err)
+	}
+
+	tool := &types.Tool{
+		Description: root.description(),
+		GlobalFlags: jsonHelpFlags(append(root.Flags, root.Options...)),
+		Subcommands: jsonHelpCommands(append(root.Commands, root.Subcommands...), 1, maxDepth),
+	}
+	if len(tool.Subcommands) == 0 && len(tool.GlobalFlags) == 0 {
+		return nil, errors.New("JSON help has no commands or flags")
+	}
+	return tool, nil
+}
+
+// description returns the first help text the command gives
+func (c jsonHelpCommand) description() string {
+	for _, s := range []string{c.Description, c.Summary, c.Help} {
+		if s = strings.TrimSpace(s); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// jsonHelpCommands converts commands at depth, dropping unnamed ones and
+// merging repeats
+func jsonHelpCommands(in []jsonHelpCommand, depth, maxDepth int) []types.Command {
+	if depth > maxDepth {
+		return nil
+	}
+	var commands []types.Command
+	cmdSet := newCommandSet(&commands)
+	for _, c := range in {
+		if !isValidCommandName(c.Name) {
+			continue
+		}
+		cmd := types.Command{
+			Name:        c.Name,
+			Description: c.description(),
+			Flags:       jsonHelpFlags(append(c.Flags, c.Options...)),
+			Subcommands: jsonHelpCommands(append(c.Commands, c.Subcommands...), depth+1, maxDepth),
+		}
+		for _, alias := range c.Aliases {
+			if isValidCommandName(alias) && alias != c.Name && !slices.Contains(cmd.Aliases, alias) {
+				cmd.Aliases = append(cmd.Aliases, alias)
+			}
+		}
+		cmdSet.Add(cmd)
+	}
+	return commands
+}
+
+// jsonHelpFlags converts flags. The first long spelling becomes the name and
+// the first short one the short form; further short spellings become short
+// aliases and further long ones separate flags with the same description.
+func jsonHelpFlags(in []jsonHelpFlag) []types.Flag {
+	var flags []types.Flag
+	flagSet := newFlagSet(&flags)
+	for _, f := range in {
+		var longs, shorts []string
+		for _, name := range append([]string{f.Name, f.Long, f.Short}, append(f.Names, f.Aliases...)...) {
+			name = jsonFlagName(name)
+			switch {
+			case name == "" || slices.Contains(longs, name) || slices.Contains(shorts, name):
+			case len(name) == 2:
+				shorts = append(shorts, name)
+			default:
+				longs = append(longs, name)
+			}
+		}
+		if len(longs) == 0 && len(shorts) == 0 {
+			continue
+		}
+
+		flag := types.Flag{
+			Description:    strings.TrimSpace(f.Description),
+			Arg:            f.Arg,
+			ArgumentValues: f.Choices,
+			Required:       f.Required,
+			Repeatable:     f.Repeatable,
+			Hidden:         f.Hidden,
+		}
+		if flag.Description == "" {
+			flag.Description = strings.TrimSpace(f.Help)
+		}
+		if flag.Arg == "" {
+			flag.Arg = f.Metavar
+		}
+		if len(flag.ArgumentValues) == 0 {
+			flag.ArgumentValues = f.Values
+		}
+		if flag.Arg == "" && (f.TakesValue || len(flag.ArgumentValues) > 0) {
+			flag.Arg = "VALUE"
+		}
+
+		if len(longs) > 0 {
+			flag.Name = longs[0]
+			if len(shorts) > 0 {
+				flag.Short, flag.ShortAliases = shorts[0], shorts[1:]
+			}
+		} else {
+			flag.Name, flag.ShortAliases = shorts[0], shorts[1:]
+		}
+		if len(flag.ShortAliases) == 0 {
+			flag.ShortAliases = nil
+		}
+		flagSet.Add(flag)
+
+		for _, long := range longs[min(1, len(longs)):] {
+			extra := flag
+			extra.Name, extra.Short, extra.ShortAliases = long, "", nil
+			flagSet.Add(extra)
+		}
+	}
+	return flags
+}
+
+// jsonFlagName adds the dashes a bare flag name lacks: "v" becomes "-v" and
+// "verbose" becomes "--verbose". Names with spaces are rejected.
+func jsonFlagName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.Trim(name, "-") == "" || strings.ContainsAny(name, " \t") {
+		return ""
+	}
+	if strings.HasPrefix(name, "-") {
+		return name
+	}
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+const sampleJSONHelp = `{
+  "name": "pkg",
+  "summary": "Package manager",
+  "options": [
+    {"names": ["-v", "--verbose"], "help": "Be verbose"},
+    {"long": "format", "short": "f", "metavar": "FMT", "choices": ["json", "yaml"], "description": "Output format"},
+    {"name": "color", "aliases": ["colour"], "takes_value": true}
+  ],
+  "commands": [
+    {
+      "name": "install",
+      "aliases": ["in", "i"],
+      "description": "Install a package",
+      "flags": [{"name": "--force", "description": "Reinstall"}],
+      "subcommands": [
+        {"name": "local", "description": "Install from a file", "commands": [{"name": "too-deep"}]}
+      ]
+    },
+    {"name": "not a command"},
+    {"Name": "remove", "Help": "Remove a package", "Extra": {"ignored": true}}
+  ]
+}`
+
+func TestParseJSONHelp(t *testing.T) {
+	tool, err := parseJSONHelp(sampleJSONHelp, 2)
+	if err != nil {
+		t.Fatalf("parseJSONHelp failed: %v", err)
+	}
+
+	if tool.Description != "Package manager" {
+		t.Errorf("description = %q, want %q", tool.Description, "Package manager")
+	}
+
+	wantFlags := []types.Flag{
+		{Name: "--verbose", Short: "-v", Description: "Be verbose"},
+		{Name: "--format", Short: "-f", Arg: "FMT", ArgumentValues: []string{"json", "yaml"}, Description: "Output format"},
+		{Name: "--color", Arg: "VALUE"},
+		{Name: "--colour", Arg: "VALUE"},
+	}
+	if len(tool.GlobalFlags) != len(wantFlags) {
+		t.Fatalf("global flags = %+v, want %+v", tool.GlobalFlags, wantFlags)
+	}
+	for i, want := range wantFlags {
+		got := tool.GlobalFlags[i]
+		if got.Name != want.Name || got.Short != want.Short || got.Arg != want.Arg ||
+			got.Description != want.Description || !slices.Equal(got.ArgumentValues, want.ArgumentValues) {
+			t.Errorf("flag %d = %+v, want %+v", i, got, want)
+		}
+	}
+
+	if len(tool.Subcommands) != 2 {
+		t.Fatalf("subcommands = %+v, want install and remove", tool.Subcommands)
+	}
+	install := tool.Subcommands[0]
+	if install.Name != "install" || !slices.Equal(install.Aliases, []string{"in", "i"}) || install.Description != "Install a package" {
+		t.Errorf("install = %+v", install)
+	}
+	if len(install.Flags) != 1 || install.Flags[0].Name != "--force" {
+		t.Errorf("install flags = %+v, want --force", install.Flags)
+	}
+	if len(install.Subcommands) != 1 || install.Subcommands[0].Name != "local" {
+		t.Fatalf("install subcommands = %+v, want local", install.Subcommands)
+	}
+	if len(install.Subcommands[0].Subcommands) != 0 {
+		t.Errorf("commands deeper than max depth should be dropped, got %+v", install.Subcommands[0].Subcommands)
+	}
+	if remove := tool.Subcommands[1]; remove.Name != "remove" || remove.Description != "Remove a package" {
+		t.Errorf("remove = %+v", remove)
+	}
+}
+
+func TestParseJSONHelp_Rejects(t *testing.T) {
+	for _, output := range []string{
+		"",
+		"Usage: pkg [OPTIONS]\n",
+		"{not json",
+		`{"name": "pkg"}`,
+		`{"flags": "--verbose"}`,
+	} {
+		if tool, err := parseJSONHelp(output, 2); err == nil {
+			t.Errorf("parseJSONHelp(%q) = %+v, want error", output, tool)
+		}
+	}
+}
+
+func TestParse_JSONHelp(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "pkg")
+	content := `#!/bin/sh
+if [ "$1" = "--help-json" ]; then
+  cat <<'EOF'
+` + sampleJSONHelp + `
+EOF
+  exit 0
+fi
+printf 'Options:\n  --text-only  Only in text help\n'
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.DetectVersion = false
+	cfg.RawDir = filepath.Join(dir, "raw")
+	tool, err := New(cfg).Parse("pkg", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tool.Source != "json" {
+		t.Errorf("source = %q, want json", tool.Source)
+	}
+	if len(tool.Subcommands) != 2 || len(tool.GlobalFlags) != 4 {
+		t.Errorf("expected 2 commands and 4 flags from JSON help, got %+v %+v", tool.Subcommands, tool.GlobalFlags)
+	}
+
+	// The raw output reparses to the same tool without running anything
+	raw, err := os.ReadFile(filepath.Join(cfg.RawDir, "pkg.txt"))
+	if err != nil {
+		t.Fatalf("raw output not saved: %v", err)
+	}
+	reparsed, err := New(cfg).ParseFromText("pkg", string(raw))
+	if err != nil {
+		t.Fatalf("ParseFromText failed: %v", err)
+	}
+	if reparsed.Source != "json" || reparsed.ContentHash() != tool.ContentHash() {
+		t.Errorf("reparsed tool differs: source=%q", reparsed.Source)
+	}
+
+	// Without JSON help configured, the text help is parsed
+	cfg.JSONHelpCmds = nil
+	tool, err = New(cfg).Parse("pkg", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tool.Source == "json" || len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--text-only" {
+		t.Errorf("expected text parsing, got source=%q flags=%+v", tool.Source, tool.GlobalFlags)
+	}
+}
@@ -6,8 +6,14 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/jvalentini/tabgen/internal/config"
 	"github.com/jvalentini/tabgen/internal/types"
@@ -17,21 +23,87 @@ import (
 type ParserConfig struct {
 	// MaxDepth limits how deep we recurse into subcommands (default: 2)
 	MaxDepth int
-	// HelpTimeout is the timeout for running help/version commands (default: 5s)
+	// HelpTimeout is the timeout for running help commands (default: 5s)
 	HelpTimeout time.Duration
+	// VersionTimeout is the timeout for each version command, kept short since
+	// up to len(VersionCmds) of them run per tool (default: 2s)
+	VersionTimeout time.Duration
+	// HelpWorkers bounds how many subcommand help commands run at once (default: 4)
+	HelpWorkers int
+	// HelpRetries is how many more times a help command is run when it couldn't
+	// be started for a transient reason, such as fork failing under load
+	// (default: 2). HelpRetryDelay is the wait before the first retry, doubled
+	// before each later one (default: 100ms)
+	HelpRetries    int
+	HelpRetryDelay time.Duration
 	// VersionCmds are the flags to try when detecting version (default: --version, -V, version, -v)
 	VersionCmds []string
+	// DetectVersion runs the tool with VersionCmds to fill Tool.Version (default: true
+	// in DefaultConfig; a config built by hand must set it)
+	DetectVersion bool
+	// DeepDiscovery runs "help -a"/"help --all" when the help output advertises it,
+	// to find commands hidden from the default listing (default: false, spawns extra processes)
+	DeepDiscovery bool
+	// DiscoverExternalSubcommands adds git-style "<tool>-<name>" executables on
+	// $PATH as subcommands, reading their flags from "<tool>-<name> --help".
+	// This finds plugins missing from the tool's own command list (default: false,
+	// reads every $PATH directory)
+	DiscoverExternalSubcommands bool
+	// RawDir, if set, is where Parse saves the raw help and man output it captured
+	// as <name>.txt, for re-parsing later with ParseFromText (default: "", disabled)
+	RawDir string
+	// ForceSource restricts parsing to "help" or "man" output (default: "", use both)
+	ForceSource string
+	// SkipHelp and SkipMan never run "--help" or man for the tool and its
+	// subcommands. Unlike ForceSource they aren't replaced by per-tool
+	// overrides, so each turns off one source everywhere (default: false)
+	SkipHelp bool
+	SkipMan  bool
+	// JSONHelpCmds are the arguments tried, in order, before "--help" for tools
+	// that describe themselves as JSON. The first that prints valid JSON help
+	// (see jsonHelpCommand) replaces text parsing for the whole tool (default:
+	// --help=json, --help-json)
+	JSONHelpCmds []string
+	// ExtraHelpArgs are appended when running "<tool> --help" (default: none)
+	ExtraHelpArgs []string
+	// ManFallback parses man pages more aggressively when --help gives nothing:
+	// flags anywhere in DESCRIPTION and commands from every "... COMMANDS"
+	// section, not just "COMMANDS" (default: false)
+	ManFallback bool
 }
 
 // DefaultConfig returns a ParserConfig with sensible defaults
 func DefaultConfig() ParserConfig {
 	return ParserConfig{
-		MaxDepth:    2,
-		HelpTimeout: 5 * time.Second,
-		VersionCmds: []string{"--version", "-V", "version", "-v"},
+		MaxDepth:       2,
+		HelpTimeout:    5 * time.Second,
+		VersionTimeout: 2 * time.Second,
+		HelpWorkers:    4,
+		HelpRetries:    2,
+		HelpRetryDelay: 100 * time.Millisecond,
+		VersionCmds:    []string{"--version", "-V", "version", "-v"},
+		JSONHelpCmds:   []string{"--help=json", "--help-json"},
+		DetectVersion:  true,
 	}
 }
 
+// WithOverride returns a copy of the config with a tool's override applied
+func (c ParserConfig) WithOverride(o types.ToolOverride) ParserConfig {
+	if len(o.VersionCmds) > 0 {
+		c.VersionCmds = o.VersionCmds
+	}
+	if o.MaxDepth > 0 {
+		c.MaxDepth = o.MaxDepth
+	}
+	if o.ForceSource != "" {
+		c.ForceSource = o.ForceSource
+	}
+	if len(o.ExtraHelpArgs) > 0 {
+		c.ExtraHelpArgs = o.ExtraHelpArgs
+	}
+	return c
+}
+
 // Parser extracts command structure from --help and man pages
 type Parser struct {
 	config ParserConfig
@@ -52,6 +124,12 @@ func New(cfg ...ParserConfig) *Parser {
 	if parserConfig.HelpTimeout == 0 {
 		parserConfig.HelpTimeout = 5 * time.Second
 	}
+	if parserConfig.VersionTimeout == 0 {
+		parserConfig.VersionTimeout = 2 * time.Second
+	}
+	if parserConfig.HelpWorkers <= 0 {
+		parserConfig.HelpWorkers = 4
+	}
 	if len(parserConfig.VersionCmds) == 0 {
 		parserConfig.VersionCmds = []string{"--version", "-V", "version", "-v"}
 	}
@@ -65,16 +143,17 @@ func (p *Parser) Config() ParserConfig {
 
 // UniqueSet provides O(1) duplicate detection for any slice type
 type UniqueSet[T any] struct {
-	seen  map[string]bool
+	seen  map[string]int
 	items *[]T
 	key   func(T) string
+	merge func(existing *T, item T) // Optional: folds a duplicate into the kept item
 }
 
 // NewUniqueSet creates a UniqueSet wrapping an existing slice
 func NewUniqueSet[T any](items *[]T, key func(T) string) *UniqueSet[T] {
-	seen := make(map[string]bool, len(*items))
-	for _, item := range *items {
-		seen[key(item)] = true
+	seen := make(map[string]int, len(*items))
+	for i, item := range *items {
+		seen[key(item)] = i
 	}
 	return &UniqueSet[T]{seen: seen, items: items, key: key}
 }
@@ -82,10 +161,13 @@ func NewUniqueSet[T any](items *[]T, key func(T) string) *UniqueSet[T] {
 // Add appends item if not already present (O(1) lookup), returns true if added
 func (s *UniqueSet[T]) Add(item T) bool {
 	k := s.key(item)
-	if s.seen[k] {
+	if i, ok := s.seen[k]; ok {
+		if s.merge != nil {
+			s.merge(&(*s.items)[i], item)
+		}
 		return false
 	}
-	s.seen[k] = true
+	s.seen[k] = len(*s.items)
 	*s.items = append(*s.items, item)
 	return true
 }
@@ -95,9 +177,31 @@ func newFlagSet(flags *[]types.Flag) *UniqueSet[types.Flag] {
 	return NewUniqueSet(flags, func(f types.Flag) string { return f.Name })
 }
 
-// newCommandSet creates a UniqueSet for commands
+// newCommandSet creates a UniqueSet for commands. A command listed twice keeps
+// the aliases, description and subcommands of both listings, so "install"
+// followed later by "install, in" still offers "in".
 func newCommandSet(commands *[]types.Command) *UniqueSet[types.Command] {
-	return NewUniqueSet(commands, func(c types.Command) string { return c.Name })
+	set := NewUniqueSet(commands, func(c types.Command) string { return c.Name })
+	set.merge = mergeCommand
+	return set
+}
+
+// mergeCommand adds dup's aliases, subcommands and missing description to cmd
+func mergeCommand(cmd *types.Command, dup types.Command) {
+	if len(dup.Subcommands) > 0 {
+		subs := newCommandSet(&cmd.Subcommands)
+		for _, sub := range dup.Subcommands {
+			subs.Add(sub)
+		}
+	}
+	for _, alias := range dup.Aliases {
+		if alias != cmd.Name && !slices.Contains(cmd.Aliases, alias) {
+			cmd.Aliases = append(cmd.Aliases, alias)
+		}
+	}
+	if cmd.Description == "" {
+		cmd.Description = dup.Description
+	}
 }
 
 // MaxSubcommandDepth is kept for backward compatibility
@@ -139,54 +243,137 @@ func (p *Parser) Parse(name, path string) (*types.Tool, error) {
 		Path:     path,
 		ParsedAt: time.Now(),
 	}
+	capture := p.newRawCapture()
 
 	// Detect version
-	tool.Version = p.detectVersion(path)
-	if tool.Version != "" {
-		config.Logf("Detected version: %s", tool.Version)
+	if p.config.DetectVersion {
+		tool.Version = p.detectVersion(path)
+		if tool.Version != "" {
+			config.Logf("Detected version: %s", tool.Version)
+		} else {
+			config.Logf("No version detected")
+		}
 	} else {
-		config.Logf("No version detected")
+		config.Logf("Version detection disabled")
 	}
 
-	// Try --help first
-	config.Logf("Running: %s --help", path)
-	helpOutput, helpErr := p.runHelp(path)
-	if helpErr != nil {
-		config.Logf("--help error: %v", helpErr)
-		// Distinguish permission errors from "no help available"
-		if isPermissionError(helpErr) {
-			return nil, fmt.Errorf("cannot run %s --help: %w", path, helpErr)
+	if p.config.ForceSource != "" {
+		config.Logf("Forcing source: %s", p.config.ForceSource)
+	}
+	useHelp := p.config.ForceSource != "man" && !p.config.SkipHelp
+	useMan := p.config.ForceSource != "help" && !p.config.SkipMan
+
+	// Tools that describe themselves as JSON need no text parsing
+	if useHelp && len(p.config.JSONHelpCmds) > 0 {
+		if output, parsed := p.runJSONHelp(path); parsed != nil {
+			capture.add(rawJSONHelpLabel, output)
+			applyJSONHelp(tool, parsed)
+			return p.finishParse(tool, capture), nil
 		}
-		// Other errors (e.g., tool has no help) are acceptable, continue
 	}
 
-	if helpOutput != "" {
-		config.Logf("--help output: %d bytes", len(helpOutput))
-		config.LogSnippet("--help output", helpOutput, 20)
-	} else {
-		config.Logf("--help returned no output")
+	// Try --help first
+	var helpOutput string
+	if useHelp {
+		config.Logf("Running: %s --help", path)
+		var helpErr error
+		helpOutput, helpErr = p.runHelp(path)
+		if helpErr != nil {
+			config.Logf("--help error: %v", helpErr)
+			// Distinguish permission errors from "no help available"
+			if isPermissionError(helpErr) {
+				return nil, fmt.Errorf("cannot run %s --help: %w", path, helpErr)
+			}
+			// Other errors (e.g., tool has no help) are acceptable, continue
+		}
+
+		capture.add(rawHelpLabel, helpOutput)
+		if helpOutput != "" {
+			config.Logf("--help output: %d bytes", len(helpOutput))
+			config.LogSnippet("--help output", helpOutput, 20)
+		} else {
+			config.Logf("--help returned no output")
+		}
 	}
 
 	// Try man page as fallback or supplement
-	config.Logf("Checking man page for: %s", name)
-	manOutput, manErr := p.getManPage(name)
-	if manErr != nil {
-		config.Logf("man page error: %v", manErr)
-		// Permission errors on man page are less critical but worth noting
-		if isPermissionError(manErr) {
-			// Log but don't fail - man pages are optional
-			tool.Source = "help-only"
+	var manOutput string
+	if useMan {
+		config.Logf("Checking man page for: %s", name)
+		var manErr error
+		manOutput, manErr = p.getManPage(name)
+		if manErr != nil {
+			config.Logf("man page error: %v", manErr)
+			// Permission errors on man page are less critical but worth noting
+			if isPermissionError(manErr) {
+				// Log but don't fail - man pages are optional
+				tool.Source = "help-only"
+			}
+			// Other errors (no man page) are acceptable
+		} else if manOutput != "" {
+			config.Logf("man page output: %d bytes", len(manOutput))
 		}
-		// Other errors (no man page) are acceptable
-	} else if manOutput != "" {
-		config.Logf("man page output: %d bytes", len(manOutput))
 	}
+	capture.add(rawManLabel, manOutput)
 
 	// Parse what we got
+	p.parseOutputs(tool, helpOutput, manOutput)
+
+	// Discover commands hidden behind "help -a" before recursing into them
+	if p.config.DeepDiscovery && helpOutput != "" {
+		p.discoverHiddenCommands(tool, path, helpOutput, capture)
+	}
+
+	var externals map[string]string
+	if p.config.DiscoverExternalSubcommands {
+		externals = p.discoverExternalCommands(tool)
+	}
+
+	// Parse nested subcommands (depth-limited)
+	if len(tool.Subcommands) > 0 {
+		config.Logf("Parsing nested subcommands (max depth: %d)...", p.config.MaxDepth)
+		p.parseNestedSubcommands(&tool.GlobalFlags, "", tool.Subcommands, 1, capture, func(cmdPath string) (string, string) {
+			var output string
+			if external, ok := externals[cmdPath]; ok {
+				if useHelp {
+					output, _ = p.runHelp(external)
+				}
+			} else if useHelp {
+				parent, sub := splitCommandPath(cmdPath)
+				output = p.runSubcommandHelp(strings.TrimSpace(path+" "+parent), sub)
+			}
+			if output != "" || !useMan {
+				return output, ""
+			}
+			// No --help for the subcommand: try a git-style "tool-sub" man page
+			return "", p.getSubcommandManPage(name, cmdPath)
+		})
+	}
+
+	return p.finishParse(tool, capture), nil
+}
+
+// finishParse runs the post-processing passes and saves the raw output
+func (p *Parser) finishParse(tool *types.Tool, capture *rawCapture) *types.Tool {
+	p.postProcess(tool)
+
+	if err := capture.save(p.config.RawDir, tool.Name); err != nil {
+		config.Logf("Failed to save raw output: %v", err)
+	}
+
+	config.Logf("Parse complete: source=%s, subcommands=%d, flags=%d",
+		tool.Source, len(tool.Subcommands), len(tool.GlobalFlags))
+
+	return tool
+}
+
+// parseOutputs parses --help and man page output into tool and sets tool.Source
+func (p *Parser) parseOutputs(tool *types.Tool, helpOutput, manOutput string) {
 	if helpOutput != "" {
 		tool.Source = "help"
 		config.Logf("Parsing --help output...")
 		p.parseHelpOutput(tool, helpOutput)
+		tool.Description = helpSummary(tool.Name, helpOutput)
 		config.Logf("Found %d subcommands, %d global flags from --help",
 			len(tool.Subcommands), len(tool.GlobalFlags))
 	}
@@ -198,7 +385,15 @@ func (p *Parser) Parse(name, path string) (*types.Tool, error) {
 			tool.Source = "both"
 		}
 		config.Logf("Parsing man page...")
-		p.parseManPage(tool, manOutput)
+		if helpOutput == "" && p.config.ManFallback {
+			config.Logf("No --help output, parsing man page commands too")
+			p.parseManSections(tool.Name, &tool.GlobalFlags, &tool.Subcommands, manOutput, true)
+		} else {
+			p.parseManPage(tool, manOutput)
+		}
+		if tool.Description == "" {
+			tool.Description = manSummary(manOutput)
+		}
 		config.Logf("Total flags after man page: %d", len(tool.GlobalFlags))
 	}
 
@@ -206,41 +401,171 @@ func (p *Parser) Parse(name, path string) (*types.Tool, error) {
 		tool.Source = "none"
 		config.Logf("No help or man page found - tool unparseable")
 	}
+}
 
-	// Parse nested subcommands (depth-limited)
-	if len(tool.Subcommands) > 0 {
-		config.Logf("Parsing nested subcommands (max depth: %d)...", MaxSubcommandDepth)
-		p.parseNestedSubcommands(path, tool.Subcommands, 1)
+// parseNestedSubcommands recursively parses subcommand help. prefix is the
+// command path leading to commands (e.g. "remote"), and helpFor returns the
+// help output for a full command path (e.g. "remote add"), or its man page
+// when there is no help output. helpFor is called concurrently for sibling
+// commands; their output is recorded in capture and parsed in command order.
+// Flags a subcommand lists as global are merged into globals instead of being
+// repeated on every command.
+func (p *Parser) parseNestedSubcommands(globals *[]types.Flag, prefix string, commands []types.Command, depth int, capture *rawCapture, helpFor func(cmdPath string) (help, man string)) {
+	if depth >= p.config.MaxDepth {
+		return
 	}
 
-	config.Logf("Parse complete: source=%s, subcommands=%d, flags=%d",
-		tool.Source, len(tool.Subcommands), len(tool.GlobalFlags))
+	paths := make([]string, len(commands))
+	for i, cmd := range commands {
+		paths[i] = strings.TrimSpace(prefix + " " + cmd.Name)
+	}
+	helps, mans := p.fetchHelp(paths, helpFor)
 
-	return tool, nil
+	for i := range commands {
+		cmd := &commands[i]
+		cmdPath := paths[i]
+		output, manOutput := helps[i], mans[i]
+		capture.add(cmdPath+" "+rawHelpLabel, output)
+		capture.add(cmdPath+" "+rawManLabel, manOutput)
+
+		switch {
+		case output != "":
+			// Parse flags and nested subcommands from output
+			if inherited := p.parseSubcommandOutput(cmd, output); len(inherited) > 0 {
+				globalSet := newFlagSet(globals)
+				for _, flag := range inherited {
+					globalSet.Add(flag)
+				}
+			}
+		case manOutput != "":
+			p.parseManSections("", &cmd.Flags, &cmd.Subcommands, manOutput, p.config.ManFallback)
+		default:
+			continue
+		}
+
+		// Recurse into nested subcommands
+		if len(cmd.Subcommands) > 0 {
+			p.parseNestedSubcommands(globals, cmdPath, cmd.Subcommands, depth+1, capture, helpFor)
+		}
+	}
 }
 
-// parseNestedSubcommands recursively parses subcommand help
-func (p *Parser) parseNestedSubcommands(basePath string, commands []types.Command, depth int) {
-	if depth >= p.config.MaxDepth {
+// fetchHelp calls helpFor for every command path, running up to HelpWorkers
+// at a time. Results land at the index of their path, so the order commands
+// finish in never affects the parse.
+func (p *Parser) fetchHelp(paths []string, helpFor func(cmdPath string) (help, man string)) (helps, mans []string) {
+	helps = make([]string, len(paths))
+	mans = make([]string, len(paths))
+	workers := min(p.config.HelpWorkers, len(paths))
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(func() {
+			for i := range indices {
+				helps[i], mans[i] = helpFor(paths[i])
+			}
+		})
+	}
+	for i := range paths {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return helps, mans
+}
+
+// splitCommandPath splits "remote add" into its parent path "remote" and last command "add"
+func splitCommandPath(cmdPath string) (parent, name string) {
+	if idx := strings.LastIndex(cmdPath, " "); idx >= 0 {
+		return cmdPath[:idx], cmdPath[idx+1:]
+	}
+	return "", cmdPath
+}
+
+// discoverHiddenCommands runs the full command listing advertised by git-style
+// help output (e.g. "See 'git help -a'") and adds any commands it finds
+func (p *Parser) discoverHiddenCommands(tool *types.Tool, path, helpOutput string, capture *rawCapture) {
+	args := helpAllArgs(helpOutput)
+	if args == nil {
 		return
 	}
 
-	for i := range commands {
-		cmd := &commands[i]
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.HelpTimeout)
+	defer cancel()
+
+	config.Logf("Deep discovery: %s %s", path, strings.Join(args, " "))
+	output, err := p.runHelpCommand(ctx, path, args...)
+	if err != nil && len(output) == 0 {
+		config.Logf("Deep discovery failed: %v", err)
+		return
+	}
+
+	capture.add(strings.Join(args, " "), string(output))
+
+	before := len(tool.Subcommands)
+	p.parseHelpAllOutput(tool, string(output))
+	config.Logf("Deep discovery found %d additional subcommands", len(tool.Subcommands)-before)
+}
+
+// discoverExternalCommands adds each git-style "<tool>-<name>" executable on
+// $PATH as a subcommand named <name>, unless the tool already lists it. The
+// first match on $PATH wins. It returns the added subcommands' binaries, whose
+// own --help is read for their flags.
+func (p *Parser) discoverExternalCommands(tool *types.Tool) map[string]string {
+	cmdSet := newCommandSet(&tool.Subcommands)
+	externals := make(map[string]string)
+	prefix := tool.Name + "-"
 
-		// Try to get help for this subcommand
-		output := p.runSubcommandHelp(basePath, cmd.Name)
-		if output == "" {
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
 			continue
 		}
+		for _, entry := range entries {
+			sub, ok := strings.CutPrefix(entry.Name(), prefix)
+			if !ok || !isValidCommandName(sub) {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			if cmdSet.Add(types.Command{Name: sub}) {
+				externals[sub] = path
+			}
+		}
+	}
+
+	config.Logf("Found %d external subcommands on $PATH", len(externals))
+	return externals
+}
 
-		// Parse flags and nested subcommands from output
-		p.parseSubcommandOutput(cmd, output)
+// helpAllArgs returns the "help --all" style arguments mentioned in help output, or nil
+func helpAllArgs(helpOutput string) []string {
+	lower := strings.ToLower(helpOutput)
+	switch {
+	case strings.Contains(lower, "help --all"):
+		return []string{"help", "--all"}
+	case strings.Contains(lower, "help -a"):
+		return []string{"help", "-a"}
+	}
+	return nil
+}
 
-		// Recurse into nested subcommands
-		if len(cmd.Subcommands) > 0 {
-			// For nested commands, we need to pass the full command path
-			p.parseNestedSubcommands(basePath+" "+cmd.Name, cmd.Subcommands, depth+1)
+// parseHelpAllOutput extracts commands from a "help -a" listing, which groups
+// indented commands under unmarked category headings
+func (p *Parser) parseHelpAllOutput(tool *types.Tool, output string) {
+	cmdSet := newCommandSet(&tool.Subcommands)
+
+	for line := range strings.SplitSeq(output, "\n") {
+		if len(line) <= 3 || (line[0] != ' ' && line[0] != '\t') {
+			continue
+		}
+		if cmd := p.parseIndentedCommand(line); cmd != nil {
+			cmdSet.Add(*cmd)
 		}
 	}
 }
@@ -254,49 +579,93 @@ func (p *Parser) runSubcommandHelp(basePath, subcommand string) string {
 	parts := strings.Fields(basePath)
 	args := append(parts[1:], subcommand, "--help")
 
-	cmd := exec.CommandContext(ctx, parts[0], args...)
-	output, err := cmd.CombinedOutput()
+	output, err := p.runHelpCommand(ctx, parts[0], args...)
 	if err != nil && len(output) == 0 {
 		// Try without --help (some tools use "help subcommand")
 		args = append(parts[1:], "help", subcommand)
-		cmd = exec.CommandContext(ctx, parts[0], args...)
-		output, _ = cmd.CombinedOutput()
+		output, _ = p.runHelpCommand(ctx, parts[0], args...)
 	}
 	return string(output)
 }
 
-// parseSubcommandOutput extracts flags and nested subcommands from help output
-func (p *Parser) parseSubcommandOutput(cmd *types.Command, output string) {
-	lines := strings.Split(output, "\n")
+// parseSubcommandOutput extracts flags and nested subcommands from help output.
+// Flags under a Cobra-style "Global Flags:" header belong to the whole tool,
+// so they are returned rather than added to cmd.
+func (p *Parser) parseSubcommandOutput(cmd *types.Command, output string) []types.Flag {
+	lines := alignDescriptionColumns(strings.Split(output, "\n"))
 
 	// Use sets for O(1) duplicate detection
-	flagSet := newFlagSet(&cmd.Flags)
+	localSet := newFlagSet(&cmd.Flags)
 	cmdSet := newCommandSet(&cmd.Subcommands)
+	var globals []types.Flag
+	globalSet := newFlagSet(&globals)
+	flagSet := localSet
 
 	inCommands := false
 	inOptions := false
+	inHidden := false // Options section of hidden flags
+	inPositionals := false
+	var positionals *positionalSection
+	inUsage, inExamples := false, false // Invocations, not definitions (see parseHelpOutput)
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		lower := strings.ToLower(trimmed)
 
+		if trimmed != "" && indentWidth(line) == 0 {
+			inExamples = isExamplesHeader(lower)
+			inUsage = strings.HasPrefix(lower, "usage:")
+		}
+
 		// Detect section headers
 		if strings.HasPrefix(lower, "commands:") ||
 			strings.HasPrefix(lower, "available commands:") ||
-			strings.HasPrefix(lower, "subcommands:") {
+			strings.HasPrefix(lower, "subcommands:") ||
+			isCommandCategoryHeader(trimmed) {
 			inCommands = true
-			inOptions = false
+			inOptions, inHidden = false, false
+			inPositionals = false
 			continue
 		}
 
 		if strings.HasPrefix(lower, "options:") ||
-			strings.HasPrefix(lower, "flags:") {
+			strings.HasPrefix(lower, "flags:") ||
+			isHiddenFlagsHeader(lower) {
 			inCommands = false
 			inOptions = true
+			inHidden = isHiddenFlagsHeader(lower)
+			inPositionals = false
+			flagSet = localSet
+			continue
+		}
+
+		if strings.HasPrefix(lower, "global flags:") ||
+			strings.HasPrefix(lower, "global options:") {
+			inCommands = false
+			inOptions = true
+			inHidden = false
+			inPositionals = false
+			flagSet = globalSet
+			continue
+		}
+
+		if isPositionalsHeader(lower) {
+			inCommands = false
+			inOptions, inHidden = false, false
+			inPositionals = true
+			positionals = p.newPositionalSection(&cmd.Subcommands, cmdSet)
 			continue
 		}
 
 		if trimmed == "" {
+			inPositionals = false
+			inUsage = false
+			continue
+		}
+
+		// Parse argparse positionals: subcommand choices or plain metavars
+		if inPositionals {
+			positionals.parseLine(line)
 			continue
 		}
 
@@ -307,10 +676,11 @@ func (p *Parser) parseSubcommandOutput(cmd *types.Command, output string) {
 			}
 		}
 
-		// Parse flags
-		if inOptions || strings.HasPrefix(trimmed, "-") {
-			if flag := p.parseFlagLine(line); flag != nil {
-				flagSet.Add(*flag)
+		// Parse flags, and flag definitions outside any options section
+		if inOptions || !inUsage && !inExamples && isFlagDefinition(trimmed) {
+			for _, flag := range p.parseFlagLines(line) {
+				flag.Hidden = flag.Hidden || inOptions && inHidden
+				flagSet.Add(flag)
 			}
 		}
 
@@ -321,6 +691,25 @@ func (p *Parser) parseSubcommandOutput(cmd *types.Command, output string) {
 			}
 		}
 	}
+
+	return globals
+}
+
+// helpEnv overrides the ambient environment so tools print plain, unwrapped help
+// and never hand it to an interactive pager
+var helpEnv = []string{
+	"NO_COLOR=1", "TERM=dumb", "COLUMNS=1000", "LC_ALL=C",
+	"PAGER=cat", "GIT_PAGER=cat", "MANPAGER=cat",
+}
+
+// HelpCommand builds a command for capturing help output. The ambient environment
+// is kept (tools may need PATH or HOME) with helpEnv taking precedence. Stdin is
+// left nil, which connects it to /dev/null, so a pager that starts anyway sees
+// EOF instead of waiting for input.
+func HelpCommand(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), helpEnv...)
+	return cmd
 }
 
 // runHelp executes tool --help and captures output
@@ -328,16 +717,14 @@ func (p *Parser) runHelp(path string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), p.config.HelpTimeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, path, "--help")
-	output, err := cmd.CombinedOutput()
+	output, err := p.runHelpCommand(ctx, path, append([]string{"--help"}, p.config.ExtraHelpArgs...)...)
 	if err != nil {
 		// Many tools return non-zero for --help, still use output
 		if len(output) > 0 {
 			return string(output), nil
 		}
 		// Try -h as fallback
-		cmd = exec.CommandContext(ctx, path, "-h")
-		output, _ = cmd.CombinedOutput()
+		output, _ = p.runHelpCommand(ctx, path, append([]string{"-h"}, p.config.ExtraHelpArgs...)...)
 	}
 	return string(output), nil
 }
@@ -348,7 +735,10 @@ func (p *Parser) getManPage(name string) (string, error) {
 	defer cancel()
 
 	cmd := exec.CommandContext(ctx, "man", name)
-	cmd.Env = []string{"MANWIDTH=120", "LC_ALL=C"}
+	cmd.Env = []string{"MANWIDTH=120", "LC_ALL=C", "MANPAGER=cat", "PAGER=cat"}
+	if manpath := os.Getenv("MANPATH"); manpath != "" {
+		cmd.Env = append(cmd.Env, "MANPATH="+manpath)
+	}
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -356,9 +746,22 @@ func (p *Parser) getManPage(name string) (string, error) {
 	return string(output), nil
 }
 
+// getSubcommandManPage retrieves the git-style man page for a subcommand,
+// e.g. "git-commit" for "git commit" or "git-remote-add" for "git remote add"
+func (p *Parser) getSubcommandManPage(name, cmdPath string) string {
+	page := name + "-" + strings.ReplaceAll(cmdPath, " ", "-")
+	config.Logf("Checking man page for: %s", page)
+	output, err := p.getManPage(page)
+	if err != nil {
+		config.Logf("man page error: %v", err)
+		return ""
+	}
+	return output
+}
+
 // parseHelpOutput extracts structure from --help output
 func (p *Parser) parseHelpOutput(tool *types.Tool, output string) {
-	lines := strings.Split(output, "\n")
+	lines := alignDescriptionColumns(skipBanner(strings.Split(output, "\n")))
 
 	// Use sets for O(1) duplicate detection
 	flagSet := newFlagSet(&tool.GlobalFlags)
@@ -366,56 +769,136 @@ func (p *Parser) parseHelpOutput(tool *types.Tool, output string) {
 
 	inCommands := false
 	inOptions := false
+	inHidden := false // Options section of hidden flags
+	inPositionals := false
+	var positionals *positionalSection
+
+	// A "Usage: tool <command>" line arms implicitCommands, which treats the
+	// indented list after the next blank line as a commands section
+	afterUsage := false
+	implicitCommands := false
+	implicitStart := 0 // len(tool.Subcommands) when the implicit list began
+
+	// Most recently added command, for attaching wrapped description lines
+	lastCmd := -1
+	lastIndent := 0
+
+	// Usage lines (until a blank line) and example sections (until the next
+	// unindented line) show invocations, not flag definitions
+	inUsage := false
+	inExamples := false
+
+	// Subcommands given as alternation in the usage, e.g. "tool (start|stop)",
+	// used only when the help has no commands list
+	var usageCommands []string
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		lower := strings.ToLower(trimmed)
 
+		if trimmed != "" && indentWidth(line) == 0 {
+			inExamples = isExamplesHeader(lower)
+			inUsage = strings.HasPrefix(lower, "usage:")
+		}
+		if inUsage {
+			usageCommands = append(usageCommands, usageAlternatives(trimmed)...)
+		}
+
 		// Detect section headers
-		if strings.HasPrefix(lower, "commands:") ||
-			strings.HasPrefix(lower, "available commands:") ||
-			strings.HasPrefix(lower, "subcommands:") ||
-			lower == "commands" {
+		if isCommandsHeader(lower) || isCommandCategoryHeader(trimmed) {
 			config.Logf("Detected COMMANDS section: %q", trimmed)
 			inCommands = true
-			inOptions = false
+			inOptions, inHidden = false, false
+			inPositionals = false
+			afterUsage, implicitCommands = false, false
+			lastCmd = -1
 			continue
 		}
 
-		if strings.HasPrefix(lower, "options:") ||
-			strings.HasPrefix(lower, "flags:") ||
-			strings.HasPrefix(lower, "global options:") ||
-			strings.HasPrefix(lower, "global flags:") ||
-			lower == "options" || lower == "flags" {
+		if isOptionsHeader(lower) || isHiddenFlagsHeader(lower) {
 			config.Logf("Detected OPTIONS section: %q", trimmed)
 			inCommands = false
 			inOptions = true
+			inHidden = isHiddenFlagsHeader(lower)
+			inPositionals = false
+			afterUsage, implicitCommands = false, false
+			continue
+		}
+
+		if isPositionalsHeader(lower) {
+			config.Logf("Detected POSITIONAL ARGUMENTS section: %q", trimmed)
+			inCommands = false
+			inOptions, inHidden = false, false
+			inPositionals = true
+			afterUsage, implicitCommands = false, false
+			positionals = p.newPositionalSection(&tool.Subcommands, cmdSet)
+			continue
+		}
+
+		if isCommandUsageLine(lower) && !inCommands {
+			afterUsage = true
 			continue
 		}
 
 		// Empty line might end a section
 		if trimmed == "" {
+			lastCmd = -1
+			inPositionals = false
+			inUsage = false
+			if afterUsage {
+				// Usage lines are done; an indented command list may follow
+				config.Logf("Looking for commands after usage line")
+				afterUsage = false
+				implicitCommands = true
+				implicitStart = len(tool.Subcommands)
+				inCommands = true
+			} else if implicitCommands && len(tool.Subcommands) > implicitStart {
+				implicitCommands = false
+				inCommands = false
+			}
+			continue
+		}
+
+		// The implicit list is indented; anything else ends it
+		if implicitCommands && indentWidth(line) == 0 {
+			implicitCommands = false
+			inCommands = false
+		}
+
+		// Parse argparse positionals: subcommand choices or plain metavars
+		if inPositionals {
+			positionals.parseLine(line)
 			continue
 		}
 
 		// Parse commands
 		if inCommands {
-			if cmd := p.parseCommandLine(line); cmd != nil {
-				cmdSet.Add(*cmd)
+			if lastCmd >= 0 && isCommandContinuation(line, lastIndent) {
+				cmd := &tool.Subcommands[lastCmd]
+				cmd.Description = strings.TrimSpace(cmd.Description + " " + trimmed)
+				continue
+			}
+			lastCmd = -1
+			// Wrapped text after a "config view" entry isn't config's description
+			if cmd := p.parseCommandLine(line); cmd != nil && cmdSet.Add(*cmd) && len(cmd.Subcommands) == 0 {
+				lastCmd = len(tool.Subcommands) - 1
+				lastIndent = indentWidth(line)
 			}
 		}
 
 		// Parse options/flags
 		if inOptions {
-			if flag := p.parseFlagLine(line); flag != nil {
-				flagSet.Add(*flag)
+			for _, flag := range p.parseFlagLines(line) {
+				flag.Hidden = flag.Hidden || inHidden
+				flagSet.Add(flag)
 			}
 		}
 
-		// Also look for inline flags anywhere (lines starting with -)
-		if !inOptions && strings.HasPrefix(strings.TrimSpace(line), "-") {
-			if flag := p.parseFlagLine(line); flag != nil {
-				flagSet.Add(*flag)
+		// Also look for inline flag definitions anywhere: a line starting with -
+		// whose description is set off in its own column
+		if !inOptions && !inUsage && !inExamples && isFlagDefinition(trimmed) {
+			for _, flag := range p.parseFlagLines(line) {
+				flagSet.Add(flag)
 			}
 		}
 
@@ -427,6 +910,228 @@ func (p *Parser) parseHelpOutput(tool *types.Tool, output string) {
 			}
 		}
 	}
+
+	if len(tool.Subcommands) == 0 {
+		for _, name := range usageCommands {
+			cmdSet.Add(types.Command{Name: name})
+		}
+	}
+}
+
+// usageAlternationPattern matches a parenthesized group of lowercase words
+// separated by pipes, e.g. "(start|stop|restart)"
+var usageAlternationPattern = regexp.MustCompile(`\(([a-z][a-z0-9-]*(?:\|[a-z][a-z0-9-]*)+)\)`)
+
+// usageAlternatives returns the subcommands a usage line lists as alternation,
+// as in "Usage: tool (start|stop) [options]". Groups that follow a flag, like
+// "--mode (fast|slow)" or "--mode=(fast|slow)", are that flag's values.
+func usageAlternatives(line string) []string {
+	var names []string
+	for _, m := range usageAlternationPattern.FindAllStringSubmatchIndex(line, -1) {
+		before := line[:m[0]]
+		if strings.HasSuffix(before, "=") {
+			continue
+		}
+		if fields := strings.Fields(before); len(fields) > 0 {
+			// "[-q] (up|down)": a closed group doesn't take the value
+			prev := strings.TrimLeft(fields[len(fields)-1], "[(")
+			if strings.HasPrefix(prev, "-") && !strings.HasSuffix(prev, "]") && !strings.HasSuffix(prev, ")") {
+				continue
+			}
+		}
+		names = append(names, strings.Split(line[m[2]:m[3]], "|")...)
+	}
+	return names
+}
+
+// helpSummary returns the tool's one-line description from --help output: the
+// first prose line outside the usage block and before any section header or
+// list. Indented lines and "name  description" rows start a list, as when a
+// command list follows the usage line without a header. A leading "name - "
+// is dropped, and version banners like "tool 1.2.3" are skipped.
+func helpSummary(name, output string) string {
+	inUsage := false
+	for _, line := range skipBanner(strings.Split(output, "\n")) {
+		trimmed := strings.TrimSpace(line)
+		lower := strings.ToLower(trimmed)
+		// GNU tools follow the usage line directly with the summary; usage
+		// continuations are indented or start with "or:"
+		if inUsage && indentWidth(line) == 0 && !strings.HasPrefix(lower, "or:") {
+			inUsage = false
+		}
+
+		switch {
+		case trimmed == "":
+			inUsage = false
+			continue
+		case strings.HasPrefix(lower, "usage:"):
+			inUsage = true
+			continue
+		case inUsage, isBannerArt(line), strings.HasPrefix(trimmed, "-"):
+			continue
+		case isCommandsHeader(lower), isCommandCategoryHeader(trimmed), isOptionsHeader(lower), isPositionalsHeader(lower),
+			isExamplesHeader(lower), strings.HasSuffix(trimmed, ":"):
+			return ""
+		case indentWidth(line) > 0, isListRow(trimmed):
+			return ""
+		}
+
+		rest, ok := strings.CutPrefix(trimmed, name+" ")
+		if !ok {
+			return trimmed
+		}
+		if desc, ok := strings.CutPrefix(rest, "- "); ok {
+			return strings.TrimSpace(desc)
+		}
+		if !isVersionBanner(rest) {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+// isListRow reports whether a line is a "name  description" row: a single
+// word, then a column gap. Prose with two spaces after a period has several
+// words before the gap.
+func isListRow(trimmed string) bool {
+	name, _, ok := splitColumns(trimmed)
+	return ok && !strings.Contains(name, " ")
+}
+
+// isVersionBanner reports whether the text after a tool's name on a help line
+// is a version, e.g. "1.2.3", "v2.0 (build abc)" or "version 3"
+func isVersionBanner(rest string) bool {
+	rest = strings.TrimPrefix(strings.ToLower(rest), "version ")
+	rest = strings.TrimPrefix(rest, "v")
+	return rest != "" && unicode.IsDigit(rune(rest[0]))
+}
+
+// isCommandUsageLine reports whether a lowercased line is a usage line that
+// takes a subcommand, e.g. "usage: tool <command> [<args>]"
+func isCommandUsageLine(lower string) bool {
+	if !strings.HasPrefix(lower, "usage:") {
+		return false
+	}
+	return strings.Contains(lower, "<command>") ||
+		strings.Contains(lower, "<subcommand>") ||
+		strings.Contains(lower, "<cmd>")
+}
+
+// isPositionalsHeader reports whether a lowercased line starts an argparse
+// "positional arguments:" section
+func isPositionalsHeader(lower string) bool {
+	return strings.HasPrefix(lower, "positional arguments:")
+}
+
+// positionalSection parses an argparse "positional arguments:" section. A
+// "{init,run}" choices line lists subparsers, whose help entries follow it at
+// a deeper indent; any other entry is a positional metavar, not a command.
+type positionalSection struct {
+	p             *Parser
+	commands      *[]types.Command
+	cmdSet        *UniqueSet[types.Command]
+	choicesIndent int // indent of the current choices line, -1 outside one
+	last          int // index in commands of the last described choice, -1 if none
+	lastIndent    int
+}
+
+// newPositionalSection starts a section that adds subcommands to commands
+func (p *Parser) newPositionalSection(commands *[]types.Command, cmdSet *UniqueSet[types.Command]) *positionalSection {
+	return &positionalSection{p: p, commands: commands, cmdSet: cmdSet, choicesIndent: -1, last: -1}
+}
+
+// parseLine handles one non-empty line of the section
+func (s *positionalSection) parseLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	indent := indentWidth(line)
+
+	if names := argparseChoices(trimmed); names != nil {
+		for _, name := range names {
+			s.cmdSet.Add(types.Command{Name: name})
+		}
+		s.choicesIndent = indent
+		s.last = -1
+		return
+	}
+
+	if s.choicesIndent < 0 || indent <= s.choicesIndent {
+		config.Logf("Skipping positional argument: %q", trimmed)
+		s.choicesIndent = -1
+		s.last = -1
+		return
+	}
+
+	// Wrapped description of the previous choice
+	if s.last >= 0 && isCommandContinuation(line, s.lastIndent) {
+		cmd := &(*s.commands)[s.last]
+		cmd.Description = strings.TrimSpace(cmd.Description + " " + trimmed)
+		return
+	}
+
+	// Help for one of the choices, which the choices line already added
+	s.last = -1
+	cmd := s.p.parseCommandLine(line)
+	if cmd == nil {
+		return
+	}
+	s.cmdSet.Add(*cmd)
+	for i := range *s.commands {
+		if (*s.commands)[i].Name == cmd.Name {
+			if (*s.commands)[i].Description == "" {
+				(*s.commands)[i].Description = cmd.Description
+			}
+			s.last = i
+			s.lastIndent = indent
+		}
+	}
+}
+
+// argparseChoices extracts the names from a "{init,run,status}" choices entry,
+// returning nil if the line isn't one
+func argparseChoices(trimmed string) []string {
+	if !strings.HasPrefix(trimmed, "{") {
+		return nil
+	}
+	end := strings.Index(trimmed, "}")
+	if end < 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range strings.SplitSeq(trimmed[1:end], ",") {
+		name = strings.TrimSpace(name)
+		if !isValidCommandName(name) {
+			return nil
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// isCommandContinuation reports whether a line in a commands section continues
+// the previous command's wrapped description rather than naming a new command.
+// Continuations have no column gap between a name and a description, and are
+// either indented past the command name or are multi-word prose.
+func isCommandContinuation(line string, cmdIndent int) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "-") {
+		return false
+	}
+	if _, _, ok := splitColumns(trimmed); ok {
+		return false
+	}
+	if indentWidth(line) > cmdIndent {
+		return true
+	}
+	if _, _, ok := splitCommandSingleSpace(trimmed); ok {
+		return false
+	}
+	return strings.Contains(trimmed, " ")
+}
+
+// indentWidth returns the number of leading whitespace characters in a line
+func indentWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
 }
 
 // parseIndentedCommand parses git-style indented command lines
@@ -442,16 +1147,12 @@ func (p *Parser) parseIndentedCommand(line string) *types.Command {
 		return nil
 	}
 
-	// Look for pattern: word + multiple spaces + description
-	// Use regex-like split on 2+ spaces
-	parts := strings.SplitN(trimmed, "  ", 2)
-	if len(parts) < 2 {
+	// Look for pattern: word + multiple spaces (or a tab) + description
+	cmdName, desc, ok := splitColumns(trimmed)
+	if !ok {
 		return nil
 	}
 
-	cmdName := strings.TrimSpace(parts[0])
-	desc := strings.TrimSpace(parts[1])
-
 	// Validate command name: lowercase letters, numbers, hyphens
 	if !isValidCommandName(cmdName) {
 		return nil
@@ -483,14 +1184,16 @@ func (p *Parser) parseCommandLine(line string) *types.Command {
 		return nil
 	}
 
-	// Split on multiple spaces (command name vs description)
-	parts := strings.SplitN(trimmed, "  ", 2)
-	if len(parts) == 0 {
-		return nil
+	// Split on multiple spaces or a tab (command name vs description),
+	// falling back to a single space before a capitalized description
+	cmdPart, desc, ok := splitColumns(trimmed)
+	if !ok {
+		cmdPart, desc, _ = splitCommandSingleSpace(trimmed)
+	} else if desc != "" {
+		// gh-style "name:  Description"
+		cmdPart = strings.TrimSuffix(cmdPart, ":")
 	}
 
-	cmdPart := strings.TrimSpace(parts[0])
-
 	// Handle "command, c" or "c, command" format - extract name and aliases
 	var primaryName string
 	var aliases []string
@@ -518,6 +1221,9 @@ func (p *Parser) parseCommandLine(line string) *types.Command {
 				aliases = append(aliases, n)
 			}
 		}
+	} else if words := strings.Fields(cmdPart); ok && len(words) > 1 {
+		// "config view  Show config": a nested command listed flat
+		return p.parseCommandPath(words, desc)
 	} else {
 		// Validate: should be a simple word
 		if !isValidCommandName(cmdPart) {
@@ -526,15 +1232,81 @@ func (p *Parser) parseCommandLine(line string) *types.Command {
 		primaryName = cmdPart
 	}
 
-	cmd := &types.Command{
-		Name:    primaryName,
-		Aliases: aliases,
+	return &types.Command{
+		Name:        primaryName,
+		Aliases:     aliases,
+		Description: desc,
+	}
+}
+
+// parseCommandPath turns the words of a flat "get pods" entry into nested
+// commands, the last one getting desc. Paths deeper than MaxDepth, or with a
+// word that isn't a lowercase command name, are rejected.
+func (p *Parser) parseCommandPath(words []string, desc string) *types.Command {
+	if len(words) > p.config.MaxDepth {
+		return nil
+	}
+	for _, w := range words {
+		if !isValidCommandName(w) || w[0] < 'a' || w[0] > 'z' {
+			return nil
+		}
+	}
+	cmd := types.Command{Name: words[len(words)-1], Description: desc}
+	for i := len(words) - 2; i >= 0; i-- {
+		cmd = types.Command{Name: words[i], Subcommands: []types.Command{cmd}}
+	}
+	return &cmd
+}
+
+// parseFlagLines extracts one flag from a help line, or several when the line
+// lists mutually exclusive alternatives (e.g. "--json | --yaml  Output format")
+func (p *Parser) parseFlagLines(line string) []types.Flag {
+	if flags := p.parseExclusiveFlagLine(line); flags != nil {
+		return flags
 	}
-	if len(parts) > 1 {
-		cmd.Description = strings.TrimSpace(parts[1])
+	if flag := p.parseFlagLine(line); flag != nil {
+		return []types.Flag{*flag}
 	}
+	return nil
+}
 
-	return cmd
+// parseExclusiveFlagLine parses pipe-separated long flags sharing one description,
+// assigning them a common ExclusiveGroup. Returns nil if the line isn't of that form.
+func (p *Parser) parseExclusiveFlagLine(line string) []types.Flag {
+	trimmed := strings.TrimSpace(line)
+	flagPart, desc, _ := splitColumns(trimmed)
+	if !strings.Contains(flagPart, "|") {
+		return nil
+	}
+
+	alternatives := strings.Split(flagPart, "|")
+	if len(alternatives) < 2 {
+		return nil
+	}
+	// Only long flags qualify; "--format json|yaml" is a value list, not a group
+	for i, alt := range alternatives {
+		alternatives[i] = strings.TrimSpace(alt)
+		if !strings.HasPrefix(alternatives[i], "--") {
+			return nil
+		}
+	}
+
+	var flags []types.Flag
+	var names []string
+	for _, alt := range alternatives {
+		flag := p.parseFlagLine(alt + "  " + desc)
+		if flag == nil {
+			return nil
+		}
+		flags = append(flags, *flag)
+		names = append(names, flag.Name)
+	}
+
+	group := strings.Join(names, "|")
+	for i := range flags {
+		flags[i].ExclusiveGroup = group
+	}
+	return flags
 }
 
 // parseFlagLine extracts a flag from a help line
@@ -549,30 +1321,66 @@ func (p *Parser) parseFlagLine(line string) *types.Flag {
 	//   --flag              Description
 	//   -f                  Description
 	//   --flag=VALUE        Description
+	//   --flag[=VALUE]      Description (optional value)
 	//   --flag <value>      Description
 	//   --format=json|yaml  Description
 	//   --format {json,yaml} Description
+	//   --include PATTERN... Description (repeatable)
+	//   -v/--verbose        Description
+	//   --[no-]cache        Description (negatable)
+	//   -display HOST       Description (single-dash long, as X11 tools use)
+	//   +x                  Description (plus toggle)
 
-	if !strings.HasPrefix(trimmed, "-") {
+	if !strings.HasPrefix(trimmed, "-") && !strings.HasPrefix(trimmed, "+") {
 		return nil
 	}
 
-	flag := &types.Flag{}
+	flag := &types.Flag{Repeatable: isRepeatableFlag(trimmed)}
+
+	// Take out the default first, so "(default: fast)" after a choice list
+	// such as "--mode=fast|slow" is neither read as choices nor left in the
+	// description
+	trimmed, flag.Default = splitDefaultClause(trimmed)
 
-	// Split on multiple spaces to separate flag from description
-	parts := strings.SplitN(trimmed, "  ", 2)
-	flagPart := parts[0]
-	if len(parts) > 1 {
-		flag.Description = strings.TrimSpace(parts[1])
+	// Split on multiple spaces or a tab to separate flag from description,
+	// falling back to a single space before a capitalized description
+	flagPart, desc, ok := splitColumns(trimmed)
+	if !ok {
+		flagPart, desc, _ = splitFlagSingleSpace(trimmed)
 	}
+	flag.Description = desc
 
 	// Parse the flag part
-	for token := range strings.FieldsSeq(flagPart) {
+	tokens := splitSlashFlags(strings.Fields(flagPart))
+	prevWasFlag := false
+	for i, token := range tokens {
 		token = strings.TrimSuffix(token, ",")
+		// A trailing "..." on the metavar marks a repeatable flag
+		if strings.Contains(token, "...") || strings.Contains(token, "…") {
+			flag.Repeatable = true
+			token = strings.NewReplacer("...", "", "…", "").Replace(token)
+			if token == "" || token == "[]" {
+				continue
+			}
+		}
+		afterFlag := prevWasFlag
+		prevWasFlag = false
 
 		if strings.HasPrefix(token, "--") {
+			prevWasFlag = true
 			// Long flag
 			name := token
+			// Handle --[no-]flag: one flag that also accepts --no-flag
+			if rest, ok := strings.CutPrefix(name, "--[no-]"); ok && rest != "" {
+				name = "--" + rest
+				flag.Negatable = true
+			}
+			// Handle --flag[=VALUE]: the value is optional and must be attached
+			optional := false
+			if idx := strings.Index(name, "[="); idx > 0 {
+				optional = true
+				name = name[:idx] + strings.TrimSuffix(name[idx+1:], "]")
+			}
 			// Handle --flag=VALUE or --flag=val1|val2
 			if idx := strings.Index(name, "="); idx > 0 {
 				argPart := name[idx+1:]
@@ -592,11 +1400,35 @@ func (p *Parser) parseFlagLine(line string) *types.Flag {
 				} else {
 					flag.Arg = argPart
 				}
+				flag.OptionalArg = optional
 			}
 			flag.Name = name
 		} else if strings.HasPrefix(token, "-") && len(token) == 2 {
-			// Short flag
-			flag.Short = token
+			// Short flag; any after the first are aliases ("-h, -?")
+			if flag.Short == "" {
+				flag.Short = token
+			} else if token != flag.Short && !slices.Contains(flag.ShortAliases, token) {
+				flag.ShortAliases = append(flag.ShortAliases, token)
+			}
+			prevWasFlag = true
+		} else if isSingleDashLongFlag(token) || isPlusFlag(token) {
+			// "-display" or "+x": a whole word, not a cluster of short flags
+			if flag.Name == "" {
+				flag.Name = token
+			}
+			prevWasFlag = true
+		} else if afterFlag && isBareMetavar(token) {
+			// GNU style "-o FILE, --output FILE": the metavar may repeat, keep the first
+			if flag.Arg == "" {
+				flag.Arg = token
+			}
+		} else if afterFlag && ok && i == len(tokens)-1 && isLowerMetavar(token) {
+			// BSD style "-f file  Read from file": a bare lowercase word is only
+			// taken as the arg name when the column gap follows it, otherwise it
+			// may be the first word of the description
+			if flag.Arg == "" {
+				flag.Arg = token
+			}
 		} else if strings.HasPrefix(token, "<") || strings.HasPrefix(token, "[") {
 			// Argument placeholder, may contain choices
 			argContent := strings.Trim(token, "<>[]")
@@ -643,31 +1475,288 @@ func (p *Parser) parseFlagLine(line string) *types.Flag {
 	return flag
 }
 
+// isSingleDashLongFlag reports whether token is a long option written with
+// one dash, as X11 tools write "-display" and find its primaries ("-name")
+func isSingleDashLongFlag(token string) bool {
+	name, ok := strings.CutPrefix(token, "-")
+	return ok && len(name) > 1 && isFlagWord(name)
+}
+
+// isPlusFlag reports whether token is a "+" option such as "+x" or "+ls",
+// which usually undoes the matching "-" option
+func isPlusFlag(token string) bool {
+	name, ok := strings.CutPrefix(token, "+")
+	return ok && name != "" && isFlagWord(name)
+}
+
+// firstField returns the first whitespace-separated field of s, or ""
+func firstField(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// isFlagWord reports whether s, a flag without its prefix, is a letter
+// followed by letters, digits, "-" or "_"
+func isFlagWord(s string) bool {
+	if s == "" || !unicode.IsLetter(rune(s[0])) {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// splitSlashFlags splits slash-joined flag forms ("-v/--verbose") into
+// separate tokens. Tokens whose parts aren't all flags, such as
+// "--prefix=/usr", are kept whole.
+func splitSlashFlags(tokens []string) []string {
+	var result []string
+	for _, token := range tokens {
+		parts := strings.Split(strings.TrimSuffix(token, ","), "/")
+		if len(parts) < 2 || slices.ContainsFunc(parts, func(part string) bool {
+			return len(part) < 2 || part[0] != '-'
+		}) {
+			result = append(result, token)
+			continue
+		}
+		result = append(result, parts...)
+	}
+	return result
+}
+
+// isExamplesHeader reports whether a lowercased line heads a section of
+// example invocations, e.g. "Examples:" or "EXAMPLES"
+func isExamplesHeader(lower string) bool {
+	lower = strings.TrimSuffix(lower, ":")
+	return lower == "example" || lower == "examples"
+}
+
+// isFlagDefinition reports whether a line outside an options section defines a
+// flag: it starts with a flag and has a description after a column gap, unlike
+// a wrapped synopsis fragment such as "-o <output>"
+func isFlagDefinition(trimmed string) bool {
+	if !strings.HasPrefix(trimmed, "-") {
+		return false
+	}
+	_, desc, ok := splitColumns(trimmed)
+	return ok && desc != ""
+}
+
+// isCommandsHeader reports whether a lowercased, trimmed help line starts a commands section
+func isCommandsHeader(lower string) bool {
+	return strings.HasPrefix(lower, "commands:") ||
+		strings.HasPrefix(lower, "available commands:") ||
+		strings.HasPrefix(lower, "subcommands:") ||
+		lower == "commands"
+}
+
+// isCommandCategoryHeader reports whether a trimmed help line heads one of
+// several grouped command lists, as in gh ("CORE COMMANDS") and docker
+// ("Management Commands:"): a few ALL-CAPS or Title-Case words ending in
+// "Commands", with an optional colon. Prose like "Run these commands:" has
+// lowercase words and doesn't count.
+func isCommandCategoryHeader(trimmed string) bool {
+	words := strings.Fields(strings.TrimSuffix(trimmed, ":"))
+	if len(words) < 2 || len(words) > 4 {
+		return false
+	}
+	if last := words[len(words)-1]; last != "COMMANDS" && last != "Commands" {
+		return false
+	}
+	for _, word := range words {
+		first, _ := utf8.DecodeRuneInString(word)
+		if !unicode.IsUpper(first) {
+			return false
+		}
+		for _, r := range word {
+			if !unicode.IsLetter(r) && r != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isOptionsHeader reports whether a lowercased, trimmed help line starts an options section
+func isOptionsHeader(lower string) bool {
+	return strings.HasPrefix(lower, "options:") ||
+		strings.HasPrefix(lower, "flags:") ||
+		strings.HasPrefix(lower, "global options:") ||
+		strings.HasPrefix(lower, "global flags:") ||
+		lower == "options" || lower == "flags"
+}
+
+// isHiddenFlagsHeader reports whether a lowercased, trimmed help line starts a
+// section of flags that are hidden from normal use, e.g. "Hidden Flags:"
+func isHiddenFlagsHeader(lower string) bool {
+	lower = strings.TrimSuffix(lower, ":")
+	return lower == "hidden flags" || lower == "hidden options"
+}
+
+// skipBanner drops ASCII art printed before the real help. Only lines before
+// the first usage line or section header are considered, and only up to the
+// last one that looks like art, so prose and command lists at the top of
+// help output without a banner are kept. Output with no such marker is
+// returned unchanged.
+func skipBanner(lines []string) []string {
+	lastArt := -1
+	for i, line := range lines {
+		lower := strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(lower, "usage:") || isCommandsHeader(lower) || isCommandCategoryHeader(strings.TrimSpace(line)) ||
+			isOptionsHeader(lower) || isPositionalsHeader(lower) {
+			if lastArt >= 0 {
+				config.Logf("Skipping %d lines of banner art", lastArt+1)
+			}
+			return lines[lastArt+1:]
+		}
+		if isBannerArt(line) {
+			lastArt = i
+		}
+	}
+	return lines
+}
+
+// isBannerArt reports whether a line is mostly drawing characters: at least
+// half its non-space characters, and at least three, are neither letters nor digits
+func isBannerArt(line string) bool {
+	symbols, total := 0, 0
+	for _, r := range line {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		total++
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			symbols++
+		}
+	}
+	return symbols >= 3 && symbols*2 >= total
+}
+
 // parseManPage extracts structure from man page output
 func (p *Parser) parseManPage(tool *types.Tool, output string) {
+	p.parseManSections(tool.Name, &tool.GlobalFlags, &tool.Subcommands, output, false)
+}
+
+// manSummary returns the description after the dash in a man page's NAME
+// section, e.g. "manage services" from "svc - manage services". Wrapped
+// lines are joined.
+func manSummary(output string) string {
+	var name strings.Builder
+	inName := false
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !inName {
+			inName = trimmed == "NAME" && line[0] != ' ' && line[0] != '\t'
+			continue
+		}
+		if trimmed == "" {
+			if name.Len() > 0 {
+				break
+			}
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			break
+		}
+		name.WriteString(trimmed + " ")
+	}
+
+	_, desc, ok := strings.Cut(name.String(), " - ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(desc)
+}
+
+// parseManSections adds the flags in a man page's OPTIONS section to flags,
+// and the entries of a COMMANDS section to commands. For a tool's own page
+// (name set), bracketed flags in the SYNOPSIS are added too. When aggressive,
+// flags listed under DESCRIPTION count as well, and commands are read from
+// every "... COMMANDS" section; git-style "name-sub(1)" entries are shortened
+// to "sub".
+func (p *Parser) parseManSections(name string, flags *[]types.Flag, commands *[]types.Command, output string, aggressive bool) {
 	lines := strings.Split(output, "\n")
 
-	// Use set for O(1) duplicate detection
-	flagSet := newFlagSet(&tool.GlobalFlags)
+	// Use sets for O(1) duplicate detection
+	flagSet := newFlagSet(flags)
+	var cmdSet *UniqueSet[types.Command]
+	if commands != nil {
+		cmdSet = newCommandSet(commands)
+	}
 
 	inOptions := false
+	inCommands := false
+	inSynopsis := false
+	var synopsis strings.Builder
 	var currentFlag *types.Flag
+	var currentCmd *types.Command
+	entryIndent := -1 // indentation of command entries in the current COMMANDS section
 
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
 		// Detect OPTIONS section
 		if trimmed == "OPTIONS" || strings.HasPrefix(trimmed, "OPTIONS") {
-			inOptions = true
+			inOptions, inCommands, inSynopsis = true, false, false
 			continue
 		}
 
-		// Detect end of OPTIONS (next major section)
-		if inOptions && len(line) > 0 && line[0] != ' ' && line[0] != '\t' {
-			if isManSectionHeader(trimmed) {
-				inOptions = false
+		// Other section headers start in the first column
+		if len(line) > 0 && line[0] != ' ' && line[0] != '\t' {
+			switch {
+			case name != "" && trimmed == "SYNOPSIS":
+				inOptions, inCommands, inSynopsis = false, false, true
+				continue
+			case aggressive && trimmed == "DESCRIPTION":
+				inOptions, inCommands, inSynopsis = true, false, false
+				continue
+			case cmdSet != nil && (trimmed == "COMMANDS" || aggressive && isManCommandsHeader(trimmed)):
+				inOptions, inCommands, inSynopsis = false, true, false
+				currentCmd, entryIndent = nil, -1
+				continue
+			case isManSectionHeader(trimmed), inCommands, inSynopsis:
+				inOptions, inCommands, inSynopsis = false, false, false
+				continue
+			}
+		}
+
+		if inSynopsis {
+			synopsis.WriteString(trimmed + " ")
+			continue
+		}
+
+		if inCommands {
+			if trimmed == "" {
+				continue
+			}
+			indent := len(line) - len(strings.TrimLeft(line, " \t"))
+			if entryIndent >= 0 && indent > entryIndent {
+				// Description under the last entry
+				if currentCmd != nil && currentCmd.Description == "" {
+					currentCmd.Description = trimmed
+				}
+				continue
+			}
+			currentCmd = nil
+			cmd := p.parseManCommandEntry(name, trimmed)
+			if cmd == nil {
+				// Subheadings and prose between entries
 				continue
 			}
+			entryIndent = indent
+			prevLen := len(*commands)
+			cmdSet.Add(*cmd)
+			if len(*commands) > prevLen {
+				currentCmd = &(*commands)[len(*commands)-1]
+			}
+			continue
 		}
 
 		if !inOptions {
@@ -676,12 +1765,12 @@ func (p *Parser) parseManPage(tool *types.Tool, output string) {
 
 		// In OPTIONS section, look for flag definitions
 		// Man pages typically have flags at a certain indentation
-		if strings.HasPrefix(trimmed, "-") {
+		if strings.HasPrefix(trimmed, "-") || isPlusFlag(firstField(trimmed)) {
 			if flag := p.parseFlagLine(line); flag != nil {
-				prevLen := len(tool.GlobalFlags)
+				prevLen := len(*flags)
 				flagSet.Add(*flag)
-				if len(tool.GlobalFlags) > prevLen {
-					currentFlag = &tool.GlobalFlags[len(tool.GlobalFlags)-1]
+				if len(*flags) > prevLen {
+					currentFlag = &(*flags)[len(*flags)-1]
 				}
 			}
 		} else if currentFlag != nil && trimmed != "" && currentFlag.Description == "" {
@@ -689,6 +1778,128 @@ func (p *Parser) parseManPage(tool *types.Tool, output string) {
 			currentFlag.Description = trimmed
 		}
 	}
+
+	// Added last so that OPTIONS entries, which carry descriptions, win
+	for _, spec := range synopsisFlagSpecs(synopsis.String()) {
+		if flag := p.parseFlagLine(spec); flag != nil {
+			flagSet.Add(*flag)
+		}
+	}
+}
+
+// parseManCommandEntry parses one entry line of a man page COMMANDS section.
+// The name may be a git-style reference ("git-add(1)"), and the description
+// must be in a separate column or on the following lines, so subheadings and
+// prose like "Ancillary Commands" aren't taken for commands.
+func (p *Parser) parseManCommandEntry(tool, trimmed string) *types.Command {
+	first, rest, _ := strings.Cut(trimmed, " ")
+	entry := manCommandName(tool, first)
+	if rest != "" {
+		entry += " " + rest
+	}
+	if _, _, ok := splitColumns(entry); !ok {
+		// A bare name, or names separated by commas ("remove, rm")
+		fields := strings.Fields(entry)
+		for _, field := range fields[:len(fields)-1] {
+			if !strings.HasSuffix(field, ",") {
+				return nil
+			}
+		}
+	}
+	return p.parseCommandLine(entry)
+}
+
+// synopsisFlagSpecs returns the flag specs in the bracketed groups of a man
+// page SYNOPSIS, splitting alternatives: "[-C <path>] [-p|--paginate]" gives
+// "-C <path>", "-p", and "--paginate". Nested brackets stay with their group,
+// so "[--exec-path[=<path>]]" keeps its optional argument.
+func synopsisFlagSpecs(synopsis string) []string {
+	var specs []string
+	depth, start := 0, 0
+	for i, c := range synopsis {
+		switch c {
+		case '[':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case ']':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth > 0 {
+				continue
+			}
+			for _, alt := range splitTopLevel(synopsis[start:i], '|') {
+				alt = strings.TrimSpace(alt)
+				if !strings.HasPrefix(alt, "-") || alt == "--" {
+					continue
+				}
+				// Nested groups other than an optional "[=value]" are sub-options
+				if strings.Contains(alt, "[") && !strings.Contains(alt, "[=") {
+					continue
+				}
+				specs = append(specs, firstSynopsisArg(alt))
+			}
+		}
+	}
+	return specs
+}
+
+// firstSynopsisArg cuts a spec's separate argument down to its first
+// placeholder, so "-c <name>=<value>" gives "-c <name>" and the metavar is a
+// single name
+func firstSynopsisArg(spec string) string {
+	flag, arg, ok := strings.Cut(spec, " ")
+	if !ok {
+		return spec
+	}
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "<") {
+		if end := strings.IndexByte(arg, '>'); end > 0 {
+			arg = arg[:end+1]
+		}
+	} else if eq := strings.IndexByte(arg, '='); eq > 0 {
+		arg = arg[:eq]
+	}
+	return flag + " " + arg
+}
+
+// splitTopLevel splits s on sep wherever it is outside brackets
+func splitTopLevel(s string, sep rune) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range s {
+		switch {
+		case c == '[':
+			depth++
+		case c == ']' && depth > 0:
+			depth--
+		case c == sep && depth == 0:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// isManCommandsHeader reports whether s heads a man page section listing
+// commands, e.g. "COMMANDS" or git's "HIGH-LEVEL COMMANDS"
+func isManCommandsHeader(s string) bool {
+	return strings.HasSuffix(s, "COMMANDS") && s == strings.ToUpper(s)
+}
+
+// manCommandName strips a man page reference down to a command name:
+// "git-add(1)" becomes "add" for tool git
+func manCommandName(tool, entry string) string {
+	if idx := strings.IndexByte(entry, '('); idx > 0 && strings.HasSuffix(entry, ")") {
+		entry = entry[:idx]
+	}
+	if tool != "" {
+		entry = strings.TrimPrefix(entry, tool+"-")
+	}
+	return entry
 }
 
 // isValidCommandName checks if a string looks like a valid command name
@@ -705,6 +1916,198 @@ func isValidCommandName(s string) bool {
 	return true
 }
 
+// splitColumns splits a help line into its name column and description at the
+// first tab or run of two or more spaces. ok is false if neither separator appears.
+func splitColumns(trimmed string) (name, desc string, ok bool) {
+	idx := strings.Index(trimmed, "  ")
+	if tab := strings.IndexByte(trimmed, '\t'); tab >= 0 && (idx < 0 || tab < idx) {
+		idx = tab
+	}
+	if idx < 0 {
+		return trimmed, "", false
+	}
+	return strings.TrimSpace(trimmed[:idx]), strings.TrimSpace(trimmed[idx:]), true
+}
+
+// alignDescriptionColumns widens the gap before the description of flag lines
+// whose flag nearly fills the description column. Help formatters start every
+// description in a block at the same offset, sized to the longest flag, so that
+// flag is left with a single space and splitColumns can't find the description.
+// The offset is inferred per block of indented lines from the flag lines that
+// do split, and needs at least two of them to agree.
+func alignDescriptionColumns(lines []string) []string {
+	aligned := make([]string, len(lines))
+	copy(aligned, lines)
+
+	start := 0
+	for i := 0; i <= len(lines); i++ {
+		if i < len(lines) && strings.TrimSpace(lines[i]) != "" && indentWidth(lines[i]) > 0 {
+			continue
+		}
+		if col := descriptionColumn(lines[start:i]); col > 0 {
+			for j := start; j < i; j++ {
+				aligned[j] = splitAtColumn(lines[j], col)
+			}
+		}
+		start = i + 1
+	}
+	return aligned
+}
+
+// descriptionColumn returns the offset most flag lines in a block start their
+// description at, or 0 if fewer than two lines agree
+func descriptionColumn(block []string) int {
+	counts := make(map[int]int)
+	best := 0
+	for _, line := range block {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "-") || strings.ContainsRune(line, '\t') {
+			continue
+		}
+		gap := strings.Index(trimmed, "  ")
+		if gap < 0 {
+			continue
+		}
+		col := indentWidth(line) + gap + len(trimmed[gap:]) - len(strings.TrimLeft(trimmed[gap:], " "))
+		counts[col]++
+		if counts[col] > counts[best] || counts[col] == counts[best] && col < best {
+			best = col
+		}
+	}
+	if counts[best] < 2 {
+		return 0
+	}
+	return best
+}
+
+// splitAtColumn adds a space before the description of a flag line whose
+// description starts at col after a single space
+func splitAtColumn(line string, col int) string {
+	trimmed := strings.TrimSpace(line)
+	if !strings.HasPrefix(trimmed, "-") || len(line) <= col || col <= indentWidth(line) {
+		return line
+	}
+	if _, _, ok := splitColumns(trimmed); ok {
+		return line
+	}
+	if line[col-1] != ' ' || line[col] == ' ' || line[col-2] == ' ' {
+		return line
+	}
+	return line[:col] + " " + line[col:]
+}
+
+// splitFlagSingleSpace splits "-v, --verbose Enable output" where a single space
+// separates the flag spec from its description. The description must start with
+// a capitalized word, and spec tokens (flags, placeholders, uppercase metavars)
+// are never split off, so "-o FILE" and "--color [WHEN]" stay whole.
+func splitFlagSingleSpace(trimmed string) (spec, desc string, ok bool) {
+	fields := strings.Fields(trimmed)
+	for i := 1; i < len(fields); i++ {
+		if isFlagSpecToken(fields[i]) {
+			continue
+		}
+		if !startsWithCapitalWord(fields[i]) {
+			break
+		}
+		return strings.Join(fields[:i], " "), strings.Join(fields[i:], " "), true
+	}
+	return trimmed, "", false
+}
+
+// splitCommandSingleSpace splits "deploy Deploy the app" or "remove, rm Delete it"
+// where a single space separates the command name from a capitalized description
+func splitCommandSingleSpace(trimmed string) (name, desc string, ok bool) {
+	fields := strings.Fields(trimmed)
+	i := 0
+	for i < len(fields) {
+		token := fields[i]
+		if !isValidCommandName(strings.TrimSuffix(token, ",")) {
+			return trimmed, "", false
+		}
+		i++
+		// A trailing comma means an alias follows
+		if !strings.HasSuffix(token, ",") {
+			break
+		}
+	}
+	if i >= len(fields) || !startsWithCapitalWord(fields[i]) {
+		return trimmed, "", false
+	}
+	return strings.Join(fields[:i], " "), strings.Join(fields[i:], " "), true
+}
+
+// isFlagSpecToken reports whether a token belongs to a flag spec rather than prose
+func isFlagSpecToken(token string) bool {
+	token = strings.TrimSuffix(token, ",")
+	if token == "" || token == "|" {
+		return true
+	}
+	if strings.ContainsAny(token[:1], "-<[{(") {
+		return true
+	}
+	return isBareMetavar(token)
+}
+
+// startsWithCapitalWord reports whether a token looks like the first word of a
+// sentence, e.g. "Enable" (uppercase metavars like FILE don't count)
+func startsWithCapitalWord(token string) bool {
+	if len(token) < 2 || token[0] < 'A' || token[0] > 'Z' {
+		return false
+	}
+	return token[1] >= 'a' && token[1] <= 'z'
+}
+
+// isBareMetavar checks if a token is an unbracketed uppercase metavar like FILE or NUM
+func isBareMetavar(s string) bool {
+	hasLetter := false
+	for _, c := range s {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			hasLetter = true
+		case (c >= '0' && c <= '9') || c == '_' || c == '-':
+		default:
+			return false
+		}
+	}
+	return hasLetter
+}
+
+// isLowerMetavar reports whether s is a bare lowercase argument name such as
+// "file" or "out-dir"
+func isLowerMetavar(s string) bool {
+	if s == "" || s[0] < 'a' || s[0] > 'z' {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// repeatablePhrases mark a flag that may be given more than once when they
+// appear in its help line
+var repeatablePhrases = []string{
+	"repeatable", "may be repeated", "can be repeated",
+	"may be given multiple times", "can be given multiple times",
+	"may be specified multiple times", "can be specified multiple times",
+	"may be used multiple times", "can be used multiple times",
+}
+
+// isRepeatableFlag reports whether a flag's help line says it can be repeated
+func isRepeatableFlag(line string) bool {
+	lower := strings.ToLower(line)
+	for _, phrase := range repeatablePhrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
 // isManSectionHeader checks if a line is a man page section header
 func isManSectionHeader(s string) bool {
 	headers := []string{
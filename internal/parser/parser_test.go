@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -56,6 +60,314 @@ Options:
 	}
 }
 
+func TestParseHelpOutput_UsageAlternation(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{
+			name:   "subcommands as alternation",
+			output: "Usage: tool (start|stop|status) [options]\n\nOptions:\n  -v, --verbose  Be verbose\n",
+			want:   []string{"start", "stop", "status"},
+		},
+		{
+			name:   "alternation on a usage continuation line",
+			output: "Usage:\n  tool [-q] (up|down)\n",
+			want:   []string{"up", "down"},
+		},
+		{
+			name:   "flag values are not subcommands",
+			output: "Usage: tool [--format (json|yaml)] --mode=(fast|slow) FILE\n",
+			want:   nil,
+		},
+		{
+			name:   "commands section wins",
+			output: "Usage: tool (start|stop)\n\nCommands:\n  run    Run it\n",
+			want:   []string{"run"},
+		},
+		{
+			name:   "alternation outside the usage is ignored",
+			output: "Usage: tool FILE\n\nThe mode (fast|slow) is picked automatically.\n",
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &types.Tool{Name: "tool"}
+			New().parseHelpOutput(tool, tt.output)
+
+			var got []string
+			for _, cmd := range tool.Subcommands {
+				got = append(got, cmd.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("subcommands = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHelpOutput_InlineFlagsSkipInvocations(t *testing.T) {
+	helpOutput := `Usage: mytool [-v] [-o <output>]
+       -o <output> input.txt
+
+Process input files.
+
+  -q, --quiet    Suppress progress output
+  -n <count>
+  --dry-run Show what would change
+
+Examples:
+  mytool -v input.txt
+  -o result.txt input.txt
+`
+	tool := &types.Tool{Name: "mytool"}
+	New().parseHelpOutput(tool, helpOutput)
+
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--quiet" {
+		t.Errorf("expected only --quiet, got %+v", tool.GlobalFlags)
+	}
+
+	cmd := &types.Command{Name: "run"}
+	New().parseSubcommandOutput(cmd, helpOutput)
+	if len(cmd.Flags) != 1 || cmd.Flags[0].Name != "--quiet" {
+		t.Errorf("subcommand: expected only --quiet, got %+v", cmd.Flags)
+	}
+}
+
+func TestParseHelpOutput_AlignedDescriptionColumn(t *testing.T) {
+	helpOutput := `Usage: mytool [OPTIONS]
+
+Options:
+  -v, --verbose                        enable verbose output
+  -o, --output <file>                  write output to file
+      --kube-apiserver-address=ADDRESS address of the API server
+      --max-concurrent-requests <num> limit requests in flight
+  -h, --help                           show help
+`
+	tool := &types.Tool{Name: "mytool"}
+	New().parseHelpOutput(tool, helpOutput)
+
+	want := map[string]struct{ arg, desc string }{
+		"--verbose":                 {"", "enable verbose output"},
+		"--output":                  {"file", "write output to file"},
+		"--kube-apiserver-address":  {"ADDRESS", "address of the API server"},
+		"--max-concurrent-requests": {},
+		"--help":                    {"", "show help"},
+	}
+	if len(tool.GlobalFlags) != len(want) {
+		t.Fatalf("expected %d flags, got %+v", len(want), tool.GlobalFlags)
+	}
+	for _, f := range tool.GlobalFlags {
+		w, ok := want[f.Name]
+		if !ok {
+			t.Errorf("unexpected flag %q", f.Name)
+			continue
+		}
+		if f.Name == "--max-concurrent-requests" {
+			// Overflows the column, so there is nothing to align to
+			continue
+		}
+		if f.Arg != w.arg || f.Description != w.desc {
+			t.Errorf("%s: arg=%q desc=%q, want arg=%q desc=%q", f.Name, f.Arg, f.Description, w.arg, w.desc)
+		}
+	}
+}
+
+func TestAlignDescriptionColumns(t *testing.T) {
+	lines := []string{
+		"Options:",
+		"  -a, --all          show all",
+		"  --really-long-flag show it",
+		"  -b                 brief",
+		"",
+		"  --other-block-flag lone line",
+	}
+	got := alignDescriptionColumns(lines)
+	if got[2] != "  --really-long-flag  show it" {
+		t.Errorf("expected split at column, got %q", got[2])
+	}
+	if got[5] != lines[5] {
+		t.Errorf("line outside an aligned block changed: %q", got[5])
+	}
+	if lines[2] != "  --really-long-flag show it" {
+		t.Error("input slice was modified")
+	}
+}
+
+func TestParseHelpOutput_WrappedCommandDescription(t *testing.T) {
+	helpOutput := `Usage: mytool COMMAND
+
+Commands:
+  deploy      Deploy the current build to the configured
+              environment and wait for health checks
+  rollback    Revert to the previous release
+  status
+`
+
+	p := New()
+	tool := &types.Tool{Name: "mytool"}
+	p.parseHelpOutput(tool, helpOutput)
+
+	if len(tool.Subcommands) != 3 {
+		t.Fatalf("expected 3 subcommands, got %d: %+v", len(tool.Subcommands), tool.Subcommands)
+	}
+
+	expected := map[string]string{
+		"deploy":   "Deploy the current build to the configured environment and wait for health checks",
+		"rollback": "Revert to the previous release",
+		"status":   "",
+	}
+	for _, cmd := range tool.Subcommands {
+		want, ok := expected[cmd.Name]
+		if !ok {
+			t.Errorf("unexpected subcommand %q", cmd.Name)
+			continue
+		}
+		if cmd.Description != want {
+			t.Errorf("%s description = %q, want %q", cmd.Name, cmd.Description, want)
+		}
+	}
+}
+
+func TestParseHelpOutput_ArgparsePositionals(t *testing.T) {
+	helpOutput := `usage: deploytool [-h] [--verbose] {init,push,status,shell} ... target
+
+Deploy things.
+
+positional arguments:
+  {init,push,status,shell}
+                        sub-command help
+    init                Create a config file
+    push                Push the build to the
+                        target environment
+    status              Show deployment status
+  target                Environment to deploy to
+
+options:
+  -h, --help            show this help message and exit
+  --verbose             print more output
+`
+
+	p := New()
+	tool := &types.Tool{Name: "deploytool"}
+	p.parseHelpOutput(tool, helpOutput)
+
+	expected := map[string]string{
+		"init":   "Create a config file",
+		"push":   "Push the build to the target environment",
+		"status": "Show deployment status",
+		"shell":  "", // no help= given to the subparser
+	}
+	if len(tool.Subcommands) != len(expected) {
+		t.Fatalf("expected %d subcommands, got %d: %+v", len(expected), len(tool.Subcommands), tool.Subcommands)
+	}
+	for _, cmd := range tool.Subcommands {
+		want, ok := expected[cmd.Name]
+		if !ok {
+			t.Errorf("unexpected subcommand %q", cmd.Name)
+			continue
+		}
+		if cmd.Description != want {
+			t.Errorf("%s description = %q, want %q", cmd.Name, cmd.Description, want)
+		}
+	}
+
+	if len(tool.GlobalFlags) != 2 {
+		t.Errorf("expected 2 flags, got %d: %+v", len(tool.GlobalFlags), tool.GlobalFlags)
+	}
+}
+
+func TestParseSubcommandOutput_ArgparsePositionals(t *testing.T) {
+	helpOutput := `usage: deploytool remote [-h] {add,remove} ...
+
+positional arguments:
+  {add,remove}
+    add         Add a remote
+    remove      Remove a remote
+
+options:
+  -h, --help    show this help message and exit
+`
+
+	p := New()
+	cmd := &types.Command{Name: "remote"}
+	p.parseSubcommandOutput(cmd, helpOutput)
+
+	if len(cmd.Subcommands) != 2 || cmd.Subcommands[0].Name != "add" || cmd.Subcommands[1].Description != "Remove a remote" {
+		t.Errorf("unexpected subcommands: %+v", cmd.Subcommands)
+	}
+}
+
+func TestArgparseChoices(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"{init,run}", []string{"init", "run"}},
+		{"{init,run}  sub-command help", []string{"init", "run"}},
+		{"target", nil},
+		{"{a b}", nil},
+	}
+	for _, tt := range tests {
+		if got := argparseChoices(tt.line); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("argparseChoices(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseHelpOutput_UsageCommandList(t *testing.T) {
+	helpOutput := `Usage: mytool <command> [<args>]
+       mytool --version
+
+  init    Create a new project
+  build   Compile the project
+  serve
+
+Run 'mytool help <command>' for details.
+
+Options:
+  -h, --help   Show help
+`
+
+	p := New()
+	tool := &types.Tool{Name: "mytool"}
+	p.parseHelpOutput(tool, helpOutput)
+
+	var names []string
+	for _, cmd := range tool.Subcommands {
+		names = append(names, cmd.Name)
+	}
+	if strings.Join(names, ",") != "init,build,serve" {
+		t.Errorf("subcommands = %v, want [init build serve]", names)
+	}
+	if len(tool.Subcommands) > 0 && tool.Subcommands[1].Description != "Compile the project" {
+		t.Errorf("build description = %q", tool.Subcommands[1].Description)
+	}
+}
+
+func TestParseHelpOutput_UsageCommandListWithHeader(t *testing.T) {
+	// A real Commands: header after the usage line must not double-count
+	helpOutput := `usage: mytool <command>
+
+A tool for things.
+
+Commands:
+  init    Create a new project
+  build   Compile the project
+`
+
+	p := New()
+	tool := &types.Tool{Name: "mytool"}
+	p.parseHelpOutput(tool, helpOutput)
+
+	if len(tool.Subcommands) != 2 {
+		t.Errorf("expected 2 subcommands, got %d: %+v", len(tool.Subcommands), tool.Subcommands)
+	}
+}
+
 func TestParseHelpOutput_GitStyleIndented(t *testing.T) {
 	helpOutput := `usage: git [--version] [--help] [-C <path>] <command> [<args>]
 
@@ -192,6 +504,44 @@ func TestParseFlagLine(t *testing.T) {
 			wantArg:   "file",
 			wantDesc:  "Output file path",
 		},
+		{
+			name:      "GNU repeated metavar",
+			line:      "  -o FILE, --output FILE   Write output to FILE",
+			wantName:  "--output",
+			wantShort: "-o",
+			wantArg:   "FILE",
+			wantDesc:  "Write output to FILE",
+		},
+		{
+			name:      "GNU repeated numeric metavar",
+			line:      "  -n NUM, --number NUM     Print NUM lines",
+			wantName:  "--number",
+			wantShort: "-n",
+			wantArg:   "NUM",
+			wantDesc:  "Print NUM lines",
+		},
+		{
+			name:      "slash separated short and long",
+			line:      "  -v/--verbose          Enable verbose output",
+			wantName:  "--verbose",
+			wantShort: "-v",
+			wantDesc:  "Enable verbose output",
+		},
+		{
+			name:      "slash separated with argument",
+			line:      "  -o/--output <file>    Write to file",
+			wantName:  "--output",
+			wantShort: "-o",
+			wantArg:   "file",
+			wantDesc:  "Write to file",
+		},
+		{
+			name:     "slash inside a value is not split",
+			line:     "      --prefix=/usr/local   Install prefix",
+			wantName: "--prefix",
+			wantArg:  "/usr/local",
+			wantDesc: "Install prefix",
+		},
 		{
 			name:    "not a flag",
 			line:    "  command     Do something",
@@ -626,11 +976,57 @@ func TestNewWithPartialConfig(t *testing.T) {
 	if cfg.HelpTimeout != 5*time.Second {
 		t.Errorf("expected default HelpTimeout 5s for zero value, got %v", cfg.HelpTimeout)
 	}
+	if cfg.VersionTimeout != 2*time.Second {
+		t.Errorf("expected default VersionTimeout 2s for zero value, got %v", cfg.VersionTimeout)
+	}
+	if cfg.HelpWorkers != 4 {
+		t.Errorf("expected default HelpWorkers 4 for zero value, got %d", cfg.HelpWorkers)
+	}
 	if len(cfg.VersionCmds) != 4 {
 		t.Errorf("expected default VersionCmds for empty slice, got %d", len(cfg.VersionCmds))
 	}
 }
 
+func TestParseNestedSubcommands_Concurrent(t *testing.T) {
+	var commands []types.Command
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		commands = append(commands, types.Command{Name: name})
+	}
+
+	var running, peak atomic.Int32
+	p := New(ParserConfig{HelpWorkers: 3})
+	capture := &rawCapture{sections: make(map[string]string)}
+	var globals []types.Flag
+	p.parseNestedSubcommands(&globals, "", commands, 1, capture, func(cmdPath string) (string, string) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return "Options:\n  --" + cmdPath + "-flag  Flag for " + cmdPath + "\n", ""
+	})
+
+	if got := peak.Load(); got < 2 || got > 3 {
+		t.Errorf("expected 2-3 concurrent help commands, peak was %d", got)
+	}
+	for _, cmd := range commands {
+		if len(cmd.Flags) != 1 || cmd.Flags[0].Name != "--"+cmd.Name+"-flag" {
+			t.Errorf("command %s got flags %+v", cmd.Name, cmd.Flags)
+		}
+	}
+	var want []string
+	for _, cmd := range commands {
+		want = append(want, cmd.Name+" "+rawHelpLabel)
+	}
+	if strings.Join(capture.labels, ",") != strings.Join(want, ",") {
+		t.Errorf("capture order = %v, want %v", capture.labels, want)
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	cfg := DefaultConfig()
 	if cfg.MaxDepth != 2 {
@@ -639,6 +1035,12 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.HelpTimeout != 5*time.Second {
 		t.Errorf("DefaultConfig HelpTimeout should be 5s, got %v", cfg.HelpTimeout)
 	}
+	if cfg.VersionTimeout != 2*time.Second {
+		t.Errorf("DefaultConfig VersionTimeout should be 2s, got %v", cfg.VersionTimeout)
+	}
+	if !cfg.DetectVersion {
+		t.Error("DefaultConfig should detect versions")
+	}
 	expectedCmds := []string{"--version", "-V", "version", "-v"}
 	if len(cfg.VersionCmds) != len(expectedCmds) {
 		t.Errorf("DefaultConfig VersionCmds length mismatch: got %d, want %d", len(cfg.VersionCmds), len(expectedCmds))
@@ -648,6 +1050,9 @@ func TestDefaultConfig(t *testing.T) {
 			t.Errorf("DefaultConfig VersionCmds[%d] = %q, want %q", i, cfg.VersionCmds[i], cmd)
 		}
 	}
+	if cfg.DeepDiscovery {
+		t.Error("DefaultConfig DeepDiscovery should be false")
+	}
 }
 
 func TestMaxSubcommandDepth(t *testing.T) {
@@ -688,6 +1093,64 @@ func TestParseFlagLine_EdgeCases(t *testing.T) {
 	}
 }
 
+func TestParseFlagLine_ShortAliases(t *testing.T) {
+	p := New()
+
+	flag := p.parseFlagLine("  -h, -?     Show help")
+	if flag == nil {
+		t.Fatal("expected flag, got nil")
+	}
+	if flag.Name != "-h" || flag.Short != "" {
+		t.Errorf("got name %q short %q, want name -h", flag.Name, flag.Short)
+	}
+	if len(flag.ShortAliases) != 1 || flag.ShortAliases[0] != "-?" {
+		t.Errorf("short aliases = %v, want [-?]", flag.ShortAliases)
+	}
+	if flag.Description != "Show help" {
+		t.Errorf("description = %q", flag.Description)
+	}
+
+	// With a long form, the first short stays Short
+	flag = p.parseFlagLine("  -h, -?, --help  Show help")
+	if flag == nil || flag.Name != "--help" || flag.Short != "-h" || len(flag.ShortAliases) != 1 {
+		t.Errorf("unexpected flag: %+v", flag)
+	}
+}
+
+func TestParseFlagLine_Repeatable(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantName string
+		wantArg  string
+	}{
+		{"ellipsis metavar", "  --include PATTERN...  Only search matching files", "--include", "PATTERN"},
+		{"ellipsis placeholder", "  -e, --exclude <glob>...  Skip matching files", "--exclude", "glob"},
+		{"repeatable in description", "  -I DIR  Add an include directory (repeatable)", "-I", "DIR"},
+		{"multiple times phrase", "  --tag TAG  Tag the image; may be given multiple times", "--tag", "TAG"},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := p.parseFlagLine(tt.line)
+			if flag == nil {
+				t.Fatal("expected flag, got nil")
+			}
+			if flag.Name != tt.wantName || flag.Arg != tt.wantArg {
+				t.Errorf("got name %q arg %q, want %q %q", flag.Name, flag.Arg, tt.wantName, tt.wantArg)
+			}
+			if !flag.Repeatable {
+				t.Error("expected flag to be repeatable")
+			}
+		})
+	}
+
+	if flag := p.parseFlagLine("  --output FILE  Write to FILE"); flag == nil || flag.Repeatable {
+		t.Errorf("plain flag marked repeatable: %+v", flag)
+	}
+}
+
 func TestParseFlagLine_ArgumentValues(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -923,6 +1386,85 @@ func TestParseCommandLine_ShortAlias(t *testing.T) {
 	}
 }
 
+func TestCommandSet_MergesDuplicateAliases(t *testing.T) {
+	// A command listed bare first and with an alias later keeps the alias
+	var commands []types.Command
+	set := newCommandSet(&commands)
+	set.Add(types.Command{Name: "install"})
+	if set.Add(types.Command{Name: "install", Aliases: []string{"in"}, Description: "Install a package"}) {
+		t.Error("expected duplicate not to be appended")
+	}
+	set.Add(types.Command{Name: "install", Aliases: []string{"in", "i"}})
+
+	if len(commands) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(commands))
+	}
+	if !slices.Equal(commands[0].Aliases, []string{"in", "i"}) {
+		t.Errorf("expected aliases [in i], got %v", commands[0].Aliases)
+	}
+	if commands[0].Description != "Install a package" {
+		t.Errorf("expected description from the second listing, got %q", commands[0].Description)
+	}
+}
+
+func TestParseCommandLine_CommandPath(t *testing.T) {
+	p := New()
+	cmd := p.parseCommandLine("  config view   Show config")
+	if cmd == nil {
+		t.Fatal("expected command, got nil")
+	}
+	if cmd.Name != "config" || cmd.Description != "" {
+		t.Errorf("expected parent 'config' without description, got %q %q", cmd.Name, cmd.Description)
+	}
+	if len(cmd.Subcommands) != 1 || cmd.Subcommands[0].Name != "view" || cmd.Subcommands[0].Description != "Show config" {
+		t.Errorf("expected subcommand 'view' described 'Show config', got %+v", cmd.Subcommands)
+	}
+
+	for _, line := range []string{
+		"  a b c   Deeper than MaxDepth",
+		"  Show all   Prose, not a command path",
+		"  get <name>   Placeholder argument",
+	} {
+		if cmd := p.parseCommandLine(line); cmd != nil {
+			t.Errorf("parseCommandLine(%q) = %+v, want nil", line, cmd)
+		}
+	}
+}
+
+func TestParseHelpOutput_CommandPaths(t *testing.T) {
+	output := `Usage: tool <command>
+
+Commands:
+  config        Manage config
+  config view   Show config
+  config set    Set a value
+  get pods      List pods
+`
+	tool := &types.Tool{Name: "tool"}
+	New().parseHelpOutput(tool, output)
+
+	got := make(map[string][]string)
+	var names []string
+	for _, cmd := range tool.Subcommands {
+		names = append(names, cmd.Name)
+		for _, sub := range cmd.Subcommands {
+			got[cmd.Name] = append(got[cmd.Name], sub.Name)
+		}
+	}
+	if !slices.Equal(names, []string{"config", "get"}) {
+		t.Fatalf("subcommands = %v, want [config get]", names)
+	}
+	if tool.Subcommands[0].Description != "Manage config" {
+		t.Errorf("config description = %q, want %q", tool.Subcommands[0].Description, "Manage config")
+	}
+	if !slices.Equal(got["config"], []string{"view", "set"}) {
+		t.Errorf("config subcommands = %v, want [view set]", got["config"])
+	}
+	if !slices.Equal(got["get"], []string{"pods"}) {
+		t.Errorf("get subcommands = %v, want [pods]", got["get"])
+	}
+}
+
 func TestParseIndentedCommand_LongName(t *testing.T) {
 	// Command name at max length limit
 	p := New()
@@ -980,3 +1522,1017 @@ func TestParse_InputValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestHelpAllArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+	}{
+		{"git style", "See 'git help <command>' or 'git help -a' for more", []string{"help", "-a"}},
+		{"long form", "Run 'tool help --all' to list every command", []string{"help", "--all"}},
+		{"not mentioned", "Usage: tool [options]\n  -h, --help  Show help", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := helpAllArgs(tt.output)
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("helpAllArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHelpAllOutput(t *testing.T) {
+	output := `See 'git help <command>' to read about a specific subcommand
+
+Main Porcelain Commands
+   add                     Add file contents to the index
+   commit                  Record changes to the repository
+
+Low-level Commands / Interrogators
+   cat-file                Provide contents or details of repository objects
+`
+
+	p := New()
+	tool := &types.Tool{
+		Name:        "git",
+		Subcommands: []types.Command{{Name: "add", Description: "Add file contents to the index"}},
+	}
+	p.parseHelpAllOutput(tool, output)
+
+	if len(tool.Subcommands) != 3 {
+		t.Fatalf("expected 3 subcommands (no duplicate add), got %d", len(tool.Subcommands))
+	}
+	if tool.Subcommands[2].Name != "cat-file" {
+		t.Errorf("expected hidden command cat-file, got %q", tool.Subcommands[2].Name)
+	}
+}
+
+func TestParseFlagLines_ExclusiveGroup(t *testing.T) {
+	p := New()
+
+	flags := p.parseFlagLines("  --json | --yaml       Output format")
+	if len(flags) != 2 {
+		t.Fatalf("expected 2 flags, got %d", len(flags))
+	}
+	if flags[0].Name != "--json" || flags[1].Name != "--yaml" {
+		t.Errorf("unexpected names: %q, %q", flags[0].Name, flags[1].Name)
+	}
+	for _, f := range flags {
+		if f.ExclusiveGroup != "--json|--yaml" {
+			t.Errorf("%s: expected group --json|--yaml, got %q", f.Name, f.ExclusiveGroup)
+		}
+		if f.Description != "Output format" {
+			t.Errorf("%s: expected shared description, got %q", f.Name, f.Description)
+		}
+	}
+
+	// Value choices after a flag are not an exclusive group
+	flags = p.parseFlagLines("  --format json|yaml    Output format")
+	if len(flags) != 1 || flags[0].ExclusiveGroup != "" {
+		t.Errorf("expected single ungrouped flag, got %+v", flags)
+	}
+}
+
+func TestRunHelp_PlainEnvironment(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "envtool")
+	content := "#!/bin/sh\necho \"NO_COLOR=$NO_COLOR TERM=$TERM COLUMNS=$COLUMNS LC_ALL=$LC_ALL HOME=${HOME:+set}\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLUMNS", "80")
+
+	output, err := New().runHelp(script)
+	if err != nil {
+		t.Fatalf("runHelp failed: %v", err)
+	}
+
+	want := "NO_COLOR=1 TERM=dumb COLUMNS=1000 LC_ALL=C HOME=set"
+	if strings.TrimSpace(output) != want {
+		t.Errorf("runHelp output = %q, want %q", strings.TrimSpace(output), want)
+	}
+}
+
+func TestParseFlagLine_TabAndSingleSpace(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantName  string
+		wantShort string
+		wantArg   string
+		wantDesc  string
+	}{
+		{"tab separated", "  -v, --verbose\tEnable verbose output", "--verbose", "-v", "", "Enable verbose output"},
+		{"tab after metavar", "\t--output FILE\tWrite to FILE", "--output", "", "FILE", "Write to FILE"},
+		{"single space", "  -q, --quiet Suppress output", "--quiet", "-q", "", "Suppress output"},
+		{"single space after metavar", "  -o FILE Write output to FILE", "-o", "", "FILE", "Write output to FILE"},
+		{"single space after placeholder", "  --color [WHEN] Colorize output", "--color", "", "WHEN", "Colorize output"},
+		{"spec without description", "  -o FILE, --output FILE", "--output", "-o", "FILE", ""},
+		{"lowercase prose is not split", "  --dry-run only print actions", "--dry-run", "", "", ""},
+		{"lowercase short arg", "  -f file  Read from file", "-f", "", "file", "Read from file"},
+		{"lowercase long arg", "  --input path  Input path", "--input", "", "path", "Input path"},
+		{"lowercase arg after both forms", "  -o, --output dir  Write into dir", "--output", "-o", "dir", "Write into dir"},
+		{"lowercase word without gap", "  -f file read from file", "-f", "", "", ""},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := p.parseFlagLine(tt.line)
+			if flag == nil {
+				t.Fatal("expected flag, got nil")
+			}
+			if flag.Name != tt.wantName {
+				t.Errorf("name: got %q, want %q", flag.Name, tt.wantName)
+			}
+			if flag.Short != tt.wantShort {
+				t.Errorf("short: got %q, want %q", flag.Short, tt.wantShort)
+			}
+			if flag.Arg != tt.wantArg {
+				t.Errorf("arg: got %q, want %q", flag.Arg, tt.wantArg)
+			}
+			if flag.Description != tt.wantDesc {
+				t.Errorf("description: got %q, want %q", flag.Description, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestParseCommandLine_TabAndSingleSpace(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        string
+		wantName    string
+		wantAliases []string
+		wantDesc    string
+		wantNil     bool
+	}{
+		{name: "tab separated", line: "  deploy\tDeploy to production", wantName: "deploy", wantDesc: "Deploy to production"},
+		{name: "single space", line: "  deploy Deploy to production", wantName: "deploy", wantDesc: "Deploy to production"},
+		{name: "single space with alias", line: "  remove, rm Delete a resource", wantName: "remove", wantAliases: []string{"rm"}, wantDesc: "Delete a resource"},
+		{name: "lowercase prose", line: "  use this to deploy", wantNil: true},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := p.parseCommandLine(tt.line)
+			if tt.wantNil {
+				if cmd != nil {
+					t.Errorf("expected nil, got %+v", cmd)
+				}
+				return
+			}
+			if cmd == nil {
+				t.Fatal("expected command, got nil")
+			}
+			if cmd.Name != tt.wantName {
+				t.Errorf("name: got %q, want %q", cmd.Name, tt.wantName)
+			}
+			if strings.Join(cmd.Aliases, ",") != strings.Join(tt.wantAliases, ",") {
+				t.Errorf("aliases: got %v, want %v", cmd.Aliases, tt.wantAliases)
+			}
+			if cmd.Description != tt.wantDesc {
+				t.Errorf("description: got %q, want %q", cmd.Description, tt.wantDesc)
+			}
+		})
+	}
+}
+
+func TestParserConfig_WithOverride(t *testing.T) {
+	base := DefaultConfig()
+
+	cfg := base.WithOverride(types.ToolOverride{})
+	if cfg.MaxDepth != base.MaxDepth || len(cfg.VersionCmds) != len(base.VersionCmds) || cfg.ForceSource != "" {
+		t.Errorf("empty override changed config: %+v", cfg)
+	}
+
+	cfg = base.WithOverride(types.ToolOverride{
+		VersionCmds:   []string{"-version"},
+		MaxDepth:      4,
+		ForceSource:   "man",
+		ExtraHelpArgs: []string{"--all"},
+	})
+	if cfg.MaxDepth != 4 {
+		t.Errorf("MaxDepth = %d, want 4", cfg.MaxDepth)
+	}
+	if strings.Join(cfg.VersionCmds, ",") != "-version" {
+		t.Errorf("VersionCmds = %v, want [-version]", cfg.VersionCmds)
+	}
+	if cfg.ForceSource != "man" {
+		t.Errorf("ForceSource = %q, want man", cfg.ForceSource)
+	}
+	if strings.Join(cfg.ExtraHelpArgs, ",") != "--all" {
+		t.Errorf("ExtraHelpArgs = %v, want [--all]", cfg.ExtraHelpArgs)
+	}
+	if base.MaxDepth != 2 {
+		t.Error("WithOverride modified the base config")
+	}
+}
+
+func TestParse_ForceSourceAndExtraHelpArgs(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "overridetool")
+	content := `#!/bin/sh
+if [ "$2" = "--all" ]; then
+  printf 'Options:\n  --hidden   Only shown with --all\n'
+else
+  printf 'Options:\n  --visible  Always shown\n'
+fi
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.VersionCmds = []string{"--no-such-version-flag"}
+	cfg.ExtraHelpArgs = []string{"--all"}
+	tool, err := New(cfg).Parse("overridetool", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--hidden" {
+		t.Errorf("expected --hidden from extra help args, got %+v", tool.GlobalFlags)
+	}
+
+	cfg.ForceSource = "man"
+	tool, err = New(cfg).Parse("overridetool", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tool.Source != "none" || len(tool.GlobalFlags) != 0 {
+		t.Errorf("ForceSource=man should skip --help, got source=%q flags=%+v", tool.Source, tool.GlobalFlags)
+	}
+}
+
+func TestParse_ForceSourceManSkipsSubcommandHelp(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	scripts := map[string]string{
+		"mantool": "#!/bin/sh\necho \"$@\" >> " + calls + "\nprintf 'Options:\\n  --visible  Always shown\\n'\n",
+		// Stands in for man(1): only the tool itself has a page
+		"man": "#!/bin/sh\n[ \"$1\" = mantool ] || exit 16\nprintf 'COMMANDS\\n       build\\n              Build it\\n\\nOPTIONS\\n       --verbose\\n              Be loud\\n'\n",
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	cfg := DefaultConfig()
+	cfg.DetectVersion = false
+	cfg.ForceSource = "man"
+	tool, err := New(cfg).Parse("mantool", filepath.Join(dir, "mantool"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(tool.Subcommands) != 1 || tool.Subcommands[0].Name != "build" {
+		t.Fatalf("expected build from the man page, got %+v", tool.Subcommands)
+	}
+	if _, err := os.Stat(calls); !os.IsNotExist(err) {
+		got, _ := os.ReadFile(calls)
+		t.Errorf("expected neither the tool nor its subcommands to run, got calls %q", got)
+	}
+}
+
+func TestParse_SkipHelp(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "skiptool")
+	log := filepath.Join(dir, "calls")
+	content := `#!/bin/sh
+echo "$@" >> ` + log + `
+printf 'Options:\n  --visible  Always shown\n'
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.DetectVersion = false
+	cfg.SkipHelp = true
+	// An override forcing help must not bring --help back
+	cfg.ForceSource = "help"
+	tool, err := New(cfg).Parse("skiptool", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tool.Source != "none" || len(tool.GlobalFlags) != 0 {
+		t.Errorf("SkipHelp should skip --help, got source=%q flags=%+v", tool.Source, tool.GlobalFlags)
+	}
+	if _, err := os.Stat(log); !os.IsNotExist(err) {
+		calls, _ := os.ReadFile(log)
+		t.Errorf("expected the tool never to run, got calls %q", calls)
+	}
+}
+
+func TestParse_DetectVersion(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "versiontool")
+	calls := filepath.Join(dir, "calls")
+	content := `#!/bin/sh
+echo "$1" >> "` + calls + `"
+case "$1" in
+  --version) echo "versiontool 1.2.3" ;;
+  *) printf 'Options:\n  --verbose  Be loud\n' ;;
+esac
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.ForceSource = "help"
+	tool, err := New(cfg).Parse("versiontool", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tool.Version != "1.2.3" {
+		t.Errorf("Version = %q, want 1.2.3", tool.Version)
+	}
+
+	os.Remove(calls)
+	cfg.DetectVersion = false
+	tool, err = New(cfg).Parse("versiontool", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tool.Version != "" {
+		t.Errorf("Version = %q, want none with detection disabled", tool.Version)
+	}
+	data, _ := os.ReadFile(calls)
+	if strings.Contains(string(data), "--version") {
+		t.Errorf("tool was run with --version: %q", data)
+	}
+	if len(tool.GlobalFlags) != 1 {
+		t.Errorf("expected help to be parsed, got %+v", tool.GlobalFlags)
+	}
+}
+
+func TestRunSubcommandHelp_NoPager(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "pagertool")
+	// Simulates a tool that pipes help through $PAGER, which would block on stdin
+	// if PAGER were an interactive pager
+	content := "#!/bin/sh\nread -r line\necho \"stdin=${line:-eof} PAGER=$PAGER GIT_PAGER=$GIT_PAGER\"\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PAGER", "less")
+	t.Setenv("GIT_PAGER", "less")
+
+	output := New().runSubcommandHelp(script, "log")
+
+	want := "stdin=eof PAGER=cat GIT_PAGER=cat"
+	if strings.TrimSpace(output) != want {
+		t.Errorf("runSubcommandHelp output = %q, want %q", strings.TrimSpace(output), want)
+	}
+}
+
+func TestParseFlagLine_OptionalValue(t *testing.T) {
+	tests := []struct {
+		line       string
+		wantName   string
+		wantArg    string
+		wantValues []string
+	}{
+		{"      --color[=WHEN]         colorize the output", "--color", "WHEN", nil},
+		{"  -c, --color[=always|never]  Colorize output", "--color", "value", []string{"always", "never"}},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			flag := p.parseFlagLine(tt.line)
+			if flag == nil {
+				t.Fatal("expected flag, got nil")
+			}
+			if flag.Name != tt.wantName {
+				t.Errorf("name: got %q, want %q", flag.Name, tt.wantName)
+			}
+			if flag.Arg != tt.wantArg {
+				t.Errorf("arg: got %q, want %q", flag.Arg, tt.wantArg)
+			}
+			if !flag.OptionalArg {
+				t.Error("expected OptionalArg to be set")
+			}
+			if strings.Join(flag.ArgumentValues, ",") != strings.Join(tt.wantValues, ",") {
+				t.Errorf("values: got %v, want %v", flag.ArgumentValues, tt.wantValues)
+			}
+		})
+	}
+
+	if flag := p.parseFlagLine("  --output=FILE  Write to FILE"); flag == nil || flag.OptionalArg {
+		t.Errorf("--output=FILE should take a required value, got %+v", flag)
+	}
+}
+
+func TestParseFlagLine_Negatable(t *testing.T) {
+	p := New()
+
+	flag := p.parseFlagLine("  -c, --[no-]cache    Use the build cache")
+	if flag == nil {
+		t.Fatal("expected flag, got nil")
+	}
+	if flag.Name != "--cache" || flag.Short != "-c" || !flag.Negatable {
+		t.Errorf("got %+v, want negatable --cache with short -c", flag)
+	}
+	if got := flag.NegatedName(); got != "--no-cache" {
+		t.Errorf("NegatedName() = %q, want --no-cache", got)
+	}
+
+	if flag := p.parseFlagLine("  --no-cache    Skip the build cache"); flag == nil || flag.Negatable {
+		t.Errorf("plain --no-cache should not be negatable, got %+v", flag)
+	}
+
+	tool := &types.Tool{Name: "build"}
+	p.parseHelpOutput(tool, "Usage: build [OPTIONS]\n\nOptions:\n  --[no-]cache    Use the build cache\n  --verbose       Be verbose\n")
+	if len(tool.GlobalFlags) != 2 || tool.GlobalFlags[0].Name != "--cache" || !tool.GlobalFlags[0].Negatable {
+		t.Errorf("expected a single negatable --cache flag, got %+v", tool.GlobalFlags)
+	}
+}
+
+func TestParseFlagLine_SingleDashAndPlus(t *testing.T) {
+	p := New()
+
+	flag := p.parseFlagLine("  -display HOST   X server to contact")
+	if flag == nil {
+		t.Fatal("expected flag, got nil")
+	}
+	if flag.Name != "-display" || flag.Short != "" || flag.Arg != "HOST" || flag.Description != "X server to contact" {
+		t.Errorf("got %+v, want -display taking HOST", flag)
+	}
+
+	flag = p.parseFlagLine("  +x  enable")
+	if flag == nil {
+		t.Fatal("expected flag, got nil")
+	}
+	if flag.Name != "+x" || flag.Arg != "" || flag.Description != "enable" {
+		t.Errorf("got %+v, want +x toggle", flag)
+	}
+
+	tool := &types.Tool{Name: "xterm"}
+	p.parseHelpOutput(tool, "Usage: xterm [options]\n\nOptions:\n  -geometry GEOM   Window size\n  -ls              Login shell\n  +ls              No login shell\n")
+	var names []string
+	for _, f := range tool.GlobalFlags {
+		names = append(names, f.Name)
+	}
+	if got := strings.Join(names, " "); got != "-geometry -ls +ls" {
+		t.Errorf("got flags %q, want \"-geometry -ls +ls\"", got)
+	}
+}
+
+func TestParseHelpOutput_HiddenFlags(t *testing.T) {
+	p := New()
+	output := `Usage: tool [OPTIONS]
+
+Options:
+  -v, --verbose       Be verbose
+      --trace         Trace every call (hidden)
+      --dump          [HIDDEN] Dump internal state
+
+Hidden Flags:
+      --profile FILE  Write a CPU profile
+`
+	tool := &types.Tool{Name: "tool"}
+	p.parseHelpOutput(tool, output)
+	p.postProcess(tool)
+
+	want := map[string]bool{"--verbose": false, "--trace": true, "--dump": true, "--profile": true}
+	if len(tool.GlobalFlags) != len(want) {
+		t.Fatalf("flags = %+v, want %d", tool.GlobalFlags, len(want))
+	}
+	for _, flag := range tool.GlobalFlags {
+		if hidden, ok := want[flag.Name]; !ok || flag.Hidden != hidden {
+			t.Errorf("%s: hidden = %v, want %v", flag.Name, flag.Hidden, hidden)
+		}
+		if strings.Contains(strings.ToLower(flag.Description), "hidden") {
+			t.Errorf("%s: marker left in description %q", flag.Name, flag.Description)
+		}
+	}
+
+	// A subcommand's hidden section doesn't hide the options after it
+	cmd := &types.Command{Name: "run"}
+	p.parseSubcommandOutput(cmd, "Hidden Options:\n  --debug  Debug run\n\nOptions:\n  --fast  Run fast\n")
+	if len(cmd.Flags) != 2 || !cmd.Flags[0].Hidden || cmd.Flags[1].Hidden {
+		t.Errorf("run flags = %+v, want hidden --debug and visible --fast", cmd.Flags)
+	}
+}
+
+const gitStyleManPage = `GIT(1)                            Git Manual                            GIT(1)
+
+NAME
+       git - the stupid content tracker
+
+SYNOPSIS
+       git [--version] [--help] [-C <path>] <command> [<args>]
+
+DESCRIPTION
+       Git is a fast, scalable, distributed revision control system.
+
+       --version
+           Prints the Git suite version.
+
+GIT COMMANDS
+       We divide Git into high level ("porcelain") commands and low level
+       ("plumbing") commands.
+
+HIGH-LEVEL COMMANDS
+   Main porcelain commands
+       git-add(1)
+           Add file contents to the index.
+
+       git-commit(1)
+           Record changes to the repository.
+
+   Ancillary Commands
+       git-config(1)
+           Get and set repository or global options.
+
+SEE ALSO
+       gittutorial(7)
+`
+
+func TestParseManSections_GitStyleCommands(t *testing.T) {
+	p := New(ParserConfig{ManFallback: true})
+	tool := &types.Tool{Name: "git"}
+	p.parseOutputs(tool, "", gitStyleManPage)
+
+	if tool.Source != "man" {
+		t.Errorf("source = %q, want man", tool.Source)
+	}
+	want := map[string]string{
+		"add":    "Add file contents to the index.",
+		"commit": "Record changes to the repository.",
+		"config": "Get and set repository or global options.",
+	}
+	if len(tool.Subcommands) != len(want) {
+		t.Fatalf("expected %d subcommands, got %+v", len(want), tool.Subcommands)
+	}
+	for _, cmd := range tool.Subcommands {
+		if desc, ok := want[cmd.Name]; !ok || cmd.Description != desc {
+			t.Errorf("unexpected subcommand %q: %q", cmd.Name, cmd.Description)
+		}
+	}
+	if len(tool.GlobalFlags) != 3 || tool.GlobalFlags[0].Name != "--version" || tool.GlobalFlags[0].Description != "Prints the Git suite version." {
+		t.Errorf("expected --version from DESCRIPTION, then SYNOPSIS flags, got %+v", tool.GlobalFlags)
+	}
+}
+
+func TestParseManSections_InlineCommandDescriptions(t *testing.T) {
+	manOutput := `COMMANDS
+       start    Start the service
+       stop
+              Stop the service
+
+OPTIONS
+       -q, --quiet    Be quiet
+`
+	p := New(ParserConfig{ManFallback: true})
+	tool := &types.Tool{Name: "svc"}
+	p.parseOutputs(tool, "", manOutput)
+
+	if len(tool.Subcommands) != 2 {
+		t.Fatalf("expected 2 subcommands, got %+v", tool.Subcommands)
+	}
+	if tool.Subcommands[0].Description != "Start the service" || tool.Subcommands[1].Description != "Stop the service" {
+		t.Errorf("unexpected descriptions: %+v", tool.Subcommands)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--quiet" {
+		t.Errorf("unexpected flags: %+v", tool.GlobalFlags)
+	}
+}
+
+func TestParseManSections_CommandsNeedFallback(t *testing.T) {
+	// Without man_fallback, or when --help worked, only a plain COMMANDS
+	// section supplies commands; flags still come from the SYNOPSIS
+	tool := &types.Tool{Name: "git"}
+	New().parseOutputs(tool, "", gitStyleManPage)
+	if len(tool.Subcommands) != 0 || len(tool.GlobalFlags) != 3 {
+		t.Errorf("expected only SYNOPSIS flags without fallback, got %+v %+v", tool.Subcommands, tool.GlobalFlags)
+	}
+
+	tool = &types.Tool{Name: "git"}
+	New(ParserConfig{ManFallback: true}).parseOutputs(tool, "Usage: git\n", gitStyleManPage)
+	if len(tool.Subcommands) != 0 {
+		t.Errorf("expected no man commands when --help worked, got %+v", tool.Subcommands)
+	}
+}
+
+func TestParseManPage_SynopsisAndCommands(t *testing.T) {
+	manOutput := `SVC(1)                       User Commands                      SVC(1)
+
+NAME
+       svc - manage services
+
+SYNOPSIS
+       svc [-q|--quiet] [-C <dir>] [--color[=<when>]]
+           [--log-file <file>] [--] <command> [<args>]
+
+COMMANDS
+       start, up    Start the service
+       stop
+              Stop the service
+       status       Show service status
+
+   Maintenance Commands
+       prune        Remove stopped services
+
+OPTIONS
+       -q, --quiet
+              Suppress output.
+
+       --log-file <file>
+              Write logs to <file>.
+
+SEE ALSO
+       systemctl(1)
+`
+	tool := &types.Tool{Name: "svc"}
+	New().parseManPage(tool, manOutput)
+
+	wantCmds := map[string]string{
+		"start":  "Start the service",
+		"stop":   "Stop the service",
+		"status": "Show service status",
+		"prune":  "Remove stopped services",
+	}
+	if len(tool.Subcommands) != len(wantCmds) {
+		t.Fatalf("expected %d subcommands, got %+v", len(wantCmds), tool.Subcommands)
+	}
+	for _, cmd := range tool.Subcommands {
+		if desc, ok := wantCmds[cmd.Name]; !ok || cmd.Description != desc {
+			t.Errorf("unexpected subcommand %q: %q", cmd.Name, cmd.Description)
+		}
+	}
+	if aliases := tool.Subcommands[0].Aliases; len(aliases) != 1 || aliases[0] != "up" {
+		t.Errorf("expected start alias up, got %v", aliases)
+	}
+
+	flags := make(map[string]types.Flag)
+	for _, f := range tool.GlobalFlags {
+		flags[f.Name] = f
+	}
+	if f := flags["--quiet"]; f.Short != "-q" || f.Description != "Suppress output." {
+		t.Errorf("expected --quiet from OPTIONS, got %+v", f)
+	}
+	if f := flags["--log-file"]; f.Arg != "file" || f.Description == "" {
+		t.Errorf("expected --log-file from OPTIONS, got %+v", f)
+	}
+	if f, ok := flags["-C"]; !ok || f.Arg != "dir" {
+		t.Errorf("expected -C <dir> from SYNOPSIS, got %+v", f)
+	}
+	if f, ok := flags["--color"]; !ok || !f.OptionalArg {
+		t.Errorf("expected --color with optional arg from SYNOPSIS, got %+v", f)
+	}
+	if _, ok := flags["--"]; ok {
+		t.Error("-- should not be a flag")
+	}
+	if len(tool.GlobalFlags) != 5 {
+		t.Errorf("expected 5 flags, got %+v", tool.GlobalFlags)
+	}
+}
+
+func TestSynopsisFlagSpecs(t *testing.T) {
+	got := synopsisFlagSpecs("git [--version] [-C <path>] [-c <name>=<value>] [--exec-path[=<path>]] [-p|--paginate | -P] [--foo [--bar]] <command> [<args>]")
+	want := []string{"--version", "-C <path>", "-c <name>", "--exec-path[=<path>]", "-p", "--paginate", "-P"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("synopsisFlagSpecs = %q, want %q", got, want)
+	}
+	if flag := New().parseFlagLine(got[2]); flag == nil || flag.Arg != "name" {
+		t.Errorf("expected -c with metavar name, got %+v", flag)
+	}
+}
+
+func TestParseFromText_SubcommandManPage(t *testing.T) {
+	text := "==> man <==\n" + gitStyleManPage + `==> commit man <==
+GIT-COMMIT(1)                     Git Manual                     GIT-COMMIT(1)
+
+OPTIONS
+       -a, --all
+           Stage modified and deleted files.
+
+       -m <msg>, --message=<msg>
+           Use the given <msg> as the commit message.
+`
+	tool, err := New(ParserConfig{ManFallback: true}).ParseFromText("git", text)
+	if err != nil {
+		t.Fatalf("ParseFromText failed: %v", err)
+	}
+
+	var commit *types.Command
+	for i := range tool.Subcommands {
+		if tool.Subcommands[i].Name == "commit" {
+			commit = &tool.Subcommands[i]
+		}
+	}
+	if commit == nil {
+		t.Fatalf("commit not found in %+v", tool.Subcommands)
+	}
+	names := make(map[string]string)
+	for _, f := range commit.Flags {
+		names[f.Name] = f.Description
+	}
+	if names["--all"] != "Stage modified and deleted files." {
+		t.Errorf("expected --all with description, got %+v", commit.Flags)
+	}
+	if _, ok := names["--message"]; !ok {
+		t.Errorf("expected --message, got %+v", commit.Flags)
+	}
+}
+
+func TestParseHelpOutput_SkipsBanner(t *testing.T) {
+	output := `  _____                    __
+ |_   _|__ _ __ _ __ __ _ / _| ___  _ __ _ __ ___
+   | |/ _ \ '__| '__/ _' | |_ / _ \| '__| '_ ' _ \
+   |_|\___|_|  |_|  \__,_|_|  \___/|_|  |_| |_| |_|
+   --=== v1.0 ===--
+
+Usage: terraform [global options] <subcommand> [args]
+
+Commands:
+  init     Prepare your working directory
+  plan     Show changes required
+
+Options:
+  -chdir=DIR  Switch to a different working directory
+`
+	p := New()
+	tool := &types.Tool{Name: "terraform"}
+	p.parseHelpOutput(tool, output)
+
+	if len(tool.Subcommands) != 2 {
+		t.Errorf("expected 2 subcommands, got %+v", tool.Subcommands)
+	}
+	for _, flag := range tool.GlobalFlags {
+		if flag.Name != "-chdir" {
+			t.Errorf("banner art parsed as flag: %+v", flag)
+		}
+	}
+}
+
+func TestSkipBanner_KeepsPlainHelp(t *testing.T) {
+	for _, output := range []string{
+		// A command list first, with no banner before the usage line
+		"  build    Build it\n  test     Test it\n\nUsage: tool <command>\n",
+		// Art-like lines but no usage line or header to anchor on
+		"-----\n  build    Build it\n",
+	} {
+		lines := strings.Split(output, "\n")
+		if got := skipBanner(lines); len(got) != len(lines) {
+			t.Errorf("skipBanner dropped lines from %q: %q", output, got)
+		}
+	}
+}
+
+func TestHelpSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "argparse description after usage",
+			output: "usage: mytool [-h] [-v] FILE\n\nConvert files between formats.\n\noptions:\n  -h, --help  show this help message and exit\n",
+			want:   "Convert files between formats.",
+		},
+		{
+			name:   "cobra long description before usage",
+			output: "Mytool manages your services.\n\nUsage:\n  mytool [command]\n\nAvailable Commands:\n  start       Start a service\n",
+			want:   "Mytool manages your services.",
+		},
+		{
+			name:   "name dash summary",
+			output: "mytool - a fast file finder\n\nUsage: mytool [OPTIONS] PATTERN\n",
+			want:   "a fast file finder",
+		},
+		{
+			name:   "version banner skipped",
+			output: "mytool v1.2.3\nA tool that does things\n\nUsage: mytool [flags]\n",
+			want:   "A tool that does things",
+		},
+		{
+			name:   "GNU summary directly after usage",
+			output: "Usage: mytool [OPTION]... [FILE]...\n  or:  mytool OPTION\nList information about the FILEs.\nSort entries alphabetically.\n\nMandatory arguments to long options are mandatory for short options too.\n",
+			want:   "List information about the FILEs.",
+		},
+		{
+			name:   "wrapped usage lines skipped",
+			output: "Usage: mytool [-q] [-C <dir>]\n              [--log-file <file>]\n\nOptions:\n  -q  Be quiet\n",
+			want:   "",
+		},
+		{
+			name:   "command list right after usage",
+			output: "usage: git <command> [<args>]\n\n   init      Create an empty repo\n   clone     Clone a repository\n\nSee 'git help <command>' for more.\n",
+			want:   "",
+		},
+		{
+			name:   "unindented command rows",
+			output: "Usage: mytool <command>\n\ninit      Create an empty repo\nclone     Clone a repository\n",
+			want:   "",
+		},
+		{
+			name:   "two spaces after a period",
+			output: "Usage: mytool [flags]\n\nConverts files.  Fast and safe.\n",
+			want:   "Converts files.  Fast and safe.",
+		},
+		{
+			name:   "section header before any prose",
+			output: "usage: git [--version] <command> [<args>]\n\nThese are common Git commands used in various situations:\n\n   clone     Clone a repository\n",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := helpSummary("mytool", tt.output); got != tt.want {
+				t.Errorf("helpSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManSummary(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "single line",
+			output: "SVC(1)            User Commands            SVC(1)\n\nNAME\n       svc - manage services\n\nSYNOPSIS\n       svc [options]\n",
+			want:   "manage services",
+		},
+		{
+			name:   "several names and a wrapped summary",
+			output: "NAME\n       gzip, gunzip, zcat - compress or expand\n       files\n\nSYNOPSIS\n       gzip [ -acdfhklLnNrtvV19 ] [ name ...  ]\n",
+			want:   "compress or expand files",
+		},
+		{
+			name:   "no dash",
+			output: "NAME\n       svc\n\nSYNOPSIS\n       svc\n",
+			want:   "",
+		},
+		{
+			name:   "no NAME section",
+			output: "SYNOPSIS\n       svc - not a summary\n",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := manSummary(tt.output); got != tt.want {
+				t.Errorf("manSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutputs_Description(t *testing.T) {
+	help := "usage: svc [-h]\n\nRun and supervise services.\n\noptions:\n  -h, --help  show help\n"
+	man := "NAME\n       svc - manage services\n\nOPTIONS\n       -q, --quiet\n              Suppress output.\n"
+
+	tool := &types.Tool{Name: "svc"}
+	New().parseOutputs(tool, help, man)
+	if tool.Description != "Run and supervise services." {
+		t.Errorf("expected --help summary to win, got %q", tool.Description)
+	}
+
+	tool = &types.Tool{Name: "svc"}
+	New().parseOutputs(tool, "usage: svc [-h]\n\noptions:\n  -h, --help  show help\n", man)
+	if tool.Description != "manage services" {
+		t.Errorf("expected man NAME summary as fallback, got %q", tool.Description)
+	}
+}
+
+func TestParseHelpOutput_CommandCategories(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+	}{
+		{
+			name: "docker style",
+			output: `Usage:  docker [OPTIONS] COMMAND
+
+A self-sufficient runtime for containers
+
+Common Commands:
+  run         Create and run a new container from an image
+  build       Build an image from a Dockerfile
+
+Management Commands:
+  container   Manage containers
+  image       Manage images
+
+Options:
+  -D, --debug   Enable debug mode
+`,
+		},
+		{
+			name: "gh style",
+			output: `Work seamlessly with GitHub from the command line.
+
+USAGE
+  gh <command> <subcommand> [flags]
+
+CORE COMMANDS
+  run:         Create and run a new container from an image
+  build:       Build an image from a Dockerfile
+
+GITHUB ACTIONS COMMANDS
+  container:   Manage containers
+  image:       Manage images
+
+FLAGS
+  -D, --debug   Enable debug mode
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &types.Tool{Name: "tool"}
+			New().parseHelpOutput(tool, tt.output)
+
+			var names []string
+			for _, cmd := range tool.Subcommands {
+				names = append(names, cmd.Name)
+			}
+			want := []string{"run", "build", "container", "image"}
+			if strings.Join(names, " ") != strings.Join(want, " ") {
+				t.Errorf("subcommands = %v, want %v", names, want)
+			}
+			if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--debug" {
+				t.Errorf("expected only --debug, got %+v", tool.GlobalFlags)
+			}
+		})
+	}
+}
+
+func TestIsCommandCategoryHeader(t *testing.T) {
+	tests := map[string]bool{
+		"CORE COMMANDS":           true,
+		"GITHUB ACTIONS COMMANDS": true,
+		"Management Commands:":    true,
+		"Common Commands:":        true,
+		"Commands:":               false, // handled by isCommandsHeader
+		"Run these commands:":     false,
+		"These are common Git commands used in various situations:": false,
+		"CORE COMMANDS (beta)": false,
+	}
+	for line, want := range tests {
+		if got := isCommandCategoryHeader(line); got != want {
+			t.Errorf("isCommandCategoryHeader(%q) = %v, want %v", line, got, want)
+		}
+	}
+}
+
+func TestParse_DiscoverExternalSubcommands(t *testing.T) {
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"exttool": "#!/bin/sh\nprintf 'Usage: exttool <command>\\n\\nCommands:\\n  build    Build it\\n'\n",
+		// Reached as "exttool build --help", which this tool doesn't answer
+		"exttool-build": "#!/bin/sh\nexit 1\n",
+		"exttool-lint":  "#!/bin/sh\nprintf 'Options:\\n  --fix    Fix problems\\n'\n",
+	}
+	for name, content := range scripts {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Not executable, so not a subcommand
+	if err := os.WriteFile(filepath.Join(dir, "exttool-data"), []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	cfg := DefaultConfig()
+	cfg.DetectVersion = false
+	cfg.ForceSource = "help"
+	tool, err := New(cfg).Parse("exttool", filepath.Join(dir, "exttool"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(tool.Subcommands) != 1 {
+		t.Fatalf("expected only the listed command without discovery, got %+v", tool.Subcommands)
+	}
+
+	cfg.DiscoverExternalSubcommands = true
+	tool, err = New(cfg).Parse("exttool", filepath.Join(dir, "exttool"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	var names []string
+	for _, cmd := range tool.Subcommands {
+		names = append(names, cmd.Name)
+	}
+	if strings.Join(names, " ") != "build lint" {
+		t.Fatalf("subcommands = %v, want [build lint]", names)
+	}
+	lint := tool.Subcommands[1]
+	if len(lint.Flags) != 1 || lint.Flags[0].Name != "--fix" {
+		t.Errorf("expected --fix from exttool-lint --help, got %+v", lint.Flags)
+	}
+}
@@ -0,0 +1,279 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// postProcess runs whole-tool passes after help and man output have been parsed
+func (p *Parser) postProcess(tool *types.Tool) {
+	rewriteFlagLists(tool, mergeShortOnlyFlags)
+	forEachFlagList(tool, extractDefaults)
+	forEachFlagList(tool, extractChoiceClauses)
+	forEachFlagList(tool, extractHiddenMarkers)
+	forEachFlagList(tool, inferArgumentValues)
+	forEachFlagList(tool, markDynamicArgs)
+}
+
+// forEachFlagList applies fn to the global flags and every command's flags, recursively
+func forEachFlagList(tool *types.Tool, fn func(flags []types.Flag)) {
+	fn(tool.GlobalFlags)
+	var walk func(cmds []types.Command)
+	walk = func(cmds []types.Command) {
+		for i := range cmds {
+			fn(cmds[i].Flags)
+			walk(cmds[i].Subcommands)
+		}
+	}
+	walk(tool.Subcommands)
+}
+
+// rewriteFlagLists replaces the global flags and every command's flags,
+// recursively, with fn's result. Use it for passes that add or remove flags.
+func rewriteFlagLists(tool *types.Tool, fn func(flags []types.Flag) []types.Flag) {
+	tool.GlobalFlags = fn(tool.GlobalFlags)
+	var walk func(cmds []types.Command)
+	walk = func(cmds []types.Command) {
+		for i := range cmds {
+			cmds[i].Flags = fn(cmds[i].Flags)
+			walk(cmds[i].Subcommands)
+		}
+	}
+	walk(tool.Subcommands)
+}
+
+// mergeShortOnlyFlags folds a short-only flag (promoted to Name, e.g. "-v"
+// from a synopsis) into the long flag it abbreviates. A long flag whose Short
+// already matches always wins; otherwise a long flag without a Short is
+// picked when it is the only one starting with the same letter, or when the
+// descriptions agree. The long form's description is kept.
+func mergeShortOnlyFlags(flags []types.Flag) []types.Flag {
+	merged := make([]bool, len(flags))
+	for i, short := range flags {
+		if !isShortOnlyFlag(short) {
+			continue
+		}
+		target := longFlagFor(flags, short)
+		if target < 0 {
+			continue
+		}
+		long := &flags[target]
+		long.Short = short.Name
+		long.ShortAliases = append(long.ShortAliases, short.ShortAliases...)
+		if long.Description == "" {
+			long.Description = short.Description
+		}
+		if long.Arg == "" {
+			long.Arg = short.Arg
+		}
+		if len(long.ArgumentValues) == 0 {
+			long.ArgumentValues = short.ArgumentValues
+		}
+		long.Required = long.Required || short.Required
+		merged[i] = true
+	}
+
+	result := flags[:0]
+	for i, flag := range flags {
+		if !merged[i] {
+			result = append(result, flag)
+		}
+	}
+	return result
+}
+
+// isShortOnlyFlag reports whether a flag only has a single-letter form
+func isShortOnlyFlag(flag types.Flag) bool {
+	return flag.Short == "" && len(flag.Name) == 2 && flag.Name[0] == '-' && flag.Name[1] != '-'
+}
+
+// longFlagFor returns the index of the long flag that short abbreviates, or -1
+func longFlagFor(flags []types.Flag, short types.Flag) int {
+	for i, flag := range flags {
+		if flag.Short == short.Name && strings.HasPrefix(flag.Name, "--") {
+			return i
+		}
+	}
+
+	letter := short.Name[1:]
+	var candidates []int
+	for i, flag := range flags {
+		if flag.Short != "" || !strings.HasPrefix(flag.Name, "--"+letter) {
+			continue
+		}
+		// A flag that takes a value can't be the same as one that doesn't
+		if (flag.Arg == "") != (short.Arg == "") {
+			continue
+		}
+		if short.Description != "" && strings.EqualFold(flag.Description, short.Description) {
+			return i
+		}
+		candidates = append(candidates, i)
+	}
+	if len(candidates) == 1 && short.Description == "" {
+		return candidates[0]
+	}
+	return -1
+}
+
+// extractDefaults moves a default clause out of descriptions that weren't on
+// the flag's own line, such as man page descriptions indented below it
+func extractDefaults(flags []types.Flag) {
+	for i := range flags {
+		if flags[i].Default != "" {
+			continue
+		}
+		flags[i].Description, flags[i].Default = splitDefaultClause(flags[i].Description)
+	}
+}
+
+// splitDefaultClause removes a bracketed default such as "(default: fast)",
+// "[default: 8080]", Cobra's `(default "json")` or "(defaults to auto)" from
+// s, returning the rest and the unquoted value. s is unchanged if it has none.
+func splitDefaultClause(s string) (rest, def string) {
+	loc := defaultClausePattern.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return s, ""
+	}
+	def = strings.Trim(strings.TrimSpace(s[loc[2]:loc[3]]), "\"'`")
+	if def == "" {
+		return s, ""
+	}
+	return strings.TrimSpace(s[:loc[0]] + " " + s[loc[1]:]), def
+}
+
+// extractChoiceClauses moves a bracketed value list out of the description, as
+// clap prints "[possible values: a, b]" and argparse-based tools "(choices: a, b)".
+// The values fill ArgumentValues of flags taking an argument, and the clause is
+// dropped from the description either way.
+func extractChoiceClauses(flags []types.Flag) {
+	for i := range flags {
+		loc := choiceClausePattern.FindStringSubmatchIndex(flags[i].Description)
+		if loc == nil {
+			continue
+		}
+		desc := flags[i].Description
+		values := splitValueList(desc[loc[2]:loc[3]])
+		if values == nil {
+			continue
+		}
+		if flags[i].Arg != "" && len(flags[i].ArgumentValues) == 0 {
+			flags[i].ArgumentValues = values
+		}
+		flags[i].Description = strings.TrimSpace(desc[:loc[0]] + " " + desc[loc[1]:])
+	}
+}
+
+// extractHiddenMarkers marks flags whose description says "(hidden)" or
+// "[hidden]" as Hidden, dropping the marker from the description
+func extractHiddenMarkers(flags []types.Flag) {
+	for i := range flags {
+		loc := hiddenMarkerPattern.FindStringIndex(flags[i].Description)
+		if loc == nil {
+			continue
+		}
+		desc := flags[i].Description
+		flags[i].Hidden = true
+		flags[i].Description = strings.TrimSpace(desc[:loc[0]] + " " + desc[loc[1]:])
+	}
+}
+
+// inferArgumentValues fills ArgumentValues from prose like "one of: a, b, c"
+// for flags that take an argument but had no placeholder choices
+func inferArgumentValues(flags []types.Flag) {
+	for i := range flags {
+		if flags[i].Arg == "" || len(flags[i].ArgumentValues) > 0 {
+			continue
+		}
+		if values := valuesFromDescription(flags[i].Description); values != nil {
+			flags[i].ArgumentValues = values
+		}
+	}
+}
+
+// dynamicArgKinds maps argument names to the kind of value that can only be
+// listed at completion time (see generator.DefaultDynamicCompletions)
+var dynamicArgKinds = map[string]string{
+	"branch":    "branch",
+	"remote":    "remote",
+	"tag":       "tag",
+	"container": "container",
+	"image":     "image",
+	"pod":       "pod",
+	"namespace": "namespace",
+	"context":   "context",
+}
+
+// markDynamicArgs sets Dynamic on flags whose argument name suggests runtime values
+func markDynamicArgs(flags []types.Flag) {
+	for i := range flags {
+		if len(flags[i].ArgumentValues) > 0 {
+			continue
+		}
+		arg := strings.ToLower(strings.Trim(flags[i].Arg, "<>[]"))
+		if kind, ok := dynamicArgKinds[arg]; ok {
+			flags[i].Dynamic = kind
+		}
+	}
+}
+
+var (
+	// Bracketed default: "(default: fast)", "[default: 8080]", `(default "json")`,
+	// "(defaults to auto)". Nested brackets, as in Cobra's "(default [])", don't match.
+	defaultClausePattern = regexp.MustCompile(`(?i)\s*[\[(]defaults?(?:\s+(?:to|is))?(?:\s*[:=]\s*|\s+)([^\[\]()]+)[\])]`)
+	// Bracketed list: "[possible values: a, b]", "(choices: a, b)"
+	choiceClausePattern = regexp.MustCompile(`(?i)\s*[\[(](?:possible values|choices)\s*:\s*([^\])]+)[\])]`)
+	// Hidden marker: "(hidden)", "[HIDDEN]"
+	hiddenMarkerPattern = regexp.MustCompile(`(?i)\s*[\[(]hidden[\])]`)
+	// Explicit cue followed by a list: "one of: a, b", "choices: a|b"
+	choicesCuePattern = regexp.MustCompile(`(?i)\b(?:one of|choices)\s*:?\s+(.+)$`)
+	// Description ending in a colon list: "Log level: debug, info, warn"
+	trailingListPattern = regexp.MustCompile(`:\s*([^:]+)$`)
+)
+
+// valuesFromDescription extracts an enumerated value list from a flag description.
+// It is deliberately conservative: an explicit cue or a trailing colon list is
+// required, and every item must be a single word.
+func valuesFromDescription(desc string) []string {
+	if m := choicesCuePattern.FindStringSubmatch(desc); m != nil {
+		if values := splitValueList(m[1]); values != nil {
+			return values
+		}
+	}
+	if m := trailingListPattern.FindStringSubmatch(desc); m != nil {
+		return splitValueList(m[1])
+	}
+	return nil
+}
+
+// splitValueList splits "a, b, or c" / "a|b|c" into single-word items.
+// Returns nil unless there are at least two items and all are single words.
+func splitValueList(list string) []string {
+	list = strings.TrimSpace(list)
+	list = strings.TrimRight(list, ".)]")
+
+	var parts []string
+	if strings.Contains(list, "|") {
+		parts = strings.Split(list, "|")
+	} else {
+		parts = strings.Split(list, ",")
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+
+	values := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		part = strings.TrimPrefix(part, "or ")
+		part = strings.TrimPrefix(part, "and ")
+		part = strings.Trim(part, `"'`+"`")
+		if part == "" || strings.ContainsAny(part, " \t") {
+			return nil
+		}
+		values = append(values, part)
+	}
+	return values
+}
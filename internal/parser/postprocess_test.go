@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+func TestValuesFromDescription(t *testing.T) {
+	tests := []struct {
+		name string
+		desc string
+		want []string
+	}{
+		{"trailing colon list", "Log level: debug, info, warn, error", []string{"debug", "info", "warn", "error"}},
+		{"one of with pipes", "Output format, one of: json|yaml|text", []string{"json", "yaml", "text"}},
+		{"one of without colon", "Must be one of fast, slow, or auto.", []string{"fast", "slow", "auto"}},
+		{"choices cue", "Compression (choices: gzip, zstd)", []string{"gzip", "zstd"}},
+		{"quoted values", `Color mode: "always", "never", "auto"`, []string{"always", "never", "auto"}},
+		{"prose after colon", "Note: this flag is deprecated, use --other", nil},
+		{"single value", "Default: json", nil},
+		{"no cue", "Set the output format for results", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := valuesFromDescription(tt.desc)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("valuesFromDescription(%q) = %v, want %v", tt.desc, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostProcess_InferArgumentValues(t *testing.T) {
+	tool := &types.Tool{
+		GlobalFlags: []types.Flag{
+			{Name: "--level", Arg: "LEVEL", Description: "Log level: debug, info, warn"},
+			{Name: "--quiet", Description: "Mode: silent, normal"}, // no Arg, left alone
+			{Name: "--format", Arg: "value", ArgumentValues: []string{"json"}, Description: "Format: a, b"},
+		},
+		Subcommands: []types.Command{
+			{Name: "run", Flags: []types.Flag{
+				{Name: "--mode", Arg: "MODE", Description: "Run mode, one of: fast|slow"},
+			}},
+		},
+	}
+
+	New().postProcess(tool)
+
+	if got := tool.GlobalFlags[0].ArgumentValues; len(got) != 3 {
+		t.Errorf("--level: expected 3 values, got %v", got)
+	}
+	if got := tool.GlobalFlags[1].ArgumentValues; got != nil {
+		t.Errorf("--quiet: expected no values without Arg, got %v", got)
+	}
+	if got := tool.GlobalFlags[2].ArgumentValues; len(got) != 1 {
+		t.Errorf("--format: existing values should be kept, got %v", got)
+	}
+	if got := tool.Subcommands[0].Flags[0].ArgumentValues; len(got) != 2 {
+		t.Errorf("run --mode: expected 2 values, got %v", got)
+	}
+}
+
+func TestPostProcess_ChoiceClauses(t *testing.T) {
+	helpOutput := `Options:
+  -l, --level <LEVEL>    Log level [default: info] [possible values: debug, info, warn]
+      --compress FORMAT  Compression (choices: gzip, zstd)
+      --color            Colorize output [possible values: yes]
+      --strict           Fail fast (choices: on, off)
+`
+	tool := &types.Tool{Name: "mytool"}
+	p := New()
+	p.parseHelpOutput(tool, helpOutput)
+	p.postProcess(tool)
+
+	tests := []struct {
+		name   string
+		values []string
+		desc   string
+	}{
+		{"--level", []string{"debug", "info", "warn"}, "Log level"},
+		{"--compress", []string{"gzip", "zstd"}, "Compression"},
+		{"--color", nil, "Colorize output [possible values: yes]"}, // a single value isn't a list
+		{"--strict", nil, "Fail fast"},                             // no argument to complete
+	}
+	if len(tool.GlobalFlags) != len(tests) {
+		t.Fatalf("expected %d flags, got %+v", len(tests), tool.GlobalFlags)
+	}
+	for i, tt := range tests {
+		flag := tool.GlobalFlags[i]
+		if flag.Name != tt.name {
+			t.Errorf("flag %d = %s, want %s", i, flag.Name, tt.name)
+			continue
+		}
+		if strings.Join(flag.ArgumentValues, ",") != strings.Join(tt.values, ",") {
+			t.Errorf("%s: values = %v, want %v", tt.name, flag.ArgumentValues, tt.values)
+		}
+		if flag.Description != tt.desc {
+			t.Errorf("%s: description = %q, want %q", tt.name, flag.Description, tt.desc)
+		}
+	}
+}
+
+func TestParseFlagLine_DefaultWithChoices(t *testing.T) {
+	p := New()
+	tests := []struct {
+		line   string
+		values []string
+		def    string
+		desc   string
+	}{
+		{"--mode=fast|slow    Speed mode (default: fast)", []string{"fast", "slow"}, "fast", "Speed mode"},
+		{"--mode=fast|slow (default: fast)", []string{"fast", "slow"}, "fast", ""},
+		{"-o, --output string   Output format (default \"json\")", nil, "json", "Output format"},
+		{"--port <PORT>   Port to listen on [default: 8080]", nil, "8080", "Port to listen on"},
+		{"--dir DIR   Work here (defaults to the current directory)", nil, "the current directory", "Work here"},
+		{"--tags strings   Tags to apply (default [])", nil, "", "Tags to apply (default [])"},
+		{"--retry   Retry (defaulting off)", nil, "", "Retry (defaulting off)"},
+	}
+	for _, tt := range tests {
+		flag := p.parseFlagLine(tt.line)
+		if flag == nil {
+			t.Errorf("parseFlagLine(%q) = nil", tt.line)
+			continue
+		}
+		if strings.Join(flag.ArgumentValues, ",") != strings.Join(tt.values, ",") {
+			t.Errorf("%q: values = %v, want %v", tt.line, flag.ArgumentValues, tt.values)
+		}
+		if flag.Default != tt.def {
+			t.Errorf("%q: default = %q, want %q", tt.line, flag.Default, tt.def)
+		}
+		if flag.Description != tt.desc {
+			t.Errorf("%q: description = %q, want %q", tt.line, flag.Description, tt.desc)
+		}
+	}
+}
+
+func TestPostProcess_ManPageDefaults(t *testing.T) {
+	tool := &types.Tool{
+		Name: "mytool",
+		GlobalFlags: []types.Flag{
+			{Name: "--level", Arg: "LEVEL", Description: "Log level: debug, info, warn (default: info)"},
+		},
+	}
+	New().postProcess(tool)
+
+	flag := tool.GlobalFlags[0]
+	if flag.Default != "info" || flag.Description != "Log level: debug, info, warn" {
+		t.Errorf("default = %q, description = %q", flag.Default, flag.Description)
+	}
+	if strings.Join(flag.ArgumentValues, ",") != "debug,info,warn" {
+		t.Errorf("expected values inferred after the default was removed, got %v", flag.ArgumentValues)
+	}
+}
+
+func TestPostProcess_MarkDynamicArgs(t *testing.T) {
+	tool := &types.Tool{
+		GlobalFlags: []types.Flag{
+			{Name: "--branch", Arg: "<branch>"},
+			{Name: "--namespace", Short: "-n", Arg: "NAMESPACE"},
+			{Name: "--output", Arg: "FILE"},
+			{Name: "--tag", Arg: "TAG", ArgumentValues: []string{"a", "b"}}, // static values win
+		},
+		Subcommands: []types.Command{
+			{Name: "run", Flags: []types.Flag{{Name: "--image", Arg: "IMAGE"}}},
+		},
+	}
+
+	New().postProcess(tool)
+
+	want := []string{"branch", "namespace", "", ""}
+	for i, flag := range tool.GlobalFlags {
+		if flag.Dynamic != want[i] {
+			t.Errorf("%s Dynamic = %q, want %q", flag.Name, flag.Dynamic, want[i])
+		}
+	}
+	if got := tool.Subcommands[0].Flags[0].Dynamic; got != "image" {
+		t.Errorf("run --image Dynamic = %q, want %q", got, "image")
+	}
+}
+
+func TestPostProcess_MergeShortOnlyFlags(t *testing.T) {
+	tool := &types.Tool{
+		GlobalFlags: []types.Flag{
+			{Name: "-v"}, // from the synopsis
+			{Name: "-n", Description: "Dry run"},
+			{Name: "-o", Arg: "FILE"},
+			{Name: "--verbose", Description: "Print more output"},
+			{Name: "--name", Arg: "NAME", Description: "Set the name"}, // takes a value, unlike -n
+			{Name: "--output", Short: "-o", Arg: "FILE", Description: "Write to FILE"},
+			{Name: "--quiet"},
+			{Name: "--query", Description: "Run a query"},
+			{Name: "-q"}, // ambiguous between --quiet and --query
+		},
+		Subcommands: []types.Command{
+			{Name: "run", Flags: []types.Flag{
+				{Name: "-f", Description: "Force the run"},
+				{Name: "--force", Description: "force the run"},
+				{Name: "--fast", Description: "Go fast"},
+			}},
+		},
+	}
+
+	New().postProcess(tool)
+
+	names := make(map[string]types.Flag)
+	for _, flag := range tool.GlobalFlags {
+		names[flag.Name] = flag
+	}
+	if len(tool.GlobalFlags) != 7 {
+		t.Errorf("expected 7 global flags after merging, got %d: %+v", len(tool.GlobalFlags), tool.GlobalFlags)
+	}
+	if _, ok := names["-v"]; ok {
+		t.Error("-v should have been merged into --verbose")
+	}
+	if got := names["--verbose"]; got.Short != "-v" || got.Description != "Print more output" {
+		t.Errorf("--verbose = %+v, want Short -v with the long description", got)
+	}
+	if _, ok := names["-o"]; ok {
+		t.Error("-o should have been merged into --output")
+	}
+	if _, ok := names["-n"]; !ok {
+		t.Error("-n should not merge into --name, which takes a value")
+	}
+	if _, ok := names["-q"]; !ok {
+		t.Error("-q should stay separate when the long form is ambiguous")
+	}
+
+	run := tool.Subcommands[0].Flags
+	if len(run) != 2 || run[0].Name != "--force" || run[0].Short != "-f" {
+		t.Errorf("run flags = %+v, want -f merged into --force by description", run)
+	}
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/config"
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// Raw output files hold every help text captured while parsing a tool, as
+// sections headed by "==> <label> <==". Labels are "--help", "man", "json help",
+// the deep discovery arguments (e.g. "help -a"), and "<subcommand path> --help".
+const (
+	rawHelpLabel = "--help"
+	rawManLabel  = "man"
+)
+
+// rawCapture accumulates help output during a parse. A nil capture discards everything.
+type rawCapture struct {
+	labels   []string
+	sections map[string]string
+}
+
+// newRawCapture returns a capture if raw output should be persisted, else nil
+func (p *Parser) newRawCapture() *rawCapture {
+	if p.config.RawDir == "" {
+		return nil
+	}
+	return &rawCapture{sections: make(map[string]string)}
+}
+
+// add records output under a label
+func (c *rawCapture) add(label, output string) {
+	if c == nil || output == "" {
+		return
+	}
+	if _, ok := c.sections[label]; !ok {
+		c.labels = append(c.labels, label)
+	}
+	c.sections[label] = output
+}
+
+// String formats the captured sections in capture order
+func (c *rawCapture) String() string {
+	var sb strings.Builder
+	for _, label := range c.labels {
+		fmt.Fprintf(&sb, "==> %s <==\n", label)
+		sb.WriteString(strings.TrimRight(c.sections[label], "\n"))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// save writes the captured output to <dir>/<name>.txt
+func (c *rawCapture) save(dir, name string) error {
+	if c == nil || len(c.labels) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name+".txt"), []byte(c.String()), 0644)
+}
+
+// splitRawSections parses raw output back into labeled sections. Text without
+// section headers is treated entirely as --help output.
+func splitRawSections(text string) map[string]string {
+	sections := make(map[string]string)
+	label := rawHelpLabel
+	var body []string
+
+	flush := func() {
+		if content := strings.Join(body, "\n"); strings.TrimSpace(content) != "" {
+			sections[label] = content
+		}
+		body = nil
+	}
+
+	for line := range strings.SplitSeq(text, "\n") {
+		if strings.HasPrefix(line, "==> ") && strings.HasSuffix(line, " <==") {
+			flush()
+			label = strings.TrimSuffix(strings.TrimPrefix(line, "==> "), " <==")
+			continue
+		}
+		body = append(body, line)
+	}
+	flush()
+
+	return sections
+}
+
+// ParseFromText extracts command structure from previously captured help output
+// without running anything. text is either a raw output file written by Parse
+// (see ParserConfig.RawDir) or plain --help output.
+func (p *Parser) ParseFromText(name, text string) (*types.Tool, error) {
+	if name == "" {
+		return nil, fmt.Errorf("name cannot be empty")
+	}
+
+	config.LogSection("Parsing " + name + " from text")
+
+	sections := splitRawSections(text)
+	tool := &types.Tool{
+		Name:     name,
+		ParsedAt: time.Now(),
+	}
+
+	if output, ok := sections[rawJSONHelpLabel]; ok {
+		if parsed, err := parseJSONHelp(output, p.config.MaxDepth); err == nil {
+			applyJSONHelp(tool, parsed)
+			p.postProcess(tool)
+			return tool, nil
+		}
+	}
+
+	helpOutput := sections[rawHelpLabel]
+	p.parseOutputs(tool, helpOutput, sections[rawManLabel])
+
+	for _, args := range [][]string{{"help", "--all"}, {"help", "-a"}} {
+		if output, ok := sections[strings.Join(args, " ")]; ok {
+			p.parseHelpAllOutput(tool, output)
+		}
+	}
+
+	p.parseNestedSubcommands(&tool.GlobalFlags, "", tool.Subcommands, 1, nil, func(cmdPath string) (string, string) {
+		return sections[cmdPath+" "+rawHelpLabel], sections[cmdPath+" "+rawManLabel]
+	})
+
+	p.postProcess(tool)
+
+	config.Logf("Parse complete: source=%s, subcommands=%d, flags=%d",
+		tool.Source, len(tool.Subcommands), len(tool.GlobalFlags))
+
+	return tool, nil
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRawCapture_RoundTrip(t *testing.T) {
+	c := &rawCapture{sections: make(map[string]string)}
+	c.add(rawHelpLabel, "Usage: mytool\n\nCommands:\n  build  Build it\n")
+	c.add(rawManLabel, "")
+	c.add("build --help", "Options:\n  --release  Optimize\n")
+
+	sections := splitRawSections(c.String())
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d: %v", len(sections), sections)
+	}
+	if !strings.Contains(sections[rawHelpLabel], "build  Build it") {
+		t.Errorf("--help section = %q", sections[rawHelpLabel])
+	}
+	if !strings.Contains(sections["build --help"], "--release") {
+		t.Errorf("build --help section = %q", sections["build --help"])
+	}
+}
+
+func TestRawCapture_NilDiscards(t *testing.T) {
+	var c *rawCapture
+	c.add(rawHelpLabel, "output")
+	if err := c.save(t.TempDir(), "mytool"); err != nil {
+		t.Errorf("save on nil capture: %v", err)
+	}
+}
+
+func TestParseFromText_PlainHelp(t *testing.T) {
+	text := `Usage: mytool [OPTIONS]
+
+Options:
+  -v, --verbose  Enable verbose output
+`
+	tool, err := New().ParseFromText("mytool", text)
+	if err != nil {
+		t.Fatalf("ParseFromText failed: %v", err)
+	}
+	if tool.Source != "help" {
+		t.Errorf("source = %q, want help", tool.Source)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--verbose" {
+		t.Errorf("unexpected flags: %+v", tool.GlobalFlags)
+	}
+}
+
+func TestParseFromText_Sections(t *testing.T) {
+	text := `==> --help <==
+Usage: mytool COMMAND
+
+Commands:
+  build       Build the project
+==> build --help <==
+Options:
+  --release   Optimized build
+`
+	tool, err := New().ParseFromText("mytool", text)
+	if err != nil {
+		t.Fatalf("ParseFromText failed: %v", err)
+	}
+	if len(tool.Subcommands) != 1 {
+		t.Fatalf("expected 1 subcommand, got %+v", tool.Subcommands)
+	}
+	flags := tool.Subcommands[0].Flags
+	if len(flags) != 1 || flags[0].Name != "--release" {
+		t.Errorf("expected build --release from nested section, got %+v", flags)
+	}
+}
+
+func TestParse_SavesRawOutput(t *testing.T) {
+	binDir := t.TempDir()
+	rawDir := filepath.Join(t.TempDir(), "raw")
+	script := filepath.Join(binDir, "rawtool")
+	content := `#!/bin/sh
+if [ "$1" = "build" ]; then
+  printf 'Options:\n  --release   Optimized build\n'
+else
+  printf 'Usage: rawtool COMMAND\n\nCommands:\n  build       Build the project\n'
+fi
+`
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.RawDir = rawDir
+	parsed, err := New(cfg).Parse("rawtool", script)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(rawDir, "rawtool.txt"))
+	if err != nil {
+		t.Fatalf("expected raw output file: %v", err)
+	}
+
+	reparsed, err := New().ParseFromText("rawtool", string(data))
+	if err != nil {
+		t.Fatalf("ParseFromText failed: %v", err)
+	}
+	if reparsed.ContentHash() != parsed.ContentHash() {
+		t.Errorf("reparsed tool differs from parsed tool:\n%+v\n%+v", reparsed, parsed)
+	}
+}
+
+func TestParseFromText_CobraGlobalFlags(t *testing.T) {
+	text := `==> --help <==
+Usage:
+  kubectl [command]
+
+Available Commands:
+  get         Display one or many resources
+  delete      Delete resources
+
+Flags:
+      --kubeconfig string   Path to the kubeconfig file
+==> get --help <==
+Display one or many resources.
+
+Usage:
+  kubectl get [flags]
+
+Flags:
+  -o, --output string   Output format
+  -w, --watch           Watch for changes
+
+Global Flags:
+      --kubeconfig string   Path to the kubeconfig file
+  -n, --namespace string    The namespace scope for this request
+==> delete --help <==
+Usage:
+  kubectl delete [flags]
+
+Flags:
+      --force   Delete immediately
+
+Global Flags:
+      --kubeconfig string   Path to the kubeconfig file
+  -n, --namespace string    The namespace scope for this request
+`
+	tool, err := New().ParseFromText("kubectl", text)
+	if err != nil {
+		t.Fatalf("ParseFromText failed: %v", err)
+	}
+
+	var globals []string
+	for _, f := range tool.GlobalFlags {
+		globals = append(globals, f.Name)
+	}
+	if strings.Join(globals, " ") != "--kubeconfig --namespace" {
+		t.Errorf("global flags = %v, want --kubeconfig --namespace", globals)
+	}
+
+	want := map[string]string{"get": "--output --watch", "delete": "--force"}
+	for _, cmd := range tool.Subcommands {
+		var names []string
+		for _, f := range cmd.Flags {
+			names = append(names, f.Name)
+		}
+		if got := strings.Join(names, " "); got != want[cmd.Name] {
+			t.Errorf("%s flags = %q, want %q", cmd.Name, got, want[cmd.Name])
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/config"
+)
+
+// IsTransientExecError reports whether a tool couldn't be started for a
+// reason that may pass on its own: fork failing under load (EAGAIN, ENOMEM)
+// or the executable being written at that moment (ETXTBSY). A tool that ran
+// and exited, or that isn't permitted to run, is never transient.
+func IsTransientExecError(err error) bool {
+	var exitErr *exec.ExitError
+	if err == nil || errors.As(err, &exitErr) {
+		return false
+	}
+	return errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.ENOMEM) || errors.Is(err, syscall.ETXTBSY)
+}
+
+// RetryTransient calls run, and calls it again up to retries more times while
+// it fails with a transient exec error, sleeping delay before the first retry
+// and doubling it before each one after
+func RetryTransient(retries int, delay time.Duration, run func() ([]byte, error)) ([]byte, error) {
+	output, err := run()
+	for i := 0; i < retries && IsTransientExecError(err); i++ {
+		config.Logf("Transient error, retrying in %v: %v", delay, err)
+		time.Sleep(delay)
+		delay *= 2
+		output, err = run()
+	}
+	return output, err
+}
+
+// runHelpCommand runs a help command and returns its combined output,
+// retrying transient failures to start it as configured
+func (p *Parser) runHelpCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return RetryTransient(p.config.HelpRetries, p.config.HelpRetryDelay, func() ([]byte, error) {
+		return HelpCommand(ctx, name, args...).CombinedOutput()
+	})
+}
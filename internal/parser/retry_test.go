@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransientExecError(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"fork EAGAIN", &os.PathError{Op: "fork/exec", Path: "/bin/tool", Err: syscall.EAGAIN}, true},
+		{"fork ENOMEM", &os.PathError{Op: "fork/exec", Path: "/bin/tool", Err: syscall.ENOMEM}, true},
+		{"text file busy", &os.PathError{Op: "fork/exec", Path: "/bin/tool", Err: syscall.ETXTBSY}, true},
+		{"permission denied", &os.PathError{Op: "fork/exec", Path: "/bin/tool", Err: syscall.EACCES}, false},
+		{"not found", exec.ErrNotFound, false},
+		{"clean non-zero exit", exitErr, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientExecError(tt.err); got != tt.want {
+				t.Errorf("IsTransientExecError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryTransient(t *testing.T) {
+	busy := &os.PathError{Op: "fork/exec", Path: "/bin/tool", Err: syscall.EAGAIN}
+	denied := &os.PathError{Op: "fork/exec", Path: "/bin/tool", Err: syscall.EACCES}
+
+	tests := []struct {
+		name      string
+		retries   int
+		failures  int   // Calls that fail before one succeeds
+		err       error // Error of the failing calls
+		wantCalls int
+		wantOK    bool
+	}{
+		{"succeeds first time", 2, 0, busy, 1, true},
+		{"fails once then succeeds", 2, 1, busy, 2, true},
+		{"retries exhausted", 2, 5, busy, 3, false},
+		{"no retries configured", 0, 1, busy, 1, false},
+		{"permission errors are not retried", 2, 1, denied, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			output, err := RetryTransient(tt.retries, time.Millisecond, func() ([]byte, error) {
+				calls++
+				if calls <= tt.failures {
+					return nil, tt.err
+				}
+				return []byte("help"), nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if ok := err == nil && string(output) == "help"; ok != tt.wantOK {
+				t.Errorf("output = %q, err = %v, want success %v", output, err, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRunHelp_RetriesBusyExecutable(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("relies on Linux refusing to run a file open for writing")
+	}
+
+	// The script is held open for writing, so the first attempt to run it
+	// fails with "text file busy" until the file is closed
+	script := filepath.Join(t.TempDir(), "flakytool")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf 'Options:\\n  --ok  Ran\\n'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(script, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(20*time.Millisecond, func() { f.Close() })
+
+	cfg := DefaultConfig()
+	cfg.HelpRetries = 3
+	cfg.HelpRetryDelay = 50 * time.Millisecond
+	output, err := New(cfg).runHelp(script)
+	if err != nil || !strings.Contains(output, "--ok") {
+		t.Errorf("runHelp = %q, %v; want help output after a retry", output, err)
+	}
+}
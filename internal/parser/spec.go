@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jvalentini/tabgen/internal/types"
+)
+
+// ParseSpec converts an existing command spec into a Tool, bypassing --help parsing.
+// Supported formats:
+//   - JSON matching the types.Tool schema (as written to tools/<name>.json)
+//   - Cobra "__complete" output: one "candidate<TAB>description" per line,
+//     terminated by a ":<directive>" line
+func ParseSpec(name string, data []byte) (*types.Tool, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, errors.New("spec is empty")
+	}
+
+	var tool *types.Tool
+	var err error
+	if trimmed[0] == '{' {
+		tool, err = parseToolJSON(trimmed)
+	} else {
+		tool, err = parseCobraComplete(string(trimmed))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Explicit name wins over whatever the spec says
+	if name != "" {
+		tool.Name = name
+	}
+	if tool.Name == "" {
+		return nil, errors.New("spec does not name a tool")
+	}
+	return tool, nil
+}
+
+// parseToolJSON decodes a spec in TabGen's own Tool schema
+func parseToolJSON(data []byte) (*types.Tool, error) {
+	var tool types.Tool
+	if err := json.Unmarshal(data, &tool); err != nil {
+		return nil, fmt.Errorf("invalid tool JSON: %w", err)
+	}
+	if len(tool.Subcommands) == 0 && len(tool.GlobalFlags) == 0 {
+		return nil, errors.New("tool JSON has no subcommands or global flags")
+	}
+	return &tool, nil
+}
+
+// parseCobraComplete decodes the output of a cobra CLI's hidden __complete command
+func parseCobraComplete(output string) (*types.Tool, error) {
+	tool := &types.Tool{}
+	flagSet := newFlagSet(&tool.GlobalFlags)
+	cmdSet := newCommandSet(&tool.Subcommands)
+
+	sawDirective := false
+	for line := range strings.SplitSeq(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		// ":4" style directive marks the end of candidates
+		if strings.HasPrefix(line, ":") {
+			sawDirective = true
+			break
+		}
+
+		candidate, desc, _ := strings.Cut(line, "\t")
+		candidate = strings.TrimSpace(candidate)
+		desc = strings.TrimSpace(desc)
+
+		if strings.HasPrefix(candidate, "-") {
+			flagSet.Add(types.Flag{Name: candidate, Description: desc})
+		} else if isValidCommandName(candidate) {
+			cmdSet.Add(types.Command{Name: candidate, Description: desc})
+		}
+	}
+
+	if !sawDirective {
+		return nil, errors.New("unrecognized spec format (expected tool JSON or cobra __complete output)")
+	}
+	if len(tool.Subcommands) == 0 && len(tool.GlobalFlags) == 0 {
+		return nil, errors.New("cobra completion output contains no candidates")
+	}
+	return tool, nil
+}
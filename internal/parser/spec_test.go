@@ -0,0 +1,85 @@
+package parser
+
+import "testing"
+
+func TestParseSpec_ToolJSON(t *testing.T) {
+	data := []byte(`{
+  "name": "mytool",
+  "subcommands": [
+    {"name": "build", "description": "Build the project"}
+  ],
+  "global_flags": [
+    {"name": "--verbose", "short": "-v"}
+  ]
+}`)
+
+	tool, err := ParseSpec("", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tool.Name != "mytool" {
+		t.Errorf("expected name 'mytool', got %q", tool.Name)
+	}
+	if len(tool.Subcommands) != 1 || tool.Subcommands[0].Name != "build" {
+		t.Errorf("expected build subcommand, got %+v", tool.Subcommands)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Short != "-v" {
+		t.Errorf("expected --verbose/-v flag, got %+v", tool.GlobalFlags)
+	}
+}
+
+func TestParseSpec_NameOverride(t *testing.T) {
+	data := []byte(`{"name": "original", "global_flags": [{"name": "--help"}]}`)
+
+	tool, err := ParseSpec("renamed", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tool.Name != "renamed" {
+		t.Errorf("expected name 'renamed', got %q", tool.Name)
+	}
+}
+
+func TestParseSpec_CobraComplete(t *testing.T) {
+	data := []byte("completion\tGenerate the autocompletion script\n" +
+		"get\tDisplay one or many resources\n" +
+		"--kubeconfig\tPath to the kubeconfig file\n" +
+		":4\n" +
+		"Completion ended with directive: ShellCompDirectiveNoFileComp\n")
+
+	tool, err := ParseSpec("kubectl", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tool.Subcommands) != 2 {
+		t.Fatalf("expected 2 subcommands, got %d", len(tool.Subcommands))
+	}
+	if tool.Subcommands[1].Description != "Display one or many resources" {
+		t.Errorf("get description mismatch: got %q", tool.Subcommands[1].Description)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Name != "--kubeconfig" {
+		t.Errorf("expected --kubeconfig flag, got %+v", tool.GlobalFlags)
+	}
+}
+
+func TestParseSpec_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", "   "},
+		{"invalid json", `{"name": `},
+		{"json without content", `{"name": "mytool"}`},
+		{"json without name", `{"global_flags": [{"name": "--help"}]}`},
+		{"unknown text format", "just some text\n"},
+		{"cobra without candidates", ":4\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseSpec("", []byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -28,7 +28,7 @@ func (p *Parser) detectVersion(path string) string {
 // detectVersionWithConfig attempts to get version info using provided config
 func detectVersionWithConfig(path string, cfg ParserConfig) string {
 	for _, flag := range cfg.VersionCmds {
-		version := tryVersionFlagWithTimeout(path, flag, cfg.HelpTimeout)
+		version := tryVersionFlagWithTimeout(path, flag, cfg.VersionTimeout)
 		if version != "" {
 			return version
 		}
@@ -66,10 +66,12 @@ func ctxWithTimeout(d time.Duration) (context.Context, context.CancelFunc) {
 func extractVersion(output string) string {
 	// Common version patterns
 	patterns := []*regexp.Regexp{
-		// "version 1.2.3" or "v1.2.3"
-		regexp.MustCompile(`(?i)(?:version\s+)?v?(\d+\.\d+(?:\.\d+)?(?:[-+][a-zA-Z0-9.]+)?)`),
+		// "version 1.2.3", "v1.2.3.4" or a calendar version like "2023.11.01"
+		regexp.MustCompile(`(?i)(?:version\s+)?v?(\d+\.\d+(?:\.\d+){0,2}(?:[-+][a-zA-Z0-9.]+)?)`),
 		// "1.2.3" at start of line
-		regexp.MustCompile(`(?m)^(\d+\.\d+(?:\.\d+)?)`),
+		regexp.MustCompile(`(?m)^(\d+\.\d+(?:\.\d+){0,2})`),
+		// Compact calendar version "20231101"
+		regexp.MustCompile(`\b((?:19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01]))\b`),
 	}
 
 	// Take first line for simpler matching
@@ -92,3 +94,50 @@ func extractVersion(output string) string {
 
 	return ""
 }
+
+// CompareVersions orders two version strings, returning -1, 0 or 1. Runs of
+// digits compare as numbers, so "1.10" sorts after "1.9", and rank above text,
+// so a garbled "version is unknown" sorts below any real version.
+func CompareVersions(a, b string) int {
+	for a != "" && b != "" {
+		pa, ra := versionPart(a)
+		pb, rb := versionPart(b)
+		if c := compareVersionParts(pa, pb); c != 0 {
+			return c
+		}
+		a, b = ra, rb
+	}
+	return strings.Compare(a, b)
+}
+
+// versionPart splits off the leading run of digits or of non-digits
+func versionPart(s string) (part, rest string) {
+	digit := s[0] >= '0' && s[0] <= '9'
+	i := 1
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9') == digit {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareVersionParts compares two digit runs numerically, and anything else
+// as text below digits
+func compareVersionParts(a, b string) int {
+	aDigit, bDigit := a[0] >= '0' && a[0] <= '9', b[0] >= '0' && b[0] <= '9'
+	if aDigit != bDigit {
+		if aDigit {
+			return 1
+		}
+		return -1
+	}
+	if aDigit {
+		a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+		if len(a) != len(b) {
+			if len(a) < len(b) {
+				return -1
+			}
+			return 1
+		}
+	}
+	return strings.Compare(a, b)
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestExtractVersion(t *testing.T) {
@@ -110,6 +113,36 @@ func TestExtractVersion(t *testing.T) {
 			output: "1.0.0 - Release build",
 			want:   "1.0.0",
 		},
+		{
+			name:   "four component version",
+			output: "tool version 1.2.3.4",
+			want:   "1.2.3.4",
+		},
+		{
+			name:   "four component version with prerelease",
+			output: "v1.2.3.4-rc1",
+			want:   "1.2.3.4-rc1",
+		},
+		{
+			name:   "calendar version",
+			output: "2023.11",
+			want:   "2023.11",
+		},
+		{
+			name:   "calendar version with day",
+			output: "tool 2023.11.01",
+			want:   "2023.11.01",
+		},
+		{
+			name:   "compact date version",
+			output: "20231101",
+			want:   "20231101",
+		},
+		{
+			name:   "compact date version with label",
+			output: "mytool nightly-20231101 (linux)",
+			want:   "20231101",
+		},
 	}
 
 	for _, tt := range tests {
@@ -233,3 +266,50 @@ func TestExtractVersion_CaseInsensitive(t *testing.T) {
 		}
 	}
 }
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.9", "1.10", -1},
+		{"2.0", "1.99.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.0-rc1", "1.0-rc2", -1},
+		{"20231101", "20240101", -1},
+		{"1.02", "1.2", 0},
+		{"v1", "1", -1},
+		{"unknown option", "0.1", -1},
+	}
+
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestDetectVersion_VersionTimeout(t *testing.T) {
+	// Answers --help at once but hangs on --version
+	script := filepath.Join(t.TempDir(), "slowversion")
+	content := "#!/bin/sh\nif [ \"$1\" = --version ]; then exec sleep 5; fi\necho 'slowversion 1.2.3'\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.VersionTimeout = 100 * time.Millisecond
+	start := time.Now()
+	version := New(cfg).detectVersion(script)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("version detection took %v, want it bounded by VersionTimeout rather than HelpTimeout", elapsed)
+	}
+	// --version times out; -V prints the version
+	if version != "1.2.3" {
+		t.Errorf("detectVersion = %q, want 1.2.3", version)
+	}
+}
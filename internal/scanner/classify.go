@@ -0,0 +1,177 @@
+package scanner
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"io"
+	"os"
+	"strings"
+)
+
+// executableKind classifies an executable by its file header
+type executableKind int
+
+const (
+	kindUnknown executableKind = iota // Unrecognized format
+	kindProgram                       // Native executable (ELF, Mach-O, or PE)
+	kindScript                        // Interpreter script ("#!")
+	kindLibrary                       // Shared library or object file, not a command
+	kindGUI                           // Program linked against a GUI toolkit
+)
+
+// guiLibraries are name fragments of GUI toolkit libraries; a program linking
+// one is assumed to be a desktop app rather than a CLI
+var guiLibraries = []string{
+	"libgtk-", "libQt5Widgets", "libQt6Widgets", "libwx_gtk",
+	"/AppKit.framework/", "/Cocoa.framework/",
+}
+
+// isNonCLI reports whether the file at path is clearly not a command-line
+// tool: a shared library or a GUI program. Scripts and unrecognized files are
+// given the benefit of the doubt.
+func isNonCLI(path string) bool {
+	kind := classifyExecutable(path)
+	return kind == kindLibrary || kind == kindGUI
+}
+
+// classifyExecutable peeks at a file's magic number and, for native binaries,
+// its headers and linked libraries
+func classifyExecutable(path string) executableKind {
+	f, err := os.Open(path)
+	if err != nil {
+		return kindUnknown
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return kindUnknown
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, []byte("#!")):
+		return kindScript
+	case bytes.Equal(magic, []byte(elf.ELFMAG)):
+		return classifyELF(f)
+	case bytes.HasPrefix(magic, []byte("MZ")):
+		return classifyPE(f)
+	}
+	switch string(magic) {
+	case "\xfe\xed\xfa\xce", "\xce\xfa\xed\xfe", "\xfe\xed\xfa\xcf", "\xcf\xfa\xed\xfe":
+		return classifyMachO(f)
+	case "\xca\xfe\xba\xbe":
+		// Universal binary; Java class files share the magic and fail to parse
+		fat, err := macho.NewFatFile(f)
+		if err != nil || len(fat.Arches) == 0 {
+			return kindUnknown
+		}
+		return machOKind(fat.Arches[0].File)
+	}
+	return kindUnknown
+}
+
+// classifyELF distinguishes ELF executables from shared libraries and GUI programs
+func classifyELF(r io.ReaderAt) executableKind {
+	f, err := elf.NewFile(r)
+	if err != nil {
+		return kindUnknown
+	}
+	switch f.Type {
+	case elf.ET_EXEC:
+	case elf.ET_DYN:
+		// Position-independent executables are ET_DYN too: dynamically linked
+		// ones request an interpreter, and static-pie ones don't
+		if !hasELFInterp(f) && !isStaticPIE(f) {
+			return kindLibrary
+		}
+	default:
+		return kindLibrary
+	}
+
+	libs, _ := f.ImportedLibraries()
+	if linksGUI(libs) {
+		return kindGUI
+	}
+	return kindProgram
+}
+
+// hasELFInterp reports whether an ELF file requests a program interpreter
+func hasELFInterp(f *elf.File) bool {
+	for _, prog := range f.Progs {
+		if prog.Type == elf.PT_INTERP {
+			return true
+		}
+	}
+	return false
+}
+
+// isStaticPIE reports whether an ET_DYN file without an interpreter is a
+// static-pie executable rather than a shared library. Linkers mark those with
+// DF_1_PIE; failing that, an entry point without a soname counts.
+func isStaticPIE(f *elf.File) bool {
+	if flags, _ := f.DynValue(elf.DT_FLAGS_1); len(flags) > 0 && flags[0]&uint64(elf.DF_1_PIE) != 0 {
+		return true
+	}
+	if soname, _ := f.DynValue(elf.DT_SONAME); len(soname) > 0 {
+		return false
+	}
+	return f.Entry != 0
+}
+
+// classifyMachO distinguishes Mach-O executables from dylibs, bundles, and GUI apps
+func classifyMachO(r io.ReaderAt) executableKind {
+	f, err := macho.NewFile(r)
+	if err != nil {
+		return kindUnknown
+	}
+	return machOKind(f)
+}
+
+// machOKind classifies a parsed Mach-O file
+func machOKind(f *macho.File) executableKind {
+	if f.Type != macho.TypeExec {
+		return kindLibrary
+	}
+	libs, _ := f.ImportedLibraries()
+	if linksGUI(libs) {
+		return kindGUI
+	}
+	return kindProgram
+}
+
+// classifyPE distinguishes PE console programs from DLLs and GUI-subsystem programs
+func classifyPE(r io.ReaderAt) executableKind {
+	f, err := pe.NewFile(r)
+	if err != nil {
+		return kindUnknown
+	}
+	if f.Characteristics&pe.IMAGE_FILE_DLL != 0 {
+		return kindLibrary
+	}
+
+	var subsystem uint16
+	switch h := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		subsystem = h.Subsystem
+	case *pe.OptionalHeader64:
+		subsystem = h.Subsystem
+	}
+	if subsystem == pe.IMAGE_SUBSYSTEM_WINDOWS_GUI {
+		return kindGUI
+	}
+	return kindProgram
+}
+
+// linksGUI reports whether any linked library is a GUI toolkit
+func linksGUI(libs []string) bool {
+	for _, lib := range libs {
+		for _, gui := range guiLibraries {
+			if strings.Contains(lib, gui) {
+				return true
+			}
+		}
+	}
+	return false
+}
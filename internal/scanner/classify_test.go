@@ -0,0 +1,184 @@
+package scanner
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFakeELF writes a minimal 64-bit ELF header of the given type with no
+// program or section headers
+func writeFakeELF(t *testing.T, path string, typ elf.Type) {
+	t.Helper()
+	hdr := elf.Header64{
+		Type:      uint16(typ),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Ehsize:    64,
+		Phentsize: 56,
+		Shentsize: 64,
+	}
+	copy(hdr.Ident[:], elf.ELFMAG)
+	hdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	hdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	hdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, hdr); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeFakeDynELF writes an ET_DYN file with the given entry point and a
+// .dynamic section holding dyn, as static-pie executables and shared
+// libraries without an interpreter look
+func writeFakeDynELF(t *testing.T, path string, entry uint64, dyn []elf.Dyn64) {
+	t.Helper()
+	const ehsize, shentsize = 64, 64
+	dynSize := uint64(len(dyn)) * 16
+	hdr := elf.Header64{
+		Type:      uint16(elf.ET_DYN),
+		Machine:   uint16(elf.EM_X86_64),
+		Version:   uint32(elf.EV_CURRENT),
+		Entry:     entry,
+		Shoff:     ehsize + dynSize,
+		Ehsize:    ehsize,
+		Phentsize: 56,
+		Shentsize: shentsize,
+		Shnum:     2,
+	}
+	copy(hdr.Ident[:], elf.ELFMAG)
+	hdr.Ident[elf.EI_CLASS] = byte(elf.ELFCLASS64)
+	hdr.Ident[elf.EI_DATA] = byte(elf.ELFDATA2LSB)
+	hdr.Ident[elf.EI_VERSION] = byte(elf.EV_CURRENT)
+
+	sections := []elf.Section64{
+		{}, // SHN_UNDEF
+		{Type: uint32(elf.SHT_DYNAMIC), Off: ehsize, Size: dynSize, Entsize: 16, Addralign: 8},
+	}
+
+	var buf bytes.Buffer
+	for _, v := range []any{hdr, dyn, sections} {
+		if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClassifyExecutable(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFakeELF(t, filepath.Join(dir, "program"), elf.ET_EXEC)
+	writeFakeELF(t, filepath.Join(dir, "libfoo.so"), elf.ET_DYN)
+	writeFakeDynELF(t, filepath.Join(dir, "static-pie"), 0x1040, []elf.Dyn64{
+		{Tag: int64(elf.DT_FLAGS_1), Val: uint64(elf.DF_1_PIE)},
+		{Tag: int64(elf.DT_NULL)},
+	})
+	writeFakeDynELF(t, filepath.Join(dir, "unmarked-pie"), 0x1040, []elf.Dyn64{{Tag: int64(elf.DT_NULL)}})
+	writeFakeDynELF(t, filepath.Join(dir, "libbar.so"), 0x1040, []elf.Dyn64{
+		{Tag: int64(elf.DT_SONAME), Val: 1},
+		{Tag: int64(elf.DT_NULL)},
+	})
+	files := map[string]string{
+		"script":    "#!/bin/sh\necho hi\n",
+		"plain":     "echo no shebang\n",
+		"truncated": "\x7fEL",
+		"java":      "\xca\xfe\xba\xbe\x00\x00\x00\x34",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		want   executableKind
+		nonCLI bool
+	}{
+		{"program", kindProgram, false},
+		{"libfoo.so", kindLibrary, true},
+		{"static-pie", kindProgram, false},
+		{"unmarked-pie", kindProgram, false},
+		{"libbar.so", kindLibrary, true},
+		{"script", kindScript, false},
+		{"plain", kindUnknown, false},
+		{"truncated", kindUnknown, false},
+		{"java", kindUnknown, false},
+		{"missing", kindUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if got := classifyExecutable(path); got != tt.want {
+				t.Errorf("classifyExecutable(%s) = %v, want %v", tt.name, got, tt.want)
+			}
+			if got := isNonCLI(path); got != tt.nonCLI {
+				t.Errorf("isNonCLI(%s) = %v, want %v", tt.name, got, tt.nonCLI)
+			}
+		})
+	}
+}
+
+func TestLinksGUI(t *testing.T) {
+	if !linksGUI([]string{"libc.so.6", "libgtk-3.so.0"}) {
+		t.Error("expected GTK program to count as GUI")
+	}
+	if !linksGUI([]string{"/System/Library/Frameworks/AppKit.framework/Versions/C/AppKit"}) {
+		t.Error("expected AppKit program to count as GUI")
+	}
+	if linksGUI([]string{"libc.so.6", "libz.so.1"}) {
+		t.Error("plain libc program should not count as GUI")
+	}
+}
+
+func TestScan_SkipNonCLI(t *testing.T) {
+	binDir := t.TempDir()
+	homeDir := t.TempDir()
+
+	writeFakeELF(t, filepath.Join(binDir, "mytool"), elf.ET_EXEC)
+	writeFakeELF(t, filepath.Join(binDir, "libthing"), elf.ET_DYN)
+	if err := os.WriteFile(filepath.Join(binDir, "myscript"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".bash_history"), []byte("mytool\nlibthing\nmyscript\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", homeDir)
+	t.Setenv("HISTFILE", "")
+	t.Setenv("PATH", binDir)
+
+	s := New(nil)
+	catalog, err := s.Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := catalog.Tools["libthing"]; !ok {
+		t.Error("without the classifier every executable is cataloged")
+	}
+
+	s.SetSkipNonCLI(true)
+	catalog, err = s.Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := catalog.Tools["libthing"]; ok {
+		t.Error("shared library should be skipped")
+	}
+	for _, name := range []string{"mytool", "myscript"} {
+		if _, ok := catalog.Tools[name]; !ok {
+			t.Errorf("expected %s to be cataloged", name)
+		}
+	}
+}
@@ -2,52 +2,172 @@ package scanner
 
 import (
 	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/jvalentini/tabgen/internal/config"
 )
 
 // GetUsedCommands extracts command names from shell history files
-// Returns a set (map) of command names that the user has actually executed
-func GetUsedCommands() (map[string]bool, error) {
-	usedCommands := make(map[string]bool)
+// Returns a set (map) of command names that the user has actually executed.
+// If includeRotated is set, rotated and session histories are read too.
+func GetUsedCommands(includeRotated bool) (map[string]bool, error) {
+	counts, err := CommandCounts(includeRotated)
+	usedCommands := make(map[string]bool, len(counts))
+	for cmd := range counts {
+		usedCommands[cmd] = true
+	}
+	return usedCommands, err
+}
+
+// CommandCounts counts how many history lines run each command, across every
+// history file GetUsedCommands reads
+func CommandCounts(includeRotated bool) (map[string]int, error) {
+	counts := make(map[string]int)
 
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return usedCommands, err
+		return counts, err
+	}
+
+	paths := historyFilePaths(homeDir)
+	for _, histFile := range paths {
+		if err := parseHistoryFile(histFile, counts); err != nil {
+			if !os.IsNotExist(err) {
+				return counts, err
+			}
+		}
 	}
 
-	historyFiles := []string{
+	if includeRotated {
+		// Old copies are a bonus: one that can't be read is skipped
+		for _, histFile := range rotatedHistoryPaths(homeDir, paths) {
+			if err := parseHistoryFile(histFile, counts); err != nil {
+				config.Logf("Skipping history file %s: %v", histFile, err)
+			}
+		}
+	}
+
+	return counts, nil
+}
+
+// historyFilePaths lists the history files to read: $HISTFILE, the bash and
+// zsh defaults (including under $ZDOTDIR), and fish's history. Paths that
+// resolve to the same file, e.g. a $HISTFILE pointing at ~/.zsh_history or a
+// symlinked history, are listed once.
+func historyFilePaths(homeDir string) []string {
+	var candidates []string
+	if histFile := os.Getenv("HISTFILE"); histFile != "" {
+		if rest, ok := strings.CutPrefix(histFile, "~/"); ok {
+			histFile = filepath.Join(homeDir, rest)
+		}
+		candidates = append(candidates, histFile)
+	}
+	candidates = append(candidates,
 		filepath.Join(homeDir, ".bash_history"),
 		filepath.Join(homeDir, ".zsh_history"),
+		filepath.Join(homeDir, ".histfile"),
+	)
+	if zdotdir := os.Getenv("ZDOTDIR"); zdotdir != "" {
+		candidates = append(candidates, filepath.Join(zdotdir, ".zsh_history"))
 	}
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(homeDir, ".local", "share")
+	}
+	candidates = append(candidates, filepath.Join(dataHome, "fish", "fish_history"))
+
+	seen := make(map[string]bool)
+	var paths []string
+	for _, path := range candidates {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			resolved = filepath.Clean(path)
+		}
+		if !seen[resolved] {
+			seen[resolved] = true
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
 
-	for _, histFile := range historyFiles {
-		if err := parseHistoryFile(histFile, usedCommands); err != nil {
-			if !os.IsNotExist(err) {
-				return usedCommands, err
+// rotatedHistoryPaths lists rotated copies of the history files in paths,
+// such as ~/.bash_history.1.gz or ~/.zsh_history-20240101, followed by macOS
+// Terminal's per-session histories
+func rotatedHistoryPaths(homeDir string, paths []string) []string {
+	var rotated []string
+	for _, path := range paths {
+		for _, sep := range []string{".", "-"} {
+			matches, _ := filepath.Glob(path + sep + "*")
+			for _, match := range matches {
+				if isRotatedSuffix(strings.TrimPrefix(match, path)) {
+					rotated = append(rotated, match)
+				}
 			}
 		}
 	}
+	for _, dir := range []string{".bash_sessions", ".zsh_sessions"} {
+		matches, _ := filepath.Glob(filepath.Join(homeDir, dir, "*.history"))
+		rotated = append(rotated, matches...)
+	}
+	return rotated
+}
 
-	return usedCommands, nil
+// isRotatedSuffix reports whether what follows a history file's name marks a
+// rotated copy: ".gz", a number or date (".1", "-20240101"), or both (".2.gz")
+func isRotatedSuffix(suffix string) bool {
+	suffix = strings.TrimSuffix(suffix, ".gz")
+	if suffix == "" {
+		return true
+	}
+	if len(suffix) < 2 || (suffix[0] != '.' && suffix[0] != '-') {
+		return false
+	}
+	return strings.Trim(suffix[1:], "0123456789") == ""
 }
 
-// parseHistoryFile reads a history file and extracts command names
-func parseHistoryFile(path string, commands map[string]bool) error {
+// parseHistoryFile reads a history file and counts the command names in it.
+// Files ending in .gz are decompressed.
+func parseHistoryFile(path string, commands map[string]int) error {
 	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return fmt.Errorf("failed to decompress %s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	// Fish history is YAML-like; only "- cmd: ..." lines hold commands
+	fish := filepath.Base(path) == "fish_history"
+
+	scanner := bufio.NewScanner(r)
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" {
 			continue
 		}
 
+		if fish {
+			after, ok := strings.CutPrefix(line, "- cmd: ")
+			if !ok {
+				continue
+			}
+			line = after
+		}
+
 		// Zsh history format: ": timestamp:duration;command"
 		if strings.HasPrefix(line, ":") {
 			parts := strings.SplitN(line, ";", 2)
@@ -58,7 +178,7 @@ func parseHistoryFile(path string, commands map[string]bool) error {
 
 		cmd := extractCommand(line)
 		if cmd != "" {
-			commands[cmd] = true
+			commands[cmd]++
 		}
 	}
 
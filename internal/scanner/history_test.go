@@ -1,8 +1,11 @@
 package scanner
 
 import (
+	"bytes"
+	"compress/gzip"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 )
 
@@ -62,21 +65,21 @@ echo hello
 		t.Fatalf("Failed to write test history file: %v", err)
 	}
 
-	commands := make(map[string]bool)
+	commands := make(map[string]int)
 	if err := parseHistoryFile(histFile, commands); err != nil {
 		t.Fatalf("parseHistoryFile failed: %v", err)
 	}
 
 	expectedCommands := []string{"git", "docker", "apt", "npm", "make"}
 	for _, cmd := range expectedCommands {
-		if !commands[cmd] {
+		if commands[cmd] == 0 {
 			t.Errorf("Expected command %q not found in parsed history", cmd)
 		}
 	}
 
 	unexpectedCommands := []string{"cd", "echo", "VAR=value", "comment"}
 	for _, cmd := range unexpectedCommands {
-		if commands[cmd] {
+		if commands[cmd] > 0 {
 			t.Errorf("Unexpected command %q found in parsed history", cmd)
 		}
 	}
@@ -96,21 +99,37 @@ func TestParseHistoryFile_ZshFormat(t *testing.T) {
 		t.Fatalf("Failed to write test zsh history file: %v", err)
 	}
 
-	commands := make(map[string]bool)
+	commands := make(map[string]int)
 	if err := parseHistoryFile(histFile, commands); err != nil {
 		t.Fatalf("parseHistoryFile failed: %v", err)
 	}
 
 	expectedCommands := []string{"git", "docker", "npm", "apt"}
 	for _, cmd := range expectedCommands {
-		if !commands[cmd] {
+		if commands[cmd] == 0 {
 			t.Errorf("Expected command %q not found in parsed zsh history", cmd)
 		}
 	}
 }
 
+func TestParseHistoryFile_Counts(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), "test_history")
+	content := "git status\ngit push\nsudo git pull\ndocker ps\n"
+	if err := os.WriteFile(histFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test history file: %v", err)
+	}
+
+	commands := make(map[string]int)
+	if err := parseHistoryFile(histFile, commands); err != nil {
+		t.Fatalf("parseHistoryFile failed: %v", err)
+	}
+	if commands["git"] != 3 || commands["docker"] != 1 {
+		t.Errorf("counts = %v, want git 3 and docker 1", commands)
+	}
+}
+
 func TestParseHistoryFile_MissingFile(t *testing.T) {
-	commands := make(map[string]bool)
+	commands := make(map[string]int)
 	err := parseHistoryFile("/nonexistent/file", commands)
 	if err == nil {
 		t.Error("Expected error for non-existent file")
@@ -168,7 +187,7 @@ npm install
 		t.Fatalf("Failed to write zsh history: %v", err)
 	}
 
-	commands, err := GetUsedCommands()
+	commands, err := GetUsedCommands(false)
 	if err != nil {
 		t.Fatalf("GetUsedCommands failed: %v", err)
 	}
@@ -187,7 +206,7 @@ func TestGetUsedCommands_NoHistoryFiles(t *testing.T) {
 	os.Setenv("HOME", tempDir)
 	defer os.Setenv("HOME", origHome)
 
-	commands, err := GetUsedCommands()
+	commands, err := GetUsedCommands(false)
 	if err != nil {
 		t.Fatalf("Expected no error when history files don't exist, got: %v", err)
 	}
@@ -196,3 +215,139 @@ func TestGetUsedCommands_NoHistoryFiles(t *testing.T) {
 		t.Errorf("Expected empty command map, got %d commands", len(commands))
 	}
 }
+
+func TestHistoryFilePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("ZDOTDIR", filepath.Join(home, ".config", "zsh"))
+
+	// $HISTFILE naming a default file, and a symlink to it, are read once
+	t.Setenv("HISTFILE", "~/.zsh_history")
+	if err := os.WriteFile(filepath.Join(home, ".zsh_history"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(home, ".zsh_history"), filepath.Join(home, ".histfile")); err != nil {
+		t.Fatal(err)
+	}
+
+	got := historyFilePaths(home)
+	want := []string{
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".config", "zsh", ".zsh_history"),
+		filepath.Join(home, ".local", "share", "fish", "fish_history"),
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("historyFilePaths() = %v, want %v", got, want)
+	}
+}
+
+func TestGetUsedCommands_HistfileAndFish(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("XDG_DATA_HOME", filepath.Join(home, "data"))
+
+	histFile := filepath.Join(home, "sessions", "zsh_history")
+	t.Setenv("HISTFILE", histFile)
+	fishHistory := filepath.Join(home, "data", "fish", "fish_history")
+	for path, content := range map[string]string{
+		histFile:    ": 1609459200:0;terraform plan\n",
+		fishHistory: "- cmd: rg TODO\n  when: 1609459200\n  paths:\n    - TODO\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commands, err := GetUsedCommands(false)
+	if err != nil {
+		t.Fatalf("GetUsedCommands failed: %v", err)
+	}
+	if !commands["terraform"] || !commands["rg"] {
+		t.Errorf("expected terraform and rg, got %v", commands)
+	}
+	if commands["when:"] || commands["paths:"] {
+		t.Errorf("fish metadata parsed as commands: %v", commands)
+	}
+}
+
+func TestParseHistoryFile_Gzip(t *testing.T) {
+	histFile := filepath.Join(t.TempDir(), ".bash_history.1.gz")
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("terraform plan\nterraform apply\nrg TODO\n"))
+	gz.Close()
+	if err := os.WriteFile(histFile, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	commands := make(map[string]int)
+	if err := parseHistoryFile(histFile, commands); err != nil {
+		t.Fatalf("parseHistoryFile failed: %v", err)
+	}
+	if commands["terraform"] != 2 || commands["rg"] != 1 {
+		t.Errorf("counts = %v, want terraform 2 and rg 1", commands)
+	}
+
+	// A file named .gz that isn't gzipped is an error, not garbage commands
+	if err := os.WriteFile(histFile, []byte("git status\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := parseHistoryFile(histFile, make(map[string]int)); err == nil {
+		t.Error("expected an error for a corrupt .gz file")
+	}
+}
+
+func TestGetUsedCommands_IncludeRotated(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("HISTFILE", "")
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("XDG_DATA_HOME", "")
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Write([]byte("terraform plan\n"))
+	gz.Close()
+	for name, content := range map[string][]byte{
+		".bash_history":                             []byte("git status\n"),
+		".bash_history.1":                           []byte("kubectl get pods\n"),
+		".bash_history.2.gz":                        buf.Bytes(),
+		".bash_history.bak":                         []byte("notrotated\n"),
+		".zsh_history-20240101.gz":                  []byte("corrupt"),
+		filepath.Join(".zsh_sessions", "A.history"): []byte("htop\n"),
+	} {
+		path := filepath.Join(home, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	commands, err := GetUsedCommands(false)
+	if err != nil {
+		t.Fatalf("GetUsedCommands failed: %v", err)
+	}
+	if !commands["git"] || commands["kubectl"] || commands["terraform"] {
+		t.Errorf("without rotated files, got %v", commands)
+	}
+
+	commands, err = GetUsedCommands(true)
+	if err != nil {
+		t.Fatalf("a corrupt rotated file should be skipped, got: %v", err)
+	}
+	for _, want := range []string{"git", "kubectl", "terraform", "htop"} {
+		if !commands[want] {
+			t.Errorf("expected %q with rotated files, got %v", want, commands)
+		}
+	}
+	if commands["notrotated"] {
+		t.Errorf(".bash_history.bak is not a rotated copy, got %v", commands)
+	}
+}
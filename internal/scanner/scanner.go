@@ -1,26 +1,45 @@
 package scanner
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jvalentini/tabgen/internal/parser"
 	"github.com/jvalentini/tabgen/internal/types"
 )
 
 // Scanner discovers executables in $PATH
 type Scanner struct {
 	excludePatterns []string
-	quickMode       bool // Skip --help and man checks during scan
+	quickMode       bool           // Skip --help and man checks during scan
+	extraDirs       []string       // Directories walked after $PATH
+	extraDirsAll    bool           // Skip the history filter for extraDirs
+	workers         int            // Concurrent --help/man checks in full mode (default: NumCPU)
+	dedupe          bool           // Fold names resolving to the same file into one entry
+	skipNonCLI      bool           // Skip shared libraries and GUI programs (reads each file's header)
+	since           time.Time      // Binaries not modified after this keep their previous entry
+	previous        *types.Catalog // Catalog from the last scan, used with since
+	helpRetries     int            // Extra --help attempts after a transient failure to start the tool
+	helpTimeout     time.Duration  // Limit on each --help check in full mode
+	includeRotated  bool           // Also read rotated and session history files
+	progress        func(done, total int)
+	excluded        int // Names skipped by exclusion patterns in the last Scan
 }
 
 // New creates a new Scanner (quick mode by default)
 func New(excluded []string) *Scanner {
-	return &Scanner{excludePatterns: excluded, quickMode: true}
+	defaults := parser.DefaultConfig()
+	return &Scanner{excludePatterns: excluded, quickMode: true, helpRetries: defaults.HelpRetries, helpTimeout: defaults.HelpTimeout}
 }
 
 // NewFull creates a Scanner that checks --help and man pages (slower)
@@ -30,26 +49,129 @@ func NewFull(excluded []string) *Scanner {
 	return s
 }
 
+// SetProgress registers a callback invoked after each tool is checked in full mode
+func (s *Scanner) SetProgress(fn func(done, total int)) {
+	s.progress = fn
+}
+
+// SetWorkers bounds the number of concurrent --help/man checks in full mode
+// (0 = NumCPU)
+func (s *Scanner) SetWorkers(n int) {
+	s.workers = n
+}
+
+// SetDedupe makes Scan fold names that resolve to the same file (e.g. vi -> vim)
+// into a single entry, recording the other names as Aliases
+func (s *Scanner) SetDedupe(dedupe bool) {
+	s.dedupe = dedupe
+}
+
+// SetHelpRetries sets how many more times full mode runs "--help" when the
+// tool couldn't be started for a transient reason (default: 2)
+func (s *Scanner) SetHelpRetries(n int) {
+	s.helpRetries = n
+}
+
+// SetIncludeRotated makes Scan also read rotated history files, gzipped or
+// not, and per-session histories when deciding which tools were used
+func (s *Scanner) SetIncludeRotated(include bool) {
+	s.includeRotated = include
+}
+
+// SetSkipNonCLI makes Scan read each candidate's file header and skip shared
+// libraries and programs linked against GUI toolkits
+func (s *Scanner) SetSkipNonCLI(skip bool) {
+	s.skipNonCLI = skip
+}
+
+// SetSince makes Scan reuse previous entries for binaries not modified since
+// cutoff, so full mode only re-checks new or changed tools
+func (s *Scanner) SetSince(cutoff time.Time, previous *types.Catalog) {
+	s.since = cutoff
+	s.previous = previous
+}
+
+// AddDirs appends directories to walk after $PATH entries. $PATH keeps
+// precedence for duplicate names. If all is true, tools in these
+// directories are cataloged even when absent from shell history.
+func (s *Scanner) AddDirs(dirs []string, all bool) {
+	s.extraDirs = append(s.extraDirs, dirs...)
+	s.extraDirsAll = all
+}
+
+// scanDir is a directory to walk and whether its tools must appear in history
+type scanDir struct {
+	path           string
+	requireHistory bool
+}
+
+// scanDirs returns $PATH entries followed by extra directories, in precedence order
+func (s *Scanner) scanDirs() []scanDir {
+	var dirs []scanDir
+	for dir := range strings.SplitSeq(os.Getenv("PATH"), string(os.PathListSeparator)) {
+		if dir == "" {
+			continue
+		}
+		dirs = append(dirs, scanDir{path: expandPathDir(dir), requireHistory: true})
+	}
+	for _, dir := range s.extraDirs {
+		if dir == "" {
+			continue
+		}
+		// Store absolute paths so the catalog works from any directory
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		dirs = append(dirs, scanDir{path: dir, requireHistory: !s.extraDirsAll})
+	}
+	return dirs
+}
+
+// expandPathDir expands a leading "~/" and $VAR or ${VAR} references in a
+// $PATH entry, which some shells leave unexpanded
+func expandPathDir(dir string) string {
+	dir = os.ExpandEnv(dir)
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = home + dir[1:]
+		}
+	}
+	return dir
+}
+
+// ExcludedCount returns how many executable names the last Scan skipped
+// because they matched an exclusion pattern
+func (s *Scanner) ExcludedCount() int {
+	return s.excluded
+}
+
 // isExcluded checks if a name matches any exclusion pattern
 func (s *Scanner) isExcluded(name string) (bool, error) {
-	for _, pattern := range s.excludePatterns {
+	pattern, err := MatchExclusion(s.excludePatterns, name)
+	return pattern != "", err
+}
+
+// MatchExclusion returns the first of patterns that excludes name, or "" if
+// none does. Patterns are globs, and also match the name exactly.
+func MatchExclusion(patterns []string, name string) (string, error) {
+	for _, pattern := range patterns {
 		// Try glob match first
 		matched, err := filepath.Match(pattern, name)
 		if err != nil {
-			return false, fmt.Errorf("invalid exclusion pattern %q: %w", pattern, err)
+			return "", fmt.Errorf("invalid exclusion pattern %q: %w", pattern, err)
 		}
 		if matched {
-			return true, nil
+			return pattern, nil
 		}
 		// Also try exact match
 		if pattern == name {
-			return true, nil
+			return pattern, nil
 		}
 	}
-	return false, nil
+	return "", nil
 }
 
-// Scan walks $PATH and returns a catalog of discovered tools
+// Scan walks $PATH (plus any extra directories) and returns a catalog of discovered tools
 // Only includes tools that appear in shell history
 func (s *Scanner) Scan() (*types.Catalog, error) {
 	catalog := &types.Catalog{
@@ -57,22 +179,17 @@ func (s *Scanner) Scan() (*types.Catalog, error) {
 		Tools:    make(map[string]types.CatalogEntry),
 	}
 
-	usedCommands, err := GetUsedCommands()
+	usedCommands, err := GetUsedCommands(s.includeRotated)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read shell history: %w", err)
 	}
 
-	pathEnv := os.Getenv("PATH")
-	if pathEnv == "" {
-		return catalog, nil
-	}
-
 	seen := make(map[string]bool)
+	unchanged := make(map[string]bool) // Reused from the previous catalog
+	s.excluded = 0
 
-	for dir := range strings.SplitSeq(pathEnv, string(os.PathListSeparator)) {
-		if dir == "" {
-			continue
-		}
+	for _, sd := range s.scanDirs() {
+		dir := sd.path
 
 		entries, err := os.ReadDir(dir)
 		if err != nil {
@@ -96,6 +213,7 @@ func (s *Scanner) Scan() (*types.Catalog, error) {
 				return nil, fmt.Errorf("checking exclusion for %s: %w", name, err)
 			}
 			if excluded {
+				s.excluded++
 				continue
 			}
 
@@ -103,7 +221,7 @@ func (s *Scanner) Scan() (*types.Catalog, error) {
 				continue
 			}
 
-			if !usedCommands[name] {
+			if sd.requireHistory && !usedCommands[name] {
 				continue
 			}
 
@@ -117,54 +235,194 @@ func (s *Scanner) Scan() (*types.Catalog, error) {
 				continue
 			}
 
-			catalogEntry := types.CatalogEntry{
+			if s.skipNonCLI && isNonCLI(fullPath) {
+				continue
+			}
+
+			if prev, ok := s.unchangedEntry(name, fullPath); ok {
+				catalog.Tools[name] = prev
+				unchanged[name] = true
+				continue
+			}
+
+			catalog.Tools[name] = types.CatalogEntry{
 				Name:      name,
 				Path:      fullPath,
 				Generated: false,
 				LastScan:  time.Now(),
 			}
+		}
+	}
 
-			if !s.quickMode {
-				hasHelp, helpErr := s.checkHelp(fullPath)
-				if helpErr != nil {
-					return nil, fmt.Errorf("checking help for %s: %w", name, helpErr)
-				}
-				catalogEntry.HasHelp = hasHelp
+	if s.dedupe {
+		dedupeByRealPath(catalog)
+	}
 
-				hasMan, manErr := s.checkManPage(name)
-				if manErr != nil {
-					return nil, fmt.Errorf("checking man page for %s: %w", name, manErr)
-				}
-				catalogEntry.HasManPage = hasMan
+	if !s.quickMode {
+		if err := s.checkTools(catalog, unchanged); err != nil {
+			return nil, err
+		}
+	}
+
+	return catalog, nil
+}
+
+// dedupeByRealPath folds catalog entries whose paths resolve to the same file
+// into one entry, recording the other names as its Aliases. The entry named
+// after the real file is kept if present, otherwise the alphabetically first.
+func dedupeByRealPath(catalog *types.Catalog) {
+	groups := make(map[string][]string)
+	for name, entry := range catalog.Tools {
+		real, err := filepath.EvalSymlinks(entry.Path)
+		if err != nil {
+			continue
+		}
+		groups[real] = append(groups[real], name)
+	}
+
+	for real, names := range groups {
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		primary := names[0]
+		if base := filepath.Base(real); slices.Contains(names, base) {
+			primary = base
+		}
+
+		entry := catalog.Tools[primary]
+		entry.Aliases = nil
+		for _, name := range names {
+			if name != primary {
+				entry.Aliases = append(entry.Aliases, name)
+				delete(catalog.Tools, name)
 			}
+		}
+		catalog.Tools[primary] = entry
+	}
+}
+
+// unchangedEntry returns the previous catalog entry for a binary at the same
+// path that hasn't been modified since the SetSince cutoff
+func (s *Scanner) unchangedEntry(name, fullPath string) (types.CatalogEntry, bool) {
+	if s.previous == nil || s.since.IsZero() {
+		return types.CatalogEntry{}, false
+	}
+	prev, ok := s.previous.Tools[name]
+	if !ok || prev.Path != fullPath {
+		return types.CatalogEntry{}, false
+	}
+	// Stat follows symlinks, so a reinstalled target counts as modified
+	info, err := os.Stat(fullPath)
+	if err != nil || info.ModTime().After(s.since) {
+		return types.CatalogEntry{}, false
+	}
+	prev.Aliases = nil // Recomputed by dedupe
+	return prev, true
+}
 
-			catalog.Tools[name] = catalogEntry
+// checkTools fills in HasHelp and HasManPage for every catalog entry not in
+// skip using a pool of workers, since each check spawns a process
+func (s *Scanner) checkTools(catalog *types.Catalog, skip map[string]bool) error {
+	workers := s.workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	// Snapshot names before workers start writing to the map
+	toCheck := make([]string, 0, len(catalog.Tools))
+	for name := range catalog.Tools {
+		if !skip[name] {
+			toCheck = append(toCheck, name)
 		}
 	}
+	// Don't use more workers than tools
+	if workers > len(toCheck) {
+		workers = len(toCheck)
+	}
 
-	return catalog, nil
+	names := make(chan string)
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		done     int
+		firstErr error
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Go(func() {
+			for name := range names {
+				mu.Lock()
+				entry := catalog.Tools[name]
+				mu.Unlock()
+
+				err := s.checkEntry(&entry)
+
+				mu.Lock()
+				catalog.Tools[name] = entry
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				done++
+				if s.progress != nil {
+					s.progress(done, len(toCheck))
+				}
+				mu.Unlock()
+			}
+		})
+	}
+
+	for _, name := range toCheck {
+		names <- name
+	}
+	close(names)
+	wg.Wait()
+
+	return firstErr
+}
+
+// checkEntry runs the --help and man page checks for one entry
+func (s *Scanner) checkEntry(entry *types.CatalogEntry) error {
+	hasHelp, err := s.checkHelp(entry.Path)
+	if err != nil {
+		return fmt.Errorf("checking help for %s: %w", entry.Name, err)
+	}
+	entry.HasHelp = hasHelp
+
+	hasMan, err := s.checkManPage(entry.Name)
+	if err != nil {
+		return fmt.Errorf("checking man page for %s: %w", entry.Name, err)
+	}
+	entry.HasManPage = hasMan
+	return nil
 }
 
-// checkHelp tests if a tool responds to --help
+// checkHelp tests if a tool responds to --help with actual output
 // Returns (hasHelp, error) - error is non-nil only for permission-related failures
 func (s *Scanner) checkHelp(path string) (bool, error) {
-	cmd := exec.Command(path, "--help")
-	cmd.Env = append(os.Environ(), "LC_ALL=C")
-	err := cmd.Run()
+	// Same timeout and environment as the parser, so a tool waiting on a
+	// pager or stdin can't hang the scan
+	ctx, cancel := context.WithTimeout(context.Background(), s.helpTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	output, err := parser.RetryTransient(s.helpRetries, parser.DefaultConfig().HelpRetryDelay, func() ([]byte, error) {
+		cmd = parser.HelpCommand(ctx, path, "--help")
+		return cmd.CombinedOutput()
+	})
 	if err != nil {
 		// Check for permission errors - these should be surfaced
 		if isPermissionError(err) {
 			return false, fmt.Errorf("permission denied running %s --help: %w", path, err)
 		}
-		// Many tools return non-zero for --help but still provide help
-		// If the process ran (ProcessState exists), treat as success
-		if cmd.ProcessState != nil {
-			return true, nil
+		// Many tools return non-zero for --help but still provide help,
+		// so only fail if the process never ran
+		if cmd.ProcessState == nil {
+			return false, nil
 		}
-		// Tool doesn't support --help - not an error, just no help
-		return false, nil
 	}
-	return true, nil
+	// Help may go to stdout or stderr; silence means no help regardless of exit code
+	return len(strings.TrimSpace(string(output))) > 0, nil
 }
 
 // checkManPage tests if a man page exists for a tool
@@ -5,7 +5,11 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/jvalentini/tabgen/internal/types"
 )
 
 func TestNew(t *testing.T) {
@@ -327,6 +331,9 @@ test.dll run
 	if _, exists := catalog.Tools["test.dll"]; exists {
 		t.Error("'test.dll' should be excluded by pattern *.dll")
 	}
+	if got := scanner.ExcludedCount(); got != 1 {
+		t.Errorf("ExcludedCount() = %d, want 1", got)
+	}
 }
 
 func TestScanner_EmptyPath(t *testing.T) {
@@ -427,3 +434,327 @@ func TestIsExcluded(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchExclusion(t *testing.T) {
+	patterns := []string{"*.dll", "python*", "python2.7"}
+
+	pattern, err := MatchExclusion(patterns, "python2.7")
+	if err != nil {
+		t.Fatalf("MatchExclusion failed: %v", err)
+	}
+	if pattern != "python*" {
+		t.Errorf("expected first matching pattern python*, got %q", pattern)
+	}
+
+	if pattern, _ := MatchExclusion(patterns, "ruby"); pattern != "" {
+		t.Errorf("expected no match for ruby, got %q", pattern)
+	}
+
+	if _, err := MatchExclusion([]string{"[invalid"}, "tool"); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestScan_ExtraDirs(t *testing.T) {
+	pathDir := t.TempDir()
+	extraDir := t.TempDir()
+	homeDir := t.TempDir()
+
+	for _, f := range []struct{ dir, name string }{
+		{pathDir, "shared"},
+		{extraDir, "shared"},
+		{extraDir, "local-tool"},
+		{extraDir, "unused-local"},
+	} {
+		path := filepath.Join(f.dir, f.name)
+		if err := os.WriteFile(path, []byte("#!/bin/sh\necho test"), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", f.name, err)
+		}
+	}
+
+	histPath := filepath.Join(homeDir, ".bash_history")
+	if err := os.WriteFile(histPath, []byte("shared\nlocal-tool\n"), 0644); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	origPath := os.Getenv("PATH")
+	origHome := os.Getenv("HOME")
+	os.Setenv("PATH", pathDir)
+	os.Setenv("HOME", homeDir)
+	defer func() {
+		os.Setenv("PATH", origPath)
+		os.Setenv("HOME", origHome)
+	}()
+
+	t.Run("history filter applies", func(t *testing.T) {
+		s := New(nil)
+		s.AddDirs([]string{extraDir}, false)
+		catalog, err := s.Scan()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if got := catalog.Tools["shared"].Path; got != filepath.Join(pathDir, "shared") {
+			t.Errorf("expected $PATH to win for shared, got %s", got)
+		}
+		if _, ok := catalog.Tools["local-tool"]; !ok {
+			t.Error("expected local-tool from extra dir")
+		}
+		if _, ok := catalog.Tools["unused-local"]; ok {
+			t.Error("unused-local should be filtered by history")
+		}
+	})
+
+	t.Run("all skips history filter", func(t *testing.T) {
+		s := New([]string{"local-*"})
+		s.AddDirs([]string{extraDir}, true)
+		catalog, err := s.Scan()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, ok := catalog.Tools["unused-local"]; !ok {
+			t.Error("expected unused-local with all=true")
+		}
+		if _, ok := catalog.Tools["local-tool"]; ok {
+			t.Error("local-tool should still be excluded")
+		}
+	})
+}
+
+func TestCheckHelp_RequiresOutput(t *testing.T) {
+	dir := t.TempDir()
+	scripts := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"stdout-help", "#!/bin/sh\necho 'Usage: tool'\n", true},
+		{"stderr-help-nonzero", "#!/bin/sh\necho 'Usage: tool' >&2\nexit 2\n", true},
+		{"silent-zero", "#!/bin/sh\nexit 0\n", false},
+		{"silent-nonzero", "#!/bin/sh\nexit 1\n", false},
+		{"needs-plain-pager", "#!/bin/sh\n[ \"$PAGER\" = cat ] && [ \"$TERM\" = dumb ] && echo 'Usage: tool'\n", true},
+		{"reads-stdin", "#!/bin/sh\nread line\necho \"Usage: $line\"\n", true},
+	}
+
+	s := NewFull(nil)
+	for _, sc := range scripts {
+		t.Run(sc.name, func(t *testing.T) {
+			path := filepath.Join(dir, sc.name)
+			if err := os.WriteFile(path, []byte(sc.content), 0755); err != nil {
+				t.Fatalf("failed to create script: %v", err)
+			}
+
+			got, err := s.checkHelp(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != sc.want {
+				t.Errorf("checkHelp() = %v, want %v", got, sc.want)
+			}
+		})
+	}
+}
+
+func TestCheckHelp_Timeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hangs")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\nexec sleep 30\n"), 0755); err != nil {
+		t.Fatalf("failed to create script: %v", err)
+	}
+
+	s := NewFull(nil)
+	s.helpTimeout = 100 * time.Millisecond
+	start := time.Now()
+	got, err := s.checkHelp(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got {
+		t.Error("expected no help from a tool that never answers")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("checkHelp took %v, expected it to give up after the timeout", elapsed)
+	}
+}
+
+func TestScan_FullChecksHelp(t *testing.T) {
+	binDir := t.TempDir()
+	homeDir := t.TempDir()
+
+	tools := map[string]string{
+		"helpful": "#!/bin/sh\necho 'Usage: helpful [options]'",
+		"silent":  "#!/bin/sh\nexit 0",
+	}
+	for name, content := range tools {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".bash_history"), []byte("helpful\nsilent\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keep the system PATH reachable for sh and man
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", homeDir)
+
+	s := NewFull(nil)
+	s.SetWorkers(64) // more workers than tools is clamped
+	var calls, lastDone, lastTotal int
+	s.SetProgress(func(done, total int) {
+		calls++
+		lastDone, lastTotal = done, total
+	})
+
+	catalog, err := s.Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !catalog.Tools["helpful"].HasHelp {
+		t.Error("expected helpful to have HasHelp")
+	}
+	if catalog.Tools["silent"].HasHelp {
+		t.Error("expected silent to not have HasHelp")
+	}
+	if calls != len(catalog.Tools) || lastDone != lastTotal || lastTotal != len(catalog.Tools) {
+		t.Errorf("progress: %d calls, last %d/%d, want %d", calls, lastDone, lastTotal, len(catalog.Tools))
+	}
+}
+
+func TestScan_ExpandsTildeAndVarsInPath(t *testing.T) {
+	homeDir := t.TempDir()
+	for _, dir := range []string{"bin", "tools"} {
+		if err := os.Mkdir(filepath.Join(homeDir, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, "bin", "mytool"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, "tools", "othertool"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".bash_history"), []byte("mytool\nothertool\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("HOME", homeDir)
+	t.Setenv("TOOLS_ROOT", homeDir)
+	t.Setenv("PATH", "~/bin"+string(os.PathListSeparator)+"${TOOLS_ROOT}/tools")
+
+	catalog, err := New(nil).Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := catalog.Tools["mytool"].Path; got != filepath.Join(homeDir, "bin", "mytool") {
+		t.Errorf("mytool path = %q, want it under the expanded ~/bin", got)
+	}
+	if _, ok := catalog.Tools["othertool"]; !ok {
+		t.Error("expected othertool from ${TOOLS_ROOT}/tools")
+	}
+}
+
+func TestScan_DedupeSymlinks(t *testing.T) {
+	binDir := t.TempDir()
+	homeDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(binDir, "vim"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range []string{"vi", "view"} {
+		if err := os.Symlink("vim", filepath.Join(binDir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(binDir, "other"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".bash_history"), []byte("vi\nvim\nview\nother\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", binDir)
+	t.Setenv("HOME", homeDir)
+
+	// Without dedupe every name gets its own entry
+	catalog, err := New(nil).Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(catalog.Tools) != 4 {
+		t.Errorf("expected 4 entries without dedupe, got %d", len(catalog.Tools))
+	}
+
+	s := New(nil)
+	s.SetDedupe(true)
+	catalog, err = s.Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(catalog.Tools) != 2 {
+		t.Fatalf("expected vim and other, got %v", catalog.Tools)
+	}
+	vim, ok := catalog.Tools["vim"]
+	if !ok {
+		t.Fatal("expected the entry named after the real file to be kept")
+	}
+	if !slices.Equal(vim.Aliases, []string{"vi", "view"}) {
+		t.Errorf("vim aliases = %v, want [vi view]", vim.Aliases)
+	}
+	if len(catalog.Tools["other"].Aliases) != 0 {
+		t.Errorf("other should have no aliases, got %v", catalog.Tools["other"].Aliases)
+	}
+}
+
+func TestScan_SinceReusesUnchangedEntries(t *testing.T) {
+	binDir := t.TempDir()
+	homeDir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"old":   "#!/bin/sh\nexit 0",
+		"fresh": "#!/bin/sh\necho 'Usage: fresh [options]'",
+	} {
+		if err := os.WriteFile(filepath.Join(binDir, name), []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lastWeek := time.Now().Add(-7 * 24 * time.Hour)
+	if err := os.Chtimes(filepath.Join(binDir, "old"), lastWeek, lastWeek); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(homeDir, ".bash_history"), []byte("old\nfresh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("HOME", homeDir)
+
+	// The previous scan recorded help for "old"; re-checking would clear it
+	previous := &types.Catalog{Tools: map[string]types.CatalogEntry{
+		"old":   {Name: "old", Path: filepath.Join(binDir, "old"), HasHelp: true, Generated: true},
+		"fresh": {Name: "fresh", Path: filepath.Join(binDir, "fresh")},
+	}}
+
+	s := NewFull(nil)
+	s.SetSince(time.Now().Add(-24*time.Hour), previous)
+	var checked int
+	s.SetProgress(func(done, total int) { checked = total })
+
+	catalog, err := s.Scan()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if old := catalog.Tools["old"]; !old.HasHelp || !old.Generated {
+		t.Errorf("old should keep its previous entry, got %+v", old)
+	}
+	if !catalog.Tools["fresh"].HasHelp {
+		t.Error("fresh was modified recently and should have been checked")
+	}
+	if checked != 1 {
+		t.Errorf("expected 1 tool checked, got %d", checked)
+	}
+}
@@ -0,0 +1,144 @@
+package types
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Entry is one flag or command of a parsed tool, addressed by its path from
+// the tool: "--verbose", "remote", or "remote add --track"
+type Entry struct {
+	Path        string
+	Depth       int    // Number of enclosing commands
+	IsFlag      bool   // Whether the entry is a flag rather than a command
+	Description string // Help text
+	Suspect     string // Why the entry looks like a parser false positive, or ""
+}
+
+// Entries lists the tool's global flags, then each command followed by its
+// flags and nested commands, depth first
+func (t *Tool) Entries() []Entry {
+	var entries []Entry
+	appendFlagEntries(&entries, t.GlobalFlags, "", 0)
+	appendCommandEntries(&entries, t.Subcommands, "", 0)
+	return entries
+}
+
+// appendFlagEntries adds the flags of the command at path ("" for global flags)
+func appendFlagEntries(entries *[]Entry, flags []Flag, path string, depth int) {
+	for _, flag := range flags {
+		*entries = append(*entries, Entry{
+			Path:        joinPath(path, flag.Name),
+			Depth:       depth,
+			IsFlag:      true,
+			Description: flag.Description,
+			Suspect:     suspectFlag(flag),
+		})
+	}
+}
+
+// appendCommandEntries adds each command, then its flags and subcommands
+func appendCommandEntries(entries *[]Entry, cmds []Command, path string, depth int) {
+	for _, cmd := range cmds {
+		cmdPath := joinPath(path, cmd.Name)
+		*entries = append(*entries, Entry{
+			Path:        cmdPath,
+			Depth:       depth,
+			Description: cmd.Description,
+			Suspect:     suspectCommand(cmd),
+		})
+		appendFlagEntries(entries, cmd.Flags, cmdPath, depth+1)
+		appendCommandEntries(entries, cmd.Subcommands, cmdPath, depth+1)
+	}
+}
+
+// joinPath appends name to a space-separated command path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + " " + name
+}
+
+// suspectCommand explains why a command looks like a misparsed line rather
+// than a real subcommand, e.g. the key bindings listed in less's help
+func suspectCommand(cmd Command) string {
+	name := []rune(cmd.Name)
+	switch {
+	case len(name) == 0:
+		return ""
+	case len(name) == 1:
+		return "single-character name"
+	case strings.ContainsFunc(cmd.Name, unicode.IsUpper):
+		return "uppercase in name"
+	case !unicode.IsLetter(name[0]) && !unicode.IsDigit(name[0]):
+		return "name starts with punctuation"
+	}
+	return ""
+}
+
+// suspectFlag explains why a long flag looks misparsed, e.g. "--foo)" picked
+// up from prose
+func suspectFlag(flag Flag) string {
+	if !strings.HasPrefix(flag.Name, "--") {
+		return ""
+	}
+	if strings.ContainsFunc(flag.Name[2:], func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !strings.ContainsRune("-_.:", r)
+	}) {
+		return "punctuation in name"
+	}
+	return ""
+}
+
+// Remove drops the flags and commands at the given paths (see Entry), along
+// with everything under a dropped command, and returns how many paths
+// matched. A flag matches any of its spellings; unknown paths are ignored.
+func (t *Tool) Remove(paths []string) int {
+	removed := 0
+	for _, path := range paths {
+		fields := strings.Fields(path)
+		if len(fields) == 0 {
+			continue
+		}
+		flags, cmds := &t.GlobalFlags, &t.Subcommands
+		found := true
+		for _, name := range fields[:len(fields)-1] {
+			i := commandIndex(*cmds, name)
+			if i < 0 {
+				found = false
+				break
+			}
+			flags, cmds = &(*cmds)[i].Flags, &(*cmds)[i].Subcommands
+		}
+		if !found {
+			continue
+		}
+
+		last := fields[len(fields)-1]
+		if strings.HasPrefix(last, "-") {
+			for i, flag := range *flags {
+				if slices.Contains(flag.Names(), last) {
+					*flags = slices.Delete(*flags, i, i+1)
+					removed++
+					break
+				}
+			}
+		} else if i := commandIndex(*cmds, last); i >= 0 {
+			*cmds = slices.Delete(*cmds, i, i+1)
+			removed++
+		}
+	}
+	return removed
+}
+
+// commandIndex returns the index of the command called name, or -1
+func commandIndex(cmds []Command, name string) int {
+	for i, cmd := range cmds {
+		if cmd.Name == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Sanitize repairs a parsed tool so generated scripts stay well-formed: flags
+// and commands with empty or invalid names (whitespace, control characters,
+// shell metacharacters) are dropped, as are invalid aliases and argument values, and control
+// characters such as embedded newlines are replaced in descriptions. It
+// returns a description of each change, or nil if the tool was already clean.
+func (t *Tool) Sanitize() []string {
+	var issues []string
+	t.GlobalFlags = sanitizeFlags(t.GlobalFlags, "", &issues)
+	t.Subcommands = sanitizeCommands(t.Subcommands, "", &issues)
+	return issues
+}
+
+// sanitizeCommands sanitizes commands and their flags and subcommands. path is
+// the parent command path, used in issue messages.
+func sanitizeCommands(cmds []Command, path string, issues *[]string) []Command {
+	kept := cmds[:0]
+	for _, cmd := range cmds {
+		if !ValidCommandName(cmd.Name) {
+			*issues = append(*issues, fmt.Sprintf("dropped command with invalid name %q%s", cmd.Name, under(path)))
+			continue
+		}
+		cmdPath := strings.TrimSpace(path + " " + cmd.Name)
+
+		aliases := cmd.Aliases[:0]
+		for _, alias := range cmd.Aliases {
+			if ValidCommandName(alias) {
+				aliases = append(aliases, alias)
+			} else {
+				*issues = append(*issues, fmt.Sprintf("dropped invalid alias %q of %s", alias, cmdPath))
+			}
+		}
+		cmd.Aliases = aliases
+
+		if desc, ok := cleanDescription(cmd.Description); !ok {
+			cmd.Description = desc
+			*issues = append(*issues, "stripped control characters from description of "+cmdPath)
+		}
+		cmd.Flags = sanitizeFlags(cmd.Flags, cmdPath, issues)
+		cmd.Subcommands = sanitizeCommands(cmd.Subcommands, cmdPath, issues)
+		kept = append(kept, cmd)
+	}
+	return kept
+}
+
+// sanitizeFlags sanitizes the flags of the command at path ("" for global flags)
+func sanitizeFlags(flags []Flag, path string, issues *[]string) []Flag {
+	kept := flags[:0]
+	for _, flag := range flags {
+		if !ValidFlagName(flag.Name) {
+			*issues = append(*issues, fmt.Sprintf("dropped flag with invalid name %q%s", flag.Name, under(path)))
+			continue
+		}
+		if flag.Short != "" && !ValidFlagName(flag.Short) {
+			*issues = append(*issues, fmt.Sprintf("dropped invalid short form %q of %s", flag.Short, flag.Name))
+			flag.Short = ""
+		}
+
+		aliases := flag.ShortAliases[:0]
+		for _, alias := range flag.ShortAliases {
+			if ValidFlagName(alias) {
+				aliases = append(aliases, alias)
+			} else {
+				*issues = append(*issues, fmt.Sprintf("dropped invalid short form %q of %s", alias, flag.Name))
+			}
+		}
+		flag.ShortAliases = aliases
+
+		values := flag.ArgumentValues[:0]
+		for _, v := range flag.ArgumentValues {
+			if validWord(v) {
+				values = append(values, v)
+			} else {
+				*issues = append(*issues, fmt.Sprintf("dropped invalid value %q of %s", v, flag.Name))
+			}
+		}
+		flag.ArgumentValues = values
+
+		if desc, ok := cleanDescription(flag.Description); !ok {
+			flag.Description = desc
+			*issues = append(*issues, "stripped control characters from description of "+flag.Name)
+		}
+		kept = append(kept, flag)
+	}
+	return kept
+}
+
+// under formats a command path for issue messages
+func under(path string) string {
+	if path == "" {
+		return ""
+	}
+	return " under " + path
+}
+
+// ValidFlagName reports whether name is a word prefixed with a dash, or a
+// plus for "+x" style options, with no shell metacharacters
+func ValidFlagName(name string) bool {
+	return (strings.HasPrefix(name, "-") || strings.HasPrefix(name, "+")) &&
+		validWord(name) && !strings.ContainsAny(name, shellMetachars)
+}
+
+// ValidCommandName reports whether name is a word that isn't a flag, with no
+// shell metacharacters.
+// Commands start case patterns, where globs and "#" or "~" also take
+// effect, so those are rejected too; flags keep them for "-?" and "-#".
+func ValidCommandName(name string) bool {
+	return !strings.HasPrefix(name, "-") && validWord(name) &&
+		!strings.ContainsAny(name, shellMetachars+"?!#~")
+}
+
+// shellMetachars would need quoting where scripts embed names unescaped, in
+// case patterns and single-quoted specs: quotes, expansions, grouping, and
+// redirections
+const shellMetachars = "\"'`$\\;&|<>()[]{}*"
+
+// validWord reports whether s is non-empty and free of whitespace and control characters
+func validWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanDescription replaces control characters (newlines, tabs, escapes) in
+// desc with spaces and collapses the result. ok is true if desc had none.
+func cleanDescription(desc string) (cleaned string, ok bool) {
+	if !strings.ContainsFunc(desc, unicode.IsControl) {
+		return desc, true
+	}
+	cleaned = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, desc)
+	return strings.Join(strings.Fields(cleaned), " "), false
+}
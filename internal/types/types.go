@@ -4,17 +4,45 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"strings"
 	"time"
 )
 
 // Flag represents a command-line flag/option
 type Flag struct {
-	Name           string   `json:"name"`                     // Long form, e.g., "--output"
-	Short          string   `json:"short,omitempty"`          // Short form, e.g., "-o"
-	Arg            string   `json:"arg,omitempty"`            // Argument name, e.g., "format"
+	Name           string   `json:"name"`                      // Long form, e.g., "--output"
+	Short          string   `json:"short,omitempty"`           // Short form, e.g., "-o"
+	ShortAliases   []string `json:"short_aliases,omitempty"`   // Further short forms, e.g., ["-?"] for "-h, -?"
+	Arg            string   `json:"arg,omitempty"`             // Argument name, e.g., "format"
+	OptionalArg    bool     `json:"optional_arg,omitempty"`    // Argument may be omitted, only given as "--flag=value"
 	ArgumentValues []string `json:"argument_values,omitempty"` // Allowed values, e.g., ["json", "yaml"]
-	Description    string   `json:"description,omitempty"`    // Help text
-	Required       bool     `json:"required,omitempty"`       // Whether the flag is required
+	Default        string   `json:"default,omitempty"`         // Value used when the flag is omitted, e.g., "json"
+	Description    string   `json:"description,omitempty"`     // Help text
+	Required       bool     `json:"required,omitempty"`        // Whether the flag is required
+	Repeatable     bool     `json:"repeatable,omitempty"`      // Whether the flag may be given more than once, e.g. "--include PATTERN..."
+	ExclusiveGroup string   `json:"exclusive_group,omitempty"` // Flags sharing a group are mutually exclusive
+	Dynamic        string   `json:"dynamic,omitempty"`         // Kind of runtime-completed value, e.g. "branch"
+	Negatable      bool     `json:"negatable,omitempty"`       // Also accepted as "--no-<name>", e.g. "--[no-]cache"
+	Hidden         bool     `json:"hidden,omitempty"`          // Marked hidden in the help; left out of completions by default
+}
+
+// NegatedName returns the "--no-" form of a negatable long flag, or "" if it has none
+func (f Flag) NegatedName() string {
+	if !f.Negatable || !strings.HasPrefix(f.Name, "--") {
+		return ""
+	}
+	return "--no-" + strings.TrimPrefix(f.Name, "--")
+}
+
+// Names returns every spelling of the flag: Name, Short, then ShortAliases
+func (f Flag) Names() []string {
+	names := make([]string, 0, 2+len(f.ShortAliases))
+	for _, name := range append([]string{f.Name, f.Short}, f.ShortAliases...) {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // Command represents a command or subcommand
@@ -28,12 +56,13 @@ type Command struct {
 
 // Tool represents a parsed CLI tool
 type Tool struct {
-	Name        string    `json:"name"`                  // Binary name
-	Path        string    `json:"path"`                  // Full path to binary
-	Version     string    `json:"version,omitempty"`     // Detected version
-	ParsedAt    time.Time `json:"parsed_at"`             // When parsing occurred
-	Source      string    `json:"source"`                // "help", "man", or "both"
-	Subcommands []Command `json:"subcommands,omitempty"` // Top-level subcommands
+	Name        string    `json:"name"`                   // Binary name
+	Path        string    `json:"path"`                   // Full path to binary
+	Version     string    `json:"version,omitempty"`      // Detected version
+	Description string    `json:"description,omitempty"`  // One-line summary of the tool
+	ParsedAt    time.Time `json:"parsed_at"`              // When parsing occurred
+	Source      string    `json:"source"`                 // "help", "man", or "both"
+	Subcommands []Command `json:"subcommands,omitempty"`  // Top-level subcommands
 	GlobalFlags []Flag    `json:"global_flags,omitempty"` // Flags available to all subcommands
 }
 
@@ -41,7 +70,7 @@ type Tool struct {
 // This is used to detect when help output changes without a version bump.
 func (t *Tool) ContentHash() string {
 	// Create a minimal struct with just the content we care about
-	// Excludes: Name, Path, Version, ParsedAt, Source (these change or don't affect completions)
+	// Excludes: Name, Path, Version, Description, ParsedAt, Source (these change or don't affect completions)
 	content := struct {
 		Subcommands []Command `json:"subcommands,omitempty"`
 		GlobalFlags []Flag    `json:"global_flags,omitempty"`
@@ -64,12 +93,29 @@ type CatalogEntry struct {
 	Name             string    `json:"name"`                        // Binary name
 	Path             string    `json:"path"`                        // Full path to binary
 	Version          string    `json:"version,omitempty"`           // Current detected version
+	Description      string    `json:"description,omitempty"`       // One-line summary from the last parse
 	GeneratedVersion string    `json:"generated_version,omitempty"` // Version when completions were generated
 	ContentHash      string    `json:"content_hash,omitempty"`      // Hash of parsed tool content (subcommands/flags)
 	Generated        bool      `json:"generated"`                   // Whether completions have been generated
+	GeneratedAt      time.Time `json:"generated_at,omitzero"`       // When completions were last generated
 	LastScan         time.Time `json:"last_scan"`                   // When this tool was last scanned
 	HasHelp          bool      `json:"has_help,omitempty"`          // Whether --help works
 	HasManPage       bool      `json:"has_man_page,omitempty"`      // Whether man page exists
+	Imported         bool      `json:"imported,omitempty"`          // Whether the tool spec was imported rather than parsed
+	Dynamic          bool      `json:"dynamic,omitempty"`           // Whether the scripts include runtime value completions (generate --dynamic)
+	IncludeHidden    bool      `json:"include_hidden,omitempty"`    // Whether the scripts complete hidden flags (generate --include-hidden)
+	CompletionStyle  string    `json:"completion_style,omitempty"`  // Zsh style the scripts were written in, "verbose" or "compact" ("" before it was recorded: verbose)
+	Source           string    `json:"source,omitempty"`            // Source of the last parse (help, man, both, none)
+	LastError        string    `json:"last_error,omitempty"`        // Error from the last failed generation
+	FailedAt         time.Time `json:"failed_at,omitzero"`          // When the last generation failed
+	Aliases          []string  `json:"aliases,omitempty"`           // Other names that resolve to the same binary
+}
+
+// GeneratedBefore reports whether the entry's completions were generated
+// before cutoff. Entries generated before timestamps were recorded count as
+// older than any cutoff.
+func (e CatalogEntry) GeneratedBefore(cutoff time.Time) bool {
+	return e.Generated && e.GeneratedAt.Before(cutoff)
 }
 
 // Catalog is the full list of discovered tools
@@ -80,16 +126,37 @@ type Catalog struct {
 
 // Config holds TabGen configuration
 type Config struct {
-	TabGenDir    string   `json:"tabgen_dir"`    // Base directory (~/.tabgen)
-	Excluded     []string `json:"excluded"`      // Tools to skip
-	ScanOnStartup bool    `json:"scan_on_startup"` // Whether to scan on shell startup
+	TabGenDir     string   `json:"tabgen_dir"`               // Base directory (~/.tabgen)
+	Excluded      []string `json:"excluded"`                 // Tools to skip
+	ExtraDirs     []string `json:"extra_dirs,omitempty"`     // Directories scanned after $PATH, saved by 'scan --dir'
+	ExtraDirsAll  bool     `json:"extra_dirs_all,omitempty"` // Whether tools in ExtraDirs are cataloged even if absent from history
+	ScanOnStartup bool     `json:"scan_on_startup"`          // Whether to scan on shell startup
+	CompressTools bool     `json:"compress_tools,omitempty"` // Whether to gzip tools/<name>.json
+	SaveRawOutput bool     `json:"save_raw,omitempty"`       // Whether to keep raw help output in raw/<name>.txt
+	DedupeLinks   bool     `json:"dedupe_links,omitempty"`   // Whether scan folds symlinked names into one entry's aliases
+	ManFallback   bool     `json:"man_fallback,omitempty"`   // Whether tools without --help get their man page commands parsed too
+	KeepBackups   int      `json:"keep_backups,omitempty"`   // Previous completion scripts kept per tool and shell for 'tabgen rollback'
+	HelpRetries   int      `json:"help_retries,omitempty"`   // Extra attempts when a tool can't be started for a transient reason (0 = default of 2)
+
+	CompletionStyle string `json:"completion_style,omitempty"` // "verbose" (default) or "compact" zsh menus without descriptions
+
+	Overrides map[string]ToolOverride `json:"overrides,omitempty"` // Per-tool parser settings, keyed by tool name
+}
+
+// ToolOverride adjusts how a single tool is parsed. Zero values keep the defaults.
+type ToolOverride struct {
+	VersionCmds   []string `json:"version_cmds,omitempty"`    // Flags to try for version detection
+	MaxDepth      int      `json:"max_depth,omitempty"`       // Subcommand nesting depth to parse
+	ForceSource   string   `json:"force_source,omitempty"`    // "help" or "man" to use only that source
+	ExtraHelpArgs []string `json:"extra_help_args,omitempty"` // Arguments appended to "--help"
+	Remove        []string `json:"remove,omitempty"`          // Flags and commands dropped from every parse, by path (see Entry), e.g. ["--bogus", "remote add"]
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() Config {
 	return Config{
-		TabGenDir:    "~/.tabgen",
-		Excluded:     []string{},
+		TabGenDir:     "~/.tabgen",
+		Excluded:      []string{},
 		ScanOnStartup: true,
 	}
 }
@@ -1,6 +1,12 @@
 package types
 
-import "testing"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestContentHash_EmptyTool(t *testing.T) {
 	tool := &Tool{Name: "mytool", Path: "/usr/bin/mytool"}
@@ -152,3 +158,186 @@ func TestContentHash_NestedSubcommands(t *testing.T) {
 		t.Error("different nested subcommands should produce different hashes")
 	}
 }
+
+func TestSanitize_DescriptionNewline(t *testing.T) {
+	tool := &Tool{
+		GlobalFlags: []Flag{
+			{Name: "--verbose", Description: "Enable verbose\noutput\t(debug)"},
+			{Name: "--quiet", Description: "Be quiet"},
+		},
+	}
+
+	issues := tool.Sanitize()
+
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 issue, got %v", issues)
+	}
+	if got := tool.GlobalFlags[0].Description; got != "Enable verbose output (debug)" {
+		t.Errorf("description = %q", got)
+	}
+	if tool.GlobalFlags[1].Description != "Be quiet" {
+		t.Errorf("clean description changed: %q", tool.GlobalFlags[1].Description)
+	}
+}
+
+func TestSanitize_DropsInvalidNames(t *testing.T) {
+	tool := &Tool{
+		GlobalFlags: []Flag{
+			{Name: ""},
+			{Name: "--ok", Short: "-o\x1b", ArgumentValues: []string{"a", "b c"}},
+		},
+		Subcommands: []Command{
+			{Name: "bad\nname"},
+			{
+				Name:        "build",
+				Aliases:     []string{"b", ""},
+				Flags:       []Flag{{Name: "--with space"}},
+				Subcommands: []Command{{Name: "\x07"}, {Name: "docs"}},
+			},
+		},
+	}
+
+	issues := tool.Sanitize()
+
+	if len(issues) != 7 {
+		t.Errorf("expected 7 issues, got %d: %v", len(issues), issues)
+	}
+	if len(tool.GlobalFlags) != 1 || tool.GlobalFlags[0].Short != "" ||
+		len(tool.GlobalFlags[0].ArgumentValues) != 1 {
+		t.Errorf("unexpected global flags: %+v", tool.GlobalFlags)
+	}
+	if len(tool.Subcommands) != 1 {
+		t.Fatalf("expected 1 subcommand, got %+v", tool.Subcommands)
+	}
+	build := tool.Subcommands[0]
+	if len(build.Aliases) != 1 || len(build.Flags) != 0 ||
+		len(build.Subcommands) != 1 || build.Subcommands[0].Name != "docs" {
+		t.Errorf("unexpected build command: %+v", build)
+	}
+}
+
+func TestSanitize_DropsShellMetacharacters(t *testing.T) {
+	tool := &Tool{
+		GlobalFlags: []Flag{{Name: "-?"}, {Name: "-#"}, {Name: `--say"hi`}, {Name: "--$(id)"}},
+		Subcommands: []Command{
+			{Name: "db:migrate"},
+			{Name: "`id`"},
+			{Name: "x|y"},
+			{Name: "run", Aliases: []string{"r'"}},
+		},
+	}
+
+	issues := tool.Sanitize()
+
+	if len(issues) != 5 {
+		t.Errorf("expected 5 issues, got %d: %v", len(issues), issues)
+	}
+	if len(tool.GlobalFlags) != 2 || tool.GlobalFlags[0].Name != "-?" || tool.GlobalFlags[1].Name != "-#" {
+		t.Errorf("expected -? and -# kept, got %+v", tool.GlobalFlags)
+	}
+	if len(tool.Subcommands) != 2 || tool.Subcommands[0].Name != "db:migrate" || len(tool.Subcommands[1].Aliases) != 0 {
+		t.Errorf("unexpected commands: %+v", tool.Subcommands)
+	}
+}
+
+func TestSanitize_CleanTool(t *testing.T) {
+	tool := &Tool{
+		GlobalFlags: []Flag{
+			{Name: "--help", Short: "-h", Description: "Show help"},
+			{Name: "-display", Arg: "HOST"},
+			{Name: "+x"},
+		},
+		Subcommands: []Command{{Name: "run", Description: "Run it"}},
+	}
+	if issues := tool.Sanitize(); issues != nil {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+}
+
+func correctionsTool() *Tool {
+	return &Tool{
+		Name:        "tool",
+		GlobalFlags: []Flag{{Name: "--verbose", Short: "-v"}, {Name: "--bogus)"}},
+		Subcommands: []Command{
+			{
+				Name:  "remote",
+				Flags: []Flag{{Name: "--track"}},
+				Subcommands: []Command{
+					{Name: "add", Flags: []Flag{{Name: "--fetch", Short: "-f"}}},
+					{Name: "remove"},
+				},
+			},
+			{Name: "ESC-SPACE"},
+			{Name: "q"},
+		},
+	}
+}
+
+func TestEntries(t *testing.T) {
+	var got []string
+	for _, e := range correctionsTool().Entries() {
+		got = append(got, fmt.Sprintf("%d %s %q", e.Depth, e.Path, e.Suspect))
+	}
+	want := []string{
+		`0 --verbose ""`,
+		`0 --bogus) "punctuation in name"`,
+		`0 remote ""`,
+		`1 remote --track ""`,
+		`1 remote add ""`,
+		`2 remote add --fetch ""`,
+		`1 remote remove ""`,
+		`0 ESC-SPACE "uppercase in name"`,
+		`0 q "single-character name"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Entries() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tool := correctionsTool()
+	removed := tool.Remove([]string{"--bogus)", "remote add -f", "remote remove", "ESC-SPACE", "missing", "remote missing --x"})
+	if removed != 4 {
+		t.Errorf("Remove() = %d, want 4", removed)
+	}
+
+	var got []string
+	for _, e := range tool.Entries() {
+		got = append(got, e.Path)
+	}
+	want := []string{"--verbose", "remote", "remote --track", "remote add", "q"}
+	if !slices.Equal(got, want) {
+		t.Errorf("entries after Remove = %v, want %v", got, want)
+	}
+}
+
+func TestRemove_CommandTakesItsChildren(t *testing.T) {
+	tool := correctionsTool()
+	if removed := tool.Remove([]string{"remote"}); removed != 1 {
+		t.Errorf("Remove() = %d, want 1", removed)
+	}
+	for _, e := range tool.Entries() {
+		if strings.HasPrefix(e.Path, "remote") {
+			t.Errorf("expected %q to go with remote", e.Path)
+		}
+	}
+}
+
+func TestCatalogEntry_GeneratedBefore(t *testing.T) {
+	cutoff := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry CatalogEntry
+		want  bool
+	}{
+		{"older", CatalogEntry{Generated: true, GeneratedAt: cutoff.Add(-time.Hour)}, true},
+		{"newer", CatalogEntry{Generated: true, GeneratedAt: cutoff.Add(time.Hour)}, false},
+		{"no timestamp", CatalogEntry{Generated: true}, true},
+		{"never generated", CatalogEntry{}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.entry.GeneratedBefore(cutoff); got != tt.want {
+			t.Errorf("%s: GeneratedBefore() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/jvalentini/tabgen/cmd"
 	"github.com/jvalentini/tabgen/internal/config"
@@ -15,12 +17,23 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Check for global verbose flag before parsing command
+	// Check for global flags before parsing command
 	var filteredArgs []string
-	for _, arg := range os.Args[1:] {
-		if arg == "-v" || arg == "--verbose" {
+	for i := 1; i < len(os.Args); i++ {
+		arg := os.Args[i]
+		switch {
+		case arg == "-v" || arg == "--verbose":
 			config.Verbose = true
-		} else {
+		case arg == "--data-dir":
+			if i+1 == len(os.Args) {
+				fmt.Fprintln(os.Stderr, "Error: --data-dir requires a directory")
+				os.Exit(1)
+			}
+			i++
+			config.DataDir = os.Args[i]
+		case strings.HasPrefix(arg, "--data-dir="):
+			config.DataDir = strings.TrimPrefix(arg, "--data-dir=")
+		default:
 			filteredArgs = append(filteredArgs, arg)
 		}
 	}
@@ -36,7 +49,28 @@ func main() {
 	var err error
 	switch command {
 	case "scan":
-		err = cmd.Scan()
+		fs := flag.NewFlagSet("scan", flag.ExitOnError)
+		var dirs stringList
+		fs.Var(&dirs, "dir", "extra directory to scan after $PATH, saved for later scans (repeatable)")
+		all := fs.Bool("all", false, "include tools from --dir directories even if not in shell history")
+		full := fs.Bool("full", false, "check each tool for --help output and a man page (slower)")
+		jobs := fs.Int("jobs", 0, "number of concurrent --full checks (default: NumCPU)")
+		fs.IntVar(jobs, "j", 0, "number of concurrent --full checks (shorthand)")
+		since := fs.Duration("since", 0, "keep catalog entries for binaries not modified within DURATION (e.g. 24h)")
+		includeRotated := fs.Bool("include-rotated", false, "also read rotated history files such as ~/.bash_history.1.gz, and session histories")
+		skipNonCLI := fs.Bool("skip-non-cli", false, "read file headers to skip shared libraries and GUI programs")
+		statsJSON := fs.Bool("stats-json", false, "print a JSON report of the scan to stdout (other output goes to stderr)")
+		prune := fs.Bool("prune", false, "delete stored data and completions of tools whose binary no longer exists")
+		var scanExcludes stringList
+		fs.Var(&scanExcludes, "exclude", "skip tools matching PATTERN for this run, on top of the stored exclusions (repeatable)")
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen scan [--dir DIR]... [--all] [--full] [-j|--jobs N] [--since DURATION] [--skip-non-cli] [--include-rotated] [--stats-json] [--prune] [--exclude PATTERN]...")
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Scan(cmd.ScanOptions{Dirs: dirs, All: *all, Full: *full, Jobs: *jobs, Since: *since, SkipNonCLI: *skipNonCLI, IncludeRotated: *includeRotated, StatsJSON: *statsJSON, Prune: *prune, Exclude: scanExcludes})
 
 	case "generate":
 		fs := flag.NewFlagSet("generate", flag.ExitOnError)
@@ -44,63 +78,185 @@ func main() {
 		fs.BoolVar(force, "f", false, "force regeneration (shorthand)")
 		workers := fs.Int("workers", 0, "number of concurrent workers (default: NumCPU)")
 		fs.IntVar(workers, "w", 0, "number of concurrent workers (shorthand)")
+		dynamic := fs.Bool("dynamic", false, "complete branches, containers, pods, etc. at runtime")
+		output := fs.String("output", "", "write scripts to DIR/bash and DIR/zsh instead of ~/.tabgen/completions")
+		fs.StringVar(output, "o", "", "output directory (shorthand)")
+		quiet := fs.Bool("quiet", false, "only print failures and the summary (live progress line on a terminal)")
+		fs.BoolVar(quiet, "q", false, "quiet (shorthand)")
+		source := fs.String("source", "", "parse only help or man output, or both (default)")
+		outputFormat := fs.String("output-format", "scripts", "scripts, or json to emit parsed tools (to DIR/<tool>.json with -o, else stdout)")
+		skipEmpty := fs.Bool("skip-empty", false, "don't write scripts for tools that parsed to no subcommands or flags")
+		manFallback := fs.Bool("man-only-fallback", false, "for tools without --help, also parse commands from the man page")
+		completionStyle := fs.String("completion-style", "", "zsh menus: verbose (with descriptions) or compact (default: config completion_style)")
+		noVersion := fs.Bool("no-version", false, "skip version detection; regenerate only when the help output changes")
+		noHelp := fs.Bool("no-help", false, "never run --help; parse man pages only (even for tools with a source override)")
+		noMan := fs.Bool("no-man", false, "never read man pages; parse --help only (even for tools with a source override)")
+		bundle := fs.Bool("bundle", false, "also combine all bash and zsh scripts into one file per shell")
+		var excludes stringList
+		fs.Var(&excludes, "exclude", "skip tools matching PATTERN for this run, on top of the stored exclusions (repeatable)")
+		keep := fs.Int("keep", 0, "keep the last N versions of each replaced script for 'tabgen rollback' (default: config keep_backups)")
+		includeHidden := fs.Bool("include-hidden", false, "also complete flags the help marks as hidden")
+		versionTimeout := fs.Duration("version-timeout", 2*time.Second, "timeout for each --version command, separate from the help timeout")
+		dryRun := fs.Bool("dry-run", false, "parse tools and print what would be generated, skipped, or regenerated (and why) without writing anything")
+		olderThan := fs.Duration("older-than", 0, "also regenerate tools last generated more than DURATION ago (e.g. 720h), even if unchanged")
+		maxTime := fs.Duration("max-time", 0, "stop starting new tools after DURATION (e.g. 5m); tools in progress finish and are saved")
 		fs.Usage = func() {
-			fmt.Fprintln(os.Stderr, "Usage: tabgen generate [tool] [-f|--force] [-w|--workers N]")
+			fmt.Fprintln(os.Stderr, "Usage: tabgen generate [-f|--force] [-w|--workers N] [--dynamic] [-o|--output DIR] [-q|--quiet] [--source help|man|both] [--no-help] [--no-man] [--man-only-fallback] [--skip-empty] [--no-version] [--completion-style verbose|compact] [--keep N] [--bundle] [--exclude PATTERN]... [--max-time DURATION] [--older-than DURATION] [--version-timeout DURATION] [--include-hidden] [--dry-run] [--output-format scripts|json] [tool...]")
 			fs.PrintDefaults()
 		}
 		if err := fs.Parse(args); err != nil {
 			os.Exit(1)
 		}
-		opts := cmd.GenerateOptions{Force: *force, Workers: *workers}
-		if fs.NArg() > 0 {
-			opts.Tool = fs.Arg(0)
-		}
+		opts := cmd.GenerateOptions{Tools: fs.Args(), Force: *force, Workers: *workers, Dynamic: *dynamic, Output: *output, Quiet: *quiet, Source: *source, ManOnlyFallback: *manFallback, SkipEmpty: *skipEmpty, OutputFormat: *outputFormat, Keep: *keep, NoVersion: *noVersion, NoHelp: *noHelp, NoMan: *noMan, CompletionStyle: *completionStyle, Bundle: *bundle, Exclude: excludes, MaxTime: *maxTime, VersionTimeout: *versionTimeout, IncludeHidden: *includeHidden, OlderThan: *olderThan, DryRun: *dryRun}
 		err = cmd.Generate(opts)
 
+	case "regenerate":
+		fs := flag.NewFlagSet("regenerate", flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen regenerate <tool>")
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Regenerate(fs.Arg(0))
+
+	case "rollback":
+		fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen rollback <tool>")
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Rollback(fs.Arg(0))
+
+	case "forget":
+		fs := flag.NewFlagSet("forget", flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen forget <tool>")
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Forget(fs.Arg(0))
+
+	case "open", "where":
+		fs := flag.NewFlagSet(command, flag.ExitOnError)
+		edit := fs.Bool("edit", false, "open the files in $EDITOR")
+		fs.Usage = func() {
+			fmt.Fprintf(os.Stderr, "Usage: tabgen %s [--edit] <tool>\n", command)
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Open(cmd.OpenOptions{Tool: fs.Arg(0), Edit: *edit})
+
+	case "review":
+		fs := flag.NewFlagSet("review", flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen review [tool]")
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Review(fs.Arg(0))
+
+	case "reparse":
+		fs := flag.NewFlagSet("reparse", flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen reparse [tool]")
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Reparse(fs.Arg(0))
+
 	case "list":
 		fs := flag.NewFlagSet("list", flag.ExitOnError)
 		showAll := fs.Bool("all", false, "show all tools")
+		jsonOut := fs.Bool("json", false, "output matching catalog entries as JSON")
+		generated := fs.Bool("generated", false, "only tools with generated completions")
+		failed := fs.Bool("failed", false, "only tools whose last generation failed")
+		unparseable := fs.Bool("unparseable", false, "only tools with no usable --help or man page")
+		stale := fs.Bool("stale", false, "only tools whose binary changed since generation")
+		sortBy := fs.String("sort", "name", "order by name, usage (shell history), subcommands, or version")
 		fs.Usage = func() {
-			fmt.Fprintln(os.Stderr, "Usage: tabgen list [--all]")
+			fmt.Fprintln(os.Stderr, "Usage: tabgen list [--all] [--json] [--generated] [--failed] [--unparseable] [--stale] [--sort name|usage|subcommands|version]")
 			fs.PrintDefaults()
 		}
 		if err := fs.Parse(args); err != nil {
 			os.Exit(1)
 		}
-		err = cmd.List(*showAll)
+		err = cmd.List(cmd.ListOptions{
+			All:         *showAll,
+			JSON:        *jsonOut,
+			Generated:   *generated,
+			Failed:      *failed,
+			Unparseable: *unparseable,
+			Stale:       *stale,
+			Sort:        *sortBy,
+		})
+
+	case "setup":
+		fs := flag.NewFlagSet("setup", flag.ExitOnError)
+		skipTimer := fs.Bool("skip-timer", false, "skip systemd timer setup")
+		dynamic := fs.Bool("dynamic", false, "complete branches, containers, pods, etc. at runtime")
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen setup [--skip-timer] [--dynamic]")
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Setup(cmd.SetupOptions{SkipTimer: *skipTimer, Dynamic: *dynamic})
 
 	case "install":
 		fs := flag.NewFlagSet("install", flag.ExitOnError)
 		skipTimer := fs.Bool("skip-timer", false, "skip systemd timer setup")
+		system := fs.Bool("system", false, "install system-wide (requires root)")
+		printHooks := fs.Bool("print", false, "print shell hook snippets instead of editing rc files, symlinks, or timers")
 		fs.Usage = func() {
-			fmt.Fprintln(os.Stderr, "Usage: tabgen install [--skip-timer]")
+			fmt.Fprintln(os.Stderr, "Usage: tabgen install [--skip-timer] [--system] [--print]")
 			fs.PrintDefaults()
 		}
 		if err := fs.Parse(args); err != nil {
 			os.Exit(1)
 		}
-		err = cmd.Install(*skipTimer)
+		err = cmd.Install(cmd.InstallOptions{SkipTimer: *skipTimer, System: *system, Print: *printHooks})
 
 	case "uninstall":
 		fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
 		keepData := fs.Bool("keep-data", false, "keep data directory")
+		system := fs.Bool("system", false, "remove the system-wide installation (requires root)")
+		dryRun := fs.Bool("dry-run", false, "print what would be removed without removing anything")
 		fs.Usage = func() {
-			fmt.Fprintln(os.Stderr, "Usage: tabgen uninstall [--keep-data]")
+			fmt.Fprintln(os.Stderr, "Usage: tabgen uninstall [--keep-data] [--system] [--dry-run]")
 			fs.PrintDefaults()
 		}
 		if err := fs.Parse(args); err != nil {
 			os.Exit(1)
 		}
-		err = cmd.Uninstall(*keepData)
+		err = cmd.Uninstall(cmd.UninstallOptions{KeepData: *keepData, System: *system, DryRun: *dryRun})
 
 	case "status":
-		err = cmd.Status()
+		fs := flag.NewFlagSet("status", flag.ExitOnError)
+		system := fs.Bool("system", false, "show system-wide installation status")
+		jsonOut := fs.Bool("json", false, "output status as JSON")
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen status [--system] [--json]")
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Status(cmd.StatusOptions{System: *system, JSON: *jsonOut})
 
 	case "exclude":
 		fs := flag.NewFlagSet("exclude", flag.ExitOnError)
 		fs.Usage = func() {
-			fmt.Fprintln(os.Stderr, "Usage: tabgen exclude <action> [pattern]")
-			fmt.Fprintln(os.Stderr, "Actions: list, add, remove, clear")
+			fmt.Fprintln(os.Stderr, "Usage: tabgen exclude <action> [pattern|name]")
+			fmt.Fprintln(os.Stderr, "Actions: list, add, remove, clear, test")
 		}
 		if err := fs.Parse(args); err != nil {
 			os.Exit(1)
@@ -115,6 +271,30 @@ func main() {
 		}
 		err = cmd.Exclude(action, pattern)
 
+	case "config":
+		fs := flag.NewFlagSet("config", flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen config <action> [key] [value]")
+			fmt.Fprintln(os.Stderr, "Actions: list, get <key>, set <key> <value>")
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Config(fs.Arg(0), fs.Arg(1), fs.Arg(2))
+
+	case "import":
+		fs := flag.NewFlagSet("import", flag.ExitOnError)
+		name := fs.String("name", "", "tool name (default: name from the spec)")
+		fs.Usage = func() {
+			fmt.Fprintln(os.Stderr, "Usage: tabgen import [--name tool] <file>")
+			fmt.Fprintln(os.Stderr, "Accepts TabGen tool JSON or cobra __complete output.")
+			fs.PrintDefaults()
+		}
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		err = cmd.Import(fs.Arg(0), *name)
+
 	case "help", "-h", "--help":
 		printUsage()
 
@@ -129,6 +309,18 @@ func main() {
 	}
 }
 
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 func printUsage() {
 	fmt.Println("tabgen - generate tab completions by analyzing CLI tools")
 	fmt.Println()
@@ -136,14 +328,24 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -v, --verbose           Show detailed parsing and debug output")
+	fmt.Println("  --data-dir DIR          Store data in DIR instead of ~/.tabgen (also $TABGEN_DATA_DIR)")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  scan                    Scan $PATH for executable tools")
-	fmt.Println("  generate [tool] [-f] [-w N]  Generate completions (-f force, -w workers)")
-	fmt.Println("  list [--all]            List discovered tools")
-	fmt.Println("  install [--skip-timer]  Set up symlinks, timer, and shell hooks")
-	fmt.Println("  uninstall [--keep-data] Remove TabGen installation")
-	fmt.Println("  status                  Show installation status")
-	fmt.Println("  exclude <action>        Manage exclusion list (list/add/remove/clear)")
+	fmt.Println("  setup [--skip-timer] [--dynamic]  Scan, generate, and install in one step (safe to re-run)")
+	fmt.Println("  scan [--dir DIR] [--all] [--full] [-j N] [--since D] [--skip-non-cli] [--include-rotated] [--stats-json] [--prune] [--exclude P]  Scan $PATH (and extra dirs) for executable tools")
+	fmt.Println("  generate [tool...] [-f] [-w N] [--dynamic] [-o DIR] [-q] [--source S] [--exclude P] [--max-time D] [--older-than D] [--version-timeout D] [--dry-run]  Generate completions (-f force, -w workers, -q quiet)")
+	fmt.Println("  regenerate <tool>       Clear cached data and re-parse a single tool")
+	fmt.Println("  rollback <tool>         Restore the previous completion scripts of a tool")
+	fmt.Println("  forget <tool>           Remove a tool from the catalog with its data and completions")
+	fmt.Println("  open [--edit] <tool>    Print the paths of a tool's JSON and bash/zsh scripts (alias: where)")
+	fmt.Println("  reparse [tool]          Re-parse saved raw help output without running tools")
+	fmt.Println("  review [tool]           Inspect parsed tools and remove false-positive flags and commands")
+	fmt.Println("  list [--all] [--json] [--generated|--failed|--unparseable|--stale] [--sort KEY]  List discovered tools")
+	fmt.Println("  install [--skip-timer] [--system] [--print]  Set up symlinks, timer, and shell hooks")
+	fmt.Println("  uninstall [--keep-data] [--system] [--dry-run]  Remove TabGen installation")
+	fmt.Println("  status [--system] [--json]  Show installation status")
+	fmt.Println("  exclude <action>        Manage exclusion list (list/add/remove/clear/test)")
+	fmt.Println("  config <action>         View or change settings (list/get/set)")
+	fmt.Println("  import <file>           Import a tool spec (tool JSON or cobra __complete)")
 	fmt.Println("  help                    Show this help message")
 }